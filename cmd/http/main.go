@@ -17,6 +17,7 @@ import (
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
 	"log"
+	"net"
 	"net/http"
 )
 
@@ -40,10 +41,43 @@ func main() {
 	// Health Check controller set up
 	controller.NewHealthCheck().SetRouter(r)
 
-	// Config resource controller set up
-	svc := service.NewConfig(repository.NewInMemoryConfig())
-	configController := controller.NewConfig(svc)
+	// Build the storage backend holding the configs, their schemas and webhooks. Without
+	// one being explicitly selected, keep them on local disk when there's a data
+	// directory, so that they survive restarts, otherwise only in memory.
+	backend := cfg.StorageBackend
+	if backend == "" {
+		backend = repository.MemoryBackend
+		if cfg.DataDir != "" {
+			backend = repository.FileBackend
+		}
+	}
+	store, err := repository.NewBackend(backend, repository.BackendOptions{DataDir: cfg.DataDir})
+	if err != nil {
+		log.Fatalf("Failed to set up the %s storage backend: %v", backend, err)
+	}
+	repo := store.Configs
+
+	// Secret metadata values can only be kept when there's a key to encrypt them with.
+	var secrets *service.Secrets
+	if len(cfg.SecretsKey) > 0 {
+		if secrets, err = service.NewSecrets(cfg.SecretsKey); err != nil {
+			log.Fatalf("Failed to set up secrets: %v", err)
+		}
+	}
+
+	// Config resource controller set up, publishing every change made
+	// to configs as an event, and posting it to the matching webhooks,
+	// as long as it doesn't violate the schemas attached to the config.
+	events := service.NewEvents(service.DefaultEventBufferSize)
+	webhooks := service.NewWebhooks(store.Webhooks)
+	schemas := service.NewSchemas(store.Schemas, repo, secrets)
+	svc := service.NewConfig(repo, service.WithEvents(events), service.WithWebhooks(webhooks), service.WithSchemas(schemas), service.WithSecrets(secrets))
+	configController := controller.NewConfig(svc, controller.WithRevealToken(cfg.SecretsRevealToken))
 	configController.SetRouter(r)
+	controller.NewNamespace(svc).SetRouter(r)
+	controller.NewEvents(events).SetRouter(r)
+	controller.NewWebhook(webhooks).SetRouter(r)
+	controller.NewSchema(schemas).SetRouter(r)
 
 	// Set the Swagger endpoint to render the OpenAPI specs.
 	r.PathPrefix("/swagger").Handler(httpSwagger.WrapHandler)
@@ -51,10 +85,20 @@ func main() {
 	// start the HTTP server
 	log.Printf("Starting server on port %d", cfg.ServerPort)
 
+	// Every request descends from the base context, which is cancelled as
+	// soon as the server starts shutting down, so that requests watching
+	// configs or streaming events are released instead of holding the
+	// shutdown up.
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler: r,
+		Addr:        fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	server.RegisterOnShutdown(cancelBase)
+	server.RegisterOnShutdown(events.Close)
 
 	// Start up the HTTP server in a Go routine
 	// to not block the execution so that the Signal listener can
@@ -83,5 +127,13 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+
+	// Give up on the pending webhook deliveries, since no more changes can be made.
+	webhooks.Close()
+
+	// Only release the storage backend once no more requests are being served.
+	if err := store.Close(); err != nil {
+		log.Printf("Failed to close the storage backend: %v", err)
+	}
 	log.Println("Server gracefully shutdown complete.")
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"log"
 	"os"
 	"strconv"
@@ -11,6 +12,19 @@ type AppConfig struct {
 	// ServerPort is the port where the API server will
 	// listen for connections.
 	ServerPort int
+	// StorageBackend is the name of the storage backend holding the configs.
+	// When empty, configs are kept on local disk if there's a DataDir,
+	// otherwise only in memory.
+	StorageBackend string
+	// DataDir is the directory where configs are persisted,
+	// used by the storage backends keeping them on local disk.
+	DataDir string
+	// SecretsKey is the AES key secret metadata values are encrypted with,
+	// 16, 24 or 32 bytes long. Without it, secret values are rejected.
+	SecretsKey []byte
+	// SecretsRevealToken is the bearer token allowing requests to reveal
+	// secret metadata values. Without it, secrets are always redacted.
+	SecretsRevealToken string
 }
 
 // NewAppConfig loads the application configuration parameters
@@ -21,7 +35,17 @@ func NewAppConfig() *AppConfig {
 		log.Fatal("Missing required server port")
 	}
 
+	// the key is encoded in base64, so that it can be any sequence of bytes.
+	secretsKey, err := base64.StdEncoding.DecodeString(os.Getenv("SECRETS_KEY"))
+	if err != nil {
+		log.Fatal("Secrets key must be encoded in base64")
+	}
+
 	return &AppConfig{
-		ServerPort: serverPort,
+		ServerPort:         serverPort,
+		StorageBackend:     os.Getenv("STORAGE_BACKEND"),
+		DataDir:            os.Getenv("DATA_DIR"),
+		SecretsKey:         secretsKey,
+		SecretsRevealToken: os.Getenv("SECRETS_REVEAL_TOKEN"),
 	}
 }
@@ -16,4 +16,37 @@ func TestNewAppConfig(t *testing.T) {
 
 		assert.Equal(t, 8080, cfg.ServerPort)
 	})
+	t.Run("data directory is populated", func(t *testing.T) {
+		os.Setenv("SERVE_PORT", "8080")
+		os.Setenv("DATA_DIR", "/var/lib/config-service")
+		defer os.Unsetenv("SERVE_PORT")
+		defer os.Unsetenv("DATA_DIR")
+
+		cfg := config.NewAppConfig()
+
+		assert.Equal(t, "/var/lib/config-service", cfg.DataDir)
+	})
+	t.Run("storage backend is populated", func(t *testing.T) {
+		os.Setenv("SERVE_PORT", "8080")
+		os.Setenv("STORAGE_BACKEND", "postgres")
+		defer os.Unsetenv("SERVE_PORT")
+		defer os.Unsetenv("STORAGE_BACKEND")
+
+		cfg := config.NewAppConfig()
+
+		assert.Equal(t, "postgres", cfg.StorageBackend)
+	})
+	t.Run("secrets are populated", func(t *testing.T) {
+		os.Setenv("SERVE_PORT", "8080")
+		os.Setenv("SECRETS_KEY", "MDEyMzQ1Njc4OWFiY2RlZg==")
+		os.Setenv("SECRETS_REVEAL_TOKEN", "t0k3n")
+		defer os.Unsetenv("SERVE_PORT")
+		defer os.Unsetenv("SECRETS_KEY")
+		defer os.Unsetenv("SECRETS_REVEAL_TOKEN")
+
+		cfg := config.NewAppConfig()
+
+		assert.Equal(t, []byte("0123456789abcdef"), cfg.SecretsKey)
+		assert.Equal(t, "t0k3n", cfg.SecretsRevealToken)
+	})
 }
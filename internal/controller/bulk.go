@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"net/http"
+)
+
+// maxBulkOps is the maximum number of operations in a bulk request.
+const maxBulkOps = 1000
+
+// @Summary Run config operations in bulk
+// @Description Runs a list of create, upsert, update and delete operations in order, in a single go.
+// @Description Operations are independent from one another, so one failing doesn't stop the rest,
+// @Description and the outcome of each of them is reported in the same order
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the configs without one, the default namespace when omitted"
+// @Param ops body []dto.BulkOp true "Operations to run"
+// @Success 200 {array} dto.BulkResult
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Router /configs:bulk [post]
+// @Router /namespaces/{namespace}/configs:bulk [post]
+func (c Config) bulk(w http.ResponseWriter, r *http.Request) {
+	var requestBody []dto.BulkOp
+	if err := decodeBody(w, r, &requestBody); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if len(requestBody) > maxBulkOps {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, fmt.Sprintf("a bulk request can't have more than %d operations", maxBulkOps))
+		return
+	}
+
+	results := make([]dto.BulkResult, len(requestBody))
+
+	// only the valid operations are run, keeping track of
+	// where their results go among the rest.
+	var ops []repository.Op
+	var positions []int
+	for n, item := range requestBody {
+		op, err := toRepositoryOp(item, namespaceOf(r))
+		if err != nil {
+			results[n] = dto.BulkResult{Code: http.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+		ops = append(ops, op)
+		positions = append(positions, n)
+	}
+
+	for n, result := range c.service.Bulk(ops) {
+		results[positions[n]] = toBulkResult(result)
+	}
+
+	writeResponse(w, r, http.StatusOK, results)
+}
+
+// toRepositoryOp validates item and converts it into an operation,
+// run in namespace unless it has its own.
+func toRepositoryOp(item dto.BulkOp, namespace string) (repository.Op, error) {
+	if err := item.Validate(); err != nil {
+		return repository.Op{}, err
+	}
+
+	op := repository.Op{
+		Type:      repository.OpType(item.Op),
+		Namespace: item.Namespace,
+		Name:      item.Name,
+		Parents:   item.Parents,
+		Revision:  item.Revision,
+	}
+	if op.Namespace == "" {
+		op.Namespace = namespace
+	}
+
+	if item.Op != dto.OpDelete {
+		metadata, err := item.Metadata.ToByteSlice()
+		if err != nil {
+			return repository.Op{}, err
+		}
+		op.Metadata = metadata
+	}
+
+	return op, nil
+}
+
+// toBulkResult converts the outcome of an operation into the result of a bulk request,
+// with the HTTP status the operation would have had on its own.
+func toBulkResult(result repository.OpResult) dto.BulkResult {
+	if result.Err != nil {
+		status, code := problemOf(result.Err)
+		return dto.BulkResult{Code: status, Error: detailOf(result.Err, code)}
+	}
+
+	bulkResult := dto.BulkResult{Code: http.StatusOK}
+	if result.Created {
+		bulkResult.Code = http.StatusCreated
+	}
+
+	config, err := dto.FromDomainConfig(result.Config)
+	if err != nil {
+		// the operation went through anyway, so only the config is left out.
+		return bulkResult
+	}
+	bulkResult.Config = &config
+
+	return bulkResult
+}
@@ -0,0 +1,104 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Bulk(t *testing.T) {
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+	require.NoError(t, repo.CreateNamespace("team-a"))
+	svc := service.NewConfig(repo)
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("every operation gets its own result", func(t *testing.T) {
+		body := fmt.Sprintf(`[
+			{"op": "create", "name": "new", "metadata": {"foo": "bar"}},
+			{"op": "create", "name": "new", "metadata": {"foo": "bar"}},
+			{"op": "upsert", "name": "new", "metadata": {"foo": "baz"}},
+			{"op": "update", "name": %[1]q, "metadata": {"foo": "bar"}, "revision": %[2]d},
+			{"op": "update", "name": %[1]q, "metadata": {"foo": "bar"}, "revision": %[2]d},
+			{"op": "delete", "name": "nope"},
+			{"op": "delete", "name": %[3]q},
+			{"op": "rename", "name": "new"},
+			{"op": "create", "metadata": {"foo": 1}},
+			{"op": "create", "namespace": "team-a", "name": "new", "metadata": {"foo": "bar"}}
+		]`, test.ConfigName1, current.Revision, test.ConfigName2)
+
+		rr := serve("/configs:bulk", body)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var results []dto.BulkResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+
+		wantCodes := []int{
+			http.StatusCreated,
+			http.StatusConflict,
+			http.StatusOK,
+			http.StatusOK,
+			http.StatusPreconditionFailed,
+			http.StatusNotFound,
+			http.StatusOK,
+			http.StatusBadRequest,
+			http.StatusBadRequest,
+			http.StatusCreated,
+		}
+		require.Len(t, results, len(wantCodes))
+		for n, want := range wantCodes {
+			assert.Equal(t, want, results[n].Code, "operation %d: %s", n, results[n].Error)
+		}
+
+		t.Run("results hold the resulting config", func(t *testing.T) {
+			require.NotNil(t, results[2].Config)
+			assert.Equal(t, dto.Metadata{"foo": "baz"}, results[2].Config.Metadata)
+			assert.Equal(t, "team-a", results[9].Config.Namespace)
+		})
+
+		t.Run("failed operations have an error", func(t *testing.T) {
+			assert.NotEmpty(t, results[1].Error)
+			assert.Nil(t, results[1].Config)
+		})
+	})
+
+	t.Run("operations default to the namespace in the path", func(t *testing.T) {
+		rr := serve("/namespaces/team-a/configs:bulk", `[{"op": "create", "name": "scoped", "metadata": {}}]`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		_, err := repo.Get("team-a", "scoped")
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid request body", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("/configs:bulk", `{"op": "create"}`).Code)
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		ops := strings.Repeat(`{"op": "delete", "name": "nope"},`, 1001)
+		assert.Equal(t, http.StatusBadRequest, serve("/configs:bulk", "["+strings.TrimSuffix(ops, ",")+"]").Code)
+	})
+}
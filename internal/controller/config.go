@@ -1,114 +1,204 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"strconv"
 )
 
+// ConfigOption customizes a Config controller instance.
+type ConfigOption func(*Config)
+
+// WithRevealToken allows the requests bearing token to reveal secret metadata
+// values. Without it, secrets are always redacted.
+func WithRevealToken(token string) ConfigOption {
+	return func(c *Config) {
+		c.revealToken = token
+	}
+}
+
 // NewConfig creates a new Config controller instance.
 // It expects a service as a dependency.
-func NewConfig(svc *service.Config) *Config {
-	return &Config{service: svc}
+func NewConfig(svc *service.Config, opts ...ConfigOption) *Config {
+	c := &Config{service: svc}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // Config is the config controller.
 // It defines routes and handlers for the config resources.
 type Config struct {
-	service *service.Config
+	service     *service.Config
+	revealToken string
 }
 
 // SetRouter returns the router r with all the necessary routes for the
 // Config controller setup.
 func (c Config) SetRouter(r *mux.Router) {
-	r.HandleFunc("/configs", middleware.SetJSONContent(c.list)).
+	// every route is served scoped to a namespace, as well as
+	// without one, as an alias for the default namespace.
+	for _, prefix := range []string{"", "/namespaces/{namespace}"} {
+		r.HandleFunc(prefix+"/configs", middleware.Negotiate(c.list)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs", middleware.Negotiate(c.create)).
+			Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/configs:bulk", middleware.Negotiate(c.bulk)).
+			Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/configs/{name}", middleware.Negotiate(c.get)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}", middleware.Negotiate(c.update)).
+			Methods(http.MethodPut)
+		r.HandleFunc(prefix+"/configs/{name}", middleware.Negotiate(c.patch)).
+			Methods(http.MethodPatch)
+		r.HandleFunc(prefix+"/configs/{name}", middleware.Negotiate(c.delete)).
+			Methods(http.MethodDelete)
+		r.HandleFunc(prefix+"/configs/{name}/keys/{path}", middleware.Negotiate(c.getKey)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}/keys/{path}", middleware.Negotiate(c.setKey)).
+			Methods(http.MethodPut)
+		r.HandleFunc(prefix+"/configs/{name}/keys/{path}", middleware.Negotiate(c.deleteKey)).
+			Methods(http.MethodDelete)
+		r.HandleFunc(prefix+"/configs/{name}/parents", middleware.Negotiate(c.setParents)).
+			Methods(http.MethodPut)
+		r.HandleFunc(prefix+"/configs/{name}/descendants", middleware.Negotiate(c.descendants)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}/render", c.render).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}/revisions", middleware.Negotiate(c.revisions)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}/revisions/{revision:[0-9]+}", middleware.Negotiate(c.revision)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/configs/{name}/revisions/{revision:[0-9]+}:rollback", middleware.Negotiate(c.rollback)).
+			Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/search", middleware.Negotiate(c.search)).
+			Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/txn", middleware.SetJSONContent(c.txn)).
+			Methods(http.MethodPost)
+	}
+
+	// exports and imports cover the whole store.
+	r.HandleFunc("/export", c.exportConfigs).
 		Methods(http.MethodGet)
-	r.HandleFunc("/configs", middleware.SetJSONContent(c.create)).
+	r.HandleFunc("/import", middleware.SetJSONContent(c.importConfigs)).
 		Methods(http.MethodPost)
-	r.HandleFunc("/configs/{name}", middleware.SetJSONContent(c.get)).
-		Methods(http.MethodGet)
-	r.HandleFunc("/configs/{name}", middleware.SetJSONContent(c.update)).
-		Methods(http.MethodPut, http.MethodPatch)
-	r.HandleFunc("/configs/{name}", middleware.SetJSONContent(c.delete)).
-		Methods(http.MethodDelete)
-	r.HandleFunc("/search", middleware.SetJSONContent(c.query)).
-		Methods(http.MethodGet)
 }
 
 // @Summary List configs
-// @Description Lists all available configs
+// @Description Lists a page of the available configs
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param limit query int false "Maximum number of configs in the page, every config when omitted"
+// @Param continue query string false "Token of the page to get, as returned in the X-Continue header of the previous page"
+// @Param sort query string false "Field configs are sorted by, one of name, updatedAt and revision, prefixed with - for descending order" default(name)
+// @Param resolved query bool false "Deep merge the metadata of the parents of every config under its own, matching searches against it as well"
+// @Param interpolate query bool false "Replace the references in the metadata values of every config by the values they point at"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
 // @Success 200 {array} dto.Config
-// @Failure 500 {string} string "Error message"
+// @Header 200 {string} ETag "Weak entity tag of the listed configs"
+// @Header 200 {integer} X-Total-Count "Number of configs across every page"
+// @Header 200 {string} X-Continue "Token of the next page, missing on the last page"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /configs [get]
+// @Router /namespaces/{namespace}/configs [get]
 func (c Config) list(w http.ResponseWriter, r *http.Request) {
-	configs, err := c.service.List()
+	opts, err := listOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	var responseConfigs []dto.Config
-	for _, config := range configs {
-		dtoConfig, err := dto.FromDomainConfig(config)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		responseConfigs = append(responseConfigs, dtoConfig)
-	}
-
-	bytes, err := json.Marshal(responseConfigs)
+	reveal, err := c.revealing(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	_, err = w.Write(bytes)
+	page, err := c.service.List(namespaceOf(r), opts)
+	if err == nil && reveal {
+		page.Configs, err = c.revealAll(page.Configs)
+	}
 	if err != nil {
-		log.Printf("Failed to write response: %s", err.Error())
+		writeError(w, err)
 		return
 	}
+
+	w.Header().Set("ETag", listETag(page.Configs))
+	writePage(w, r, page)
 }
 
 // @Summary Create a new config
 // @Description Creates a new config resource
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
-// @Param config body dto.Config true "Config object to be created"
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param config body dto.Config true "Config object to be created, or its flattened metadata when format is set"
+// @Param format query string false "Format of flattened metadata to take the body as, one of dotenv, properties and flat-json"
+// @Param name query string false "Name of the config, required when format is set"
 // @Success 201
-// @Failure 400 {object} string "Error message"
-// @Failure 500 {object} string "Error message"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /configs [post]
+// @Router /namespaces/{namespace}/configs [post]
 func (c Config) create(w http.ResponseWriter, r *http.Request) {
 	var requestBody dto.Config
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var err error
+	if r.URL.Query().Has(formatParam) {
+		requestBody.Name = r.URL.Query().Get(nameParam)
+		requestBody.Metadata, err = decodeFlatMetadata(w, r)
+	} else {
+		err = decodeBody(w, r, &requestBody)
+	}
+	if err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	if err := requestBody.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
 	config, err := requestBody.ToDomainConfig()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, err.Error())
 		return
 	}
+	config.Namespace = namespaceOf(r)
 
 	if err := c.service.Create(config); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -119,84 +209,228 @@ func (c Config) create(w http.ResponseWriter, r *http.Request) {
 // @Description Gets a config resource by its name
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
 // @Param name path string true "Name of the config"
+// @Param revision query int false "Revision to read the config at"
+// @Param watch query bool false "Wait for the config to change past sinceRevision"
+// @Param sinceRevision query int false "Revision to wait for the config to change past, required to watch"
+// @Param timeout query string false "How long to wait for the config to change, 30s by default and 5m at most"
+// @Param resolved query bool false "Deep merge the metadata of the parents of the config under its own, which can't be combined with revision or watch"
+// @Param interpolate query bool false "Replace the references in the metadata values of the config by the values they point at, which can't be combined with revision or watch"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
 // @Success 200 {object} dto.Config
-// @Failure 404 {object} string "Error message"
-// @Failure 500 {object} string "Error message"
+// @Header 200 {string} ETag "Entity tag of the config revision, the latest one among the config and the configs it inherits from or references when resolved or interpolated"
+// @Success 304 "The config didn't change before the timeout"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /configs/{name} [get]
+// @Router /namespaces/{namespace}/configs/{name} [get]
 func (c Config) get(w http.ResponseWriter, r *http.Request) {
-	name := mux.Vars(r)["name"]
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
 
-	config, err := c.service.Get(name)
+	watch, watching, err := parseWatch(r.URL.Query())
 	if err != nil {
-		if errors.Is(err, repository.ErrConfigNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	responseConfig, err := dto.FromDomainConfig(config)
+	reveal, err := c.revealing(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	bytes, err := json.Marshal(responseConfig)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	var config domain.Config
+	resolved, _ := strconv.ParseBool(r.URL.Query().Get(resolvedParam))
+	interpolate, _ := strconv.ParseBool(r.URL.Query().Get(interpolateParam))
+	rawRevision := r.URL.Query().Get("revision")
+	if (resolved || interpolate) && (watching || rawRevision != "") {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "resolved and interpolate can't be combined with revision or watch")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(bytes)
+	// a specific revision can be pinned to read the config as it was back then,
+	// or the config can be watched to read it as soon as it changes.
+	// Resolved configs are only read as they are now.
+	if resolved {
+		config, err = c.service.Resolve(namespace, name)
+	} else if watching {
+		ctx, cancel := context.WithTimeout(r.Context(), watch.timeout)
+		defer cancel()
+
+		config, err = c.service.Watch(ctx, namespace, name, watch.sinceRevision)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if rawRevision != "" {
+		revision, parseErr := strconv.ParseInt(rawRevision, 10, 64)
+		if parseErr != nil {
+			writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "revision must be an integer")
+			return
+		}
+		config, err = c.service.Revision(namespace, name, revision)
+	} else {
+		config, err = c.service.Get(namespace, name)
+	}
+	if err == nil && interpolate {
+		config, err = c.service.Interpolate(config)
+	}
+	if err == nil && reveal {
+		config, err = c.service.Reveal(config)
+	}
 	if err != nil {
-		log.Printf("Failed to write response: %s", err.Error())
+		writeError(w, err)
 		return
 	}
+
+	w.Header().Set("ETag", etag(config.Revision))
+	writeConfig(w, r, config)
 }
 
 // @Summary Update a config by name
 // @Description Updates a config resource by its name
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
 // @Param name path string true "Name of the config"
-// @Param config body dto.Metadata true "Metadata"
+// @Param config body dto.Metadata true "Metadata, or its flattened form when format is set"
+// @Param format query string false "Format of flattened metadata to take the body as, one of dotenv, properties and flat-json"
+// @Param If-Match header string false "Only update if the config still matches the entity tag"
 // @Success 200
-// @Failure 400 {object} string "Error message"
-// @Failure 404 {object} string "Error message"
-// @Failure 500 {object} string "Error message"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /configs/{name} [put]
-// @Router /configs/{name} [patch]
+// @Router /namespaces/{namespace}/configs/{name} [put]
 func (c Config) update(w http.ResponseWriter, r *http.Request) {
-	name := mux.Vars(r)["name"]
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
 
 	var requestBody dto.Metadata
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var err error
+	if r.URL.Query().Has(formatParam) {
+		requestBody, err = decodeFlatMetadata(w, r)
+	} else {
+		err = decodeBody(w, r, &requestBody)
+	}
+	if err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	if err := requestBody.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
 	metadataBytes, err := requestBody.ToByteSlice()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	if err := c.service.Update(name, metadataBytes); err != nil {
-		if errors.Is(err, repository.ErrConfigNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
+	revision, conditional, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		if conditional {
+			err = c.service.CompareAndSwap(namespace, name, revision, metadataBytes)
+		} else {
+			err = c.service.Update(namespace, name, metadataBytes)
+		}
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Patch a config by name
+// @Description Patches the metadata of a config according to the content type:
+// @Description a JSON Merge Patch (RFC 7396) document, where keys set to null are deleted and nested objects are merged,
+// @Description or a JSON Patch (RFC 6902) document, whose operations are applied all or nothing.
+// @Description A YAML document is taken as a merge patch.
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param config body object true "Merge patch or JSON Patch operations"
+// @Param If-Match header string false "Only patch if the config still matches the entity tag"
+// @Success 200
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name} [patch]
+// @Router /namespaces/{namespace}/configs/{name} [patch]
+func (c Config) patch(w http.ResponseWriter, r *http.Request) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	var applyPatch func(metadata []byte, patch []byte) ([]byte, error)
+	contentType := mediaType(r)
+	switch {
+	// plain JSON and YAML are taken as a merge patch as well, which is what
+	// PATCH requests used to be treated as.
+	case contentType == "", contentType == middleware.JSONContentType, contentType == mergePatchContentType, middleware.IsYAML(contentType):
+		applyPatch = domain.MergePatch
+	case contentType == jsonPatchContentType:
+		applyPatch = domain.JSONPatch
+	default:
+		writeProblem(w, http.StatusUnsupportedMediaType, dto.CodeUnsupportedMediaType, "unsupported patch content type")
+		return
+	}
+
+	patchBytes, err := io.ReadAll(limitBody(w, r))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if middleware.IsYAML(contentType) {
+		if patchBytes, err = yamlToJSON(patchBytes); err != nil {
+			writeDecodeError(w, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	if !json.Valid(patchBytes) {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidPatch, "patch must be valid JSON")
+		return
+	}
+
+	revision, _, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		err = c.service.Patch(namespace, name, revision, func(metadata []byte) ([]byte, error) {
+			patched, err := applyPatch(metadata, patchBytes)
+			if err != nil {
+				return nil, err
+			}
+			return patched, validateMetadata(patched)
+		})
+	}
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -207,22 +441,33 @@ func (c Config) update(w http.ResponseWriter, r *http.Request) {
 // @Description Deletes a config resource by its name
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
 // @Param name path string true "Name of the config"
+// @Param If-Match header string false "Only delete if the config still matches the entity tag"
 // @Success 200
-// @Failure 404 {object} string "Error message"
-// @Failure 500 {object} string "Error message"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /configs/{name} [delete]
+// @Router /namespaces/{namespace}/configs/{name} [delete]
 func (c Config) delete(w http.ResponseWriter, r *http.Request) {
-	name := mux.Vars(r)["name"]
-
-	err := c.service.Delete(name)
-	if err != nil {
-		if errors.Is(err, repository.ErrConfigNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	revision, conditional, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		if conditional {
+			err = c.service.CompareAndDelete(namespace, name, revision)
+		} else {
+			err = c.service.Delete(namespace, name)
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -233,44 +478,277 @@ func (c Config) delete(w http.ResponseWriter, r *http.Request) {
 // @Description Query all available configs based on query parameters
 // @Tags config
 // @Accept json
+// @Accept application/yaml
 // @Produce json
-// @Param keyValuePairs query object true "Metadata filters not represented appropriately, due to limitations in OpenAPI 2.x. But it's a free key/value pair of strings"
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param keyValuePairs query object false "Metadata conditions not represented appropriately, due to limitations in OpenAPI 2.x. Each of them is a `path[operator]=value` pair, such as `metadata.region[in]=eu,us`, where the operator defaults to eq"
+// @Param q query string false "Query expression combining conditions with and/or, such as `region in (eu, us) and (tier = gold or tier prefix:i plat)`"
+// @Param allNamespaces query bool false "Search across every namespace"
+// @Param limit query int false "Maximum number of configs in the page, every config when omitted"
+// @Param continue query string false "Token of the page to get, as returned in the X-Continue header of the previous page"
+// @Param sort query string false "Field configs are sorted by, one of name, updatedAt and revision, prefixed with - for descending order" default(name)
+// @Param resolved query bool false "Deep merge the metadata of the parents of every config under its own, matching searches against it as well"
+// @Param interpolate query bool false "Replace the references in the metadata values of every config by the values they point at, while matching searches against the raw values"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
 // @Success 200 {array} dto.Config
-// @Failure 500 {object} string "Error message"
+// @Header 200 {integer} X-Total-Count "Number of matching configs across every page"
+// @Header 200 {string} X-Continue "Token of the next page, missing on the last page"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
 // @Router /search [get]
-func (c Config) query(w http.ResponseWriter, r *http.Request) {
+// @Router /namespaces/{namespace}/search [get]
+func (c Config) search(w http.ResponseWriter, r *http.Request) {
 	urlQuery := r.URL.Query()
 
-	// convert the query params into map[string]string
-	query := make(map[string]string)
-	for k, v := range urlQuery {
-		if len(v) > 0 {
-			query[k] = v[0]
-		}
+	// searches are scoped to the namespace, unless explicitly
+	// requested to go across every namespace.
+	namespace := namespaceOf(r)
+	if allNamespaces, _ := strconv.ParseBool(urlQuery.Get(allNamespacesParam)); allNamespaces {
+		namespace = repository.AllNamespaces
+	}
+	urlQuery.Del(allNamespacesParam)
+
+	opts, err := listOptions(urlQuery)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// every other query param is part of the query.
+	expr, err := query.ParseValues(urlQuery)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := c.service.Search(namespace, expr, opts)
+	if err == nil && reveal {
+		page.Configs, err = c.revealAll(page.Configs)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writePage(w, r, page)
+}
+
+// @Summary List the revisions of a config
+// @Description Lists every revision of a config, from the oldest to the current one
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
+// @Success 200 {array} dto.Config
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/revisions [get]
+// @Router /namespaces/{namespace}/configs/{name}/revisions [get]
+func (c Config) revisions(w http.ResponseWriter, r *http.Request) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	configs, err := c.service.Revisions(namespace, name)
+	if err == nil && reveal {
+		configs, err = c.revealAll(configs)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeConfigs(w, r, configs)
+}
+
+// @Summary Get a revision of a config
+// @Description Gets a config resource as it was at a given revision
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param revision path int true "Revision of the config"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
+// @Success 200 {object} dto.Config
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/revisions/{revision} [get]
+// @Router /namespaces/{namespace}/configs/{name}/revisions/{revision} [get]
+func (c Config) revision(w http.ResponseWriter, r *http.Request) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	revision, err := strconv.ParseInt(mux.Vars(r)["revision"], 10, 64)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "revision must be an integer")
+		return
+	}
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config, err := c.service.Revision(namespace, name, revision)
+	if err == nil && reveal {
+		config, err = c.service.Reveal(config)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	configs, err := c.service.Search(query)
+	writeConfig(w, r, config)
+}
+
+// @Summary Roll back a config to a revision
+// @Description Restores the metadata a config had at a given revision, storing it as a new revision
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param revision path int true "Revision to roll back to"
+// @Param If-Match header string false "Only roll back if the config still matches the entity tag"
+// @Success 200
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/revisions/{revision}:rollback [post]
+// @Router /namespaces/{namespace}/configs/{name}/revisions/{revision}:rollback [post]
+func (c Config) rollback(w http.ResponseWriter, r *http.Request) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	revision, err := strconv.ParseInt(mux.Vars(r)["revision"], 10, 64)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "revision must be an integer")
+		return
+	}
+
+	current, _, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		err = c.service.Rollback(namespace, name, revision, current)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
+	w.WriteHeader(http.StatusOK)
+}
+
+// allNamespacesParam is the query param making a search go across every namespace.
+const allNamespacesParam = "allNamespaces"
+
+// namespaceOf gets the namespace a request is scoped to, which is the default
+// namespace for the routes outside of /namespaces/{namespace}.
+func namespaceOf(r *http.Request) string {
+	if namespace, ok := mux.Vars(r)["namespace"]; ok {
+		return namespace
+	}
+
+	return domain.DefaultNamespace
+}
+
+const (
+	// mergePatchContentType is the media type of JSON Merge Patch documents.
+	mergePatchContentType = "application/merge-patch+json"
+	// jsonPatchContentType is the media type of JSON Patch documents.
+	jsonPatchContentType = "application/json-patch+json"
+)
+
+// mediaType gets the media type of the request body, without any parameters.
+func mediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	return mediaType
+}
+
+// validateMetadata makes sure the patched metadata still passes the same
+// validation applied to the metadata sent in full.
+func validateMetadata(metadata []byte) error {
+	m, err := dto.MetadataFromByteSlice(metadata)
+	if err != nil {
+		return err
+	}
+
+	return m.Validate()
+}
+
+// writeConfig writes config as the response body, in the content type negotiated for r.
+func writeConfig(w http.ResponseWriter, r *http.Request, config domain.Config) {
+	responseConfig, err := dto.FromDomainConfig(config)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, responseConfig)
+}
+
+// writeConfigs writes configs as the response body, in the content type negotiated for r.
+func writeConfigs(w http.ResponseWriter, r *http.Request, configs []domain.Config) {
 	var responseConfigs []dto.Config
 	for _, config := range configs {
 		dtoConfig, err := dto.FromDomainConfig(config)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		responseConfigs = append(responseConfigs, dtoConfig)
 	}
 
-	bytes, err := json.Marshal(responseConfigs)
+	writeResponse(w, r, http.StatusOK, responseConfigs)
+}
+
+// writeJSON marshals v and writes it as the response body along with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	bytes, err := json.Marshal(v)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
+	w.WriteHeader(status)
 	_, err = w.Write(bytes)
 	if err != nil {
 		log.Printf("Failed to write response: %s", err.Error())
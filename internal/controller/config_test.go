@@ -18,6 +18,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -61,7 +63,7 @@ func TestConfig(t *testing.T) {
 
 		t.Run("service errors out", func(t *testing.T) {
 			mockRepo := mocks.NewConfig(t)
-			mockRepo.On("List").Return(nil, errors.New("oops"))
+			mockRepo.On("List", mock.Anything, mock.Anything).Return(repository.Page{}, errors.New("oops"))
 
 			svc := service.NewConfig(mockRepo)
 			configController := controller.NewConfig(svc)
@@ -150,7 +152,7 @@ func TestConfig(t *testing.T) {
 
 		t.Run("service errors out", func(t *testing.T) {
 			mockRepo := mocks.NewConfig(t)
-			mockRepo.On("Save", mock.Anything).Return(errors.New("oops"))
+			mockRepo.On("Save", mock.Anything).Return(domain.Config{}, errors.New("oops"))
 
 			svc := service.NewConfig(mockRepo)
 			configController := controller.NewConfig(svc)
@@ -366,4 +368,600 @@ func TestConfig(t *testing.T) {
 			assert.Equal(t, wantLen, len(responseConfigs))
 		})
 	})
+
+	t.Run("paginate configs", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+		svc := service.NewConfig(repo)
+		configController := controller.NewConfig(svc)
+
+		r := mux.NewRouter()
+		configController.SetRouter(r)
+
+		for _, path := range []string{"/configs", "/search"} {
+			t.Run(path, func(t *testing.T) {
+				var names []string
+				target := path + "?limit=2&sort=-name"
+				for target != "" {
+					req := httptest.NewRequest(http.MethodGet, target, nil)
+					rr := httptest.NewRecorder()
+					r.ServeHTTP(rr, req)
+					require.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, strconv.Itoa(len(customData)), rr.Header().Get("X-Total-Count"))
+
+					var responseConfigs []dto.Config
+					require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseConfigs))
+					for _, c := range responseConfigs {
+						names = append(names, c.Name)
+					}
+
+					target = ""
+					if next := rr.Header().Get("X-Continue"); next != "" {
+						target = path + "?limit=2&sort=-name&continue=" + url.QueryEscape(next)
+					}
+				}
+
+				assert.Equal(t, []string{test.ConfigName2, test.ConfigName1, test.ConfigName3}, names)
+			})
+		}
+
+		tests := []struct {
+			name   string
+			target string
+		}{
+			{name: "invalid limit", target: "/configs?limit=many"},
+			{name: "unknown sort field", target: "/configs?sort=size"},
+			{name: "invalid continue token", target: "/search?continue=nope"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+		}
+	})
+
+	t.Run("search configs using the query language", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+		svc := service.NewConfig(repo)
+		configController := controller.NewConfig(svc)
+
+		r := mux.NewRouter()
+		configController.SetRouter(r)
+
+		tests := []struct {
+			name           string
+			target         string
+			wantStatus     int
+			wantConfigsLen int
+		}{
+			{
+				name:           "operators in query params",
+				target:         "/search?metadata.abc[in]=123,456&metadata.foo[ne]=bar",
+				wantStatus:     http.StatusOK,
+				wantConfigsLen: 1,
+			},
+			{
+				name:           "every value of a repeated param applies",
+				target:         "/search?abc[prefix]=1&abc[suffix]=3&obj[exists]",
+				wantStatus:     http.StatusOK,
+				wantConfigsLen: 2,
+			},
+			{
+				name:           "or group in an expression",
+				target:         "/search?q=" + url.QueryEscape(`foo eq:i BAR or allergens.eggs = true`),
+				wantStatus:     http.StatusOK,
+				wantConfigsLen: 2,
+			},
+			{
+				name:       "malformed expression",
+				target:     "/search?q=" + url.QueryEscape(`foo = bar or`),
+				wantStatus: http.StatusBadRequest,
+			},
+			{
+				name:       "unknown operator",
+				target:     "/search?foo[like]=bar",
+				wantStatus: http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				require.Equal(t, tt.wantStatus, rr.Code)
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var responseConfigs []dto.Config
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseConfigs))
+				assert.Len(t, responseConfigs, tt.wantConfigsLen)
+			})
+		}
+
+		t.Run("error message points at the malformed part", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(`foo = bar or`), nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Contains(t, rr.Body.String(), "at position 13")
+		})
+	})
+
+	t.Run("watch config", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+		svc := service.NewConfig(repo)
+		configController := controller.NewConfig(svc)
+
+		r := mux.NewRouter()
+		configController.SetRouter(r)
+
+		current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+
+		t.Run("it returns the config once it changes", func(t *testing.T) {
+			target := fmt.Sprintf("/configs/%s?watch=true&sinceRevision=%d", test.ConfigName1, current.Revision)
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rr := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				r.ServeHTTP(rr, req)
+				close(done)
+			}()
+
+			_, err := repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "watched"}`))
+			require.NoError(t, err)
+			<-done
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), "watched")
+
+			updated, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf(`"%d"`, updated.Revision), rr.Header().Get("ETag"))
+			current = updated
+		})
+
+		t.Run("it's not modified when the timeout passes", func(t *testing.T) {
+			target := fmt.Sprintf("/configs/%s?watch=true&sinceRevision=%d&timeout=10ms", test.ConfigName1, current.Revision)
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotModified, rr.Code)
+			assert.Empty(t, rr.Body.String())
+		})
+
+		t.Run("missing namespace is not found right away", func(t *testing.T) {
+			target := fmt.Sprintf("/namespaces/nope/configs/%s?watch=true&sinceRevision=0", test.ConfigName1)
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+
+		t.Run("deleted config is not found", func(t *testing.T) {
+			other, err := repo.Get(domain.DefaultNamespace, test.ConfigName2)
+			require.NoError(t, err)
+
+			target := fmt.Sprintf("/configs/%s?watch=true&sinceRevision=%d", test.ConfigName2, other.Revision)
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rr := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				r.ServeHTTP(rr, req)
+				close(done)
+			}()
+
+			_, err = repo.Delete(domain.DefaultNamespace, test.ConfigName2)
+			require.NoError(t, err)
+			<-done
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+
+		t.Run("invalid watch params", func(t *testing.T) {
+			tests := []struct {
+				name  string
+				query string
+			}{
+				{name: "missing sinceRevision", query: "watch=true"},
+				{name: "non-numeric sinceRevision", query: "watch=true&sinceRevision=abc"},
+				{name: "invalid timeout", query: "watch=true&sinceRevision=1&timeout=soon"},
+				{name: "pinned revision", query: "watch=true&sinceRevision=1&revision=1"},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/configs/%s?%s", test.ConfigName1, tt.query), nil)
+					rr := httptest.NewRecorder()
+					r.ServeHTTP(rr, req)
+
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+				})
+			}
+		})
+	})
+
+	t.Run("config revisions", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+		svc := service.NewConfig(repo)
+		configController := controller.NewConfig(svc)
+
+		r := mux.NewRouter()
+		configController.SetRouter(r)
+
+		original, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		_, err = repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "updated"}`))
+		require.NoError(t, err)
+
+		t.Run("list revisions", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/configs/%s/revisions", test.ConfigName1), nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			var responseConfigs []dto.Config
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseConfigs))
+			assert.Len(t, responseConfigs, 2)
+		})
+
+		tests := []struct {
+			name           string
+			method         string
+			target         string
+			wantHTTPStatus int
+		}{
+			{
+				name:           "get revision",
+				method:         http.MethodGet,
+				target:         fmt.Sprintf("/configs/%s/revisions/%d", test.ConfigName1, original.Revision),
+				wantHTTPStatus: http.StatusOK,
+			},
+			{
+				name:           "get pinned revision",
+				method:         http.MethodGet,
+				target:         fmt.Sprintf("/configs/%s?revision=%d", test.ConfigName1, original.Revision),
+				wantHTTPStatus: http.StatusOK,
+			},
+			{
+				name:           "invalid pinned revision",
+				method:         http.MethodGet,
+				target:         fmt.Sprintf("/configs/%s?revision=abc", test.ConfigName1),
+				wantHTTPStatus: http.StatusBadRequest,
+			},
+			{
+				name:           "revision not found",
+				method:         http.MethodGet,
+				target:         fmt.Sprintf("/configs/%s/revisions/9999", test.ConfigName1),
+				wantHTTPStatus: http.StatusNotFound,
+			},
+			{
+				name:           "config not found",
+				method:         http.MethodGet,
+				target:         "/configs/nope/revisions",
+				wantHTTPStatus: http.StatusNotFound,
+			},
+			{
+				name:           "rollback to a revision not found",
+				method:         http.MethodPost,
+				target:         fmt.Sprintf("/configs/%s/revisions/9999:rollback", test.ConfigName1),
+				wantHTTPStatus: http.StatusNotFound,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				assert.Equal(t, tt.wantHTTPStatus, rr.Code)
+			})
+		}
+
+		t.Run("rollback with a stale ETag", func(t *testing.T) {
+			target := fmt.Sprintf("/configs/%s/revisions/%d:rollback", test.ConfigName1, original.Revision)
+			req := httptest.NewRequest(http.MethodPost, target, nil)
+			req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, original.Revision))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		})
+
+		t.Run("rollback to a revision", func(t *testing.T) {
+			target := fmt.Sprintf("/configs/%s/revisions/%d:rollback", test.ConfigName1, original.Revision)
+			req := httptest.NewRequest(http.MethodPost, target, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			t.Run("old metadata is restored as a new revision", func(t *testing.T) {
+				config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+				require.NoError(t, err)
+				assert.Equal(t, original.Metadata, config.Metadata)
+				assert.Greater(t, config.Revision, original.Revision)
+			})
+		})
+	})
+
+	t.Run("optimistic concurrency", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+		svc := service.NewConfig(repo)
+		configController := controller.NewConfig(svc)
+
+		r := mux.NewRouter()
+		configController.SetRouter(r)
+
+		// read the config to get hold of its entity tag.
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/configs/%s", test.ConfigName1), nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		t.Run("list has an ETag", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/configs", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.NotEmpty(t, rr.Header().Get("ETag"))
+		})
+
+		requestBody := `{"calories": "230"}`
+
+		tests := []struct {
+			name           string
+			method         string
+			target         string
+			body           string
+			ifMatch        string
+			wantHTTPStatus int
+		}{
+			{
+				name:           "update with a non-matching ETag",
+				method:         http.MethodPut,
+				ifMatch:        `"9999"`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "update with a weak ETag",
+				method:         http.MethodPut,
+				ifMatch:        "W/" + etag,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "update with the current ETag",
+				method:         http.MethodPut,
+				ifMatch:        etag,
+				wantHTTPStatus: http.StatusOK,
+			},
+			{
+				name:           "update with the now stale ETag",
+				method:         http.MethodPatch,
+				ifMatch:        etag,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "update with a wildcard",
+				method:         http.MethodPut,
+				ifMatch:        "*",
+				wantHTTPStatus: http.StatusOK,
+			},
+			{
+				name:           "delete with the stale ETag",
+				method:         http.MethodDelete,
+				ifMatch:        etag,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "update a missing config with a wildcard",
+				method:         http.MethodPut,
+				target:         "/configs/nope",
+				ifMatch:        "*",
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "delete a missing config with a wildcard",
+				method:         http.MethodDelete,
+				target:         "/configs/nope",
+				ifMatch:        "*",
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "patch with the ETag of revision 0",
+				method:         http.MethodPatch,
+				ifMatch:        `"0"`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "rollback with the ETag of revision 0",
+				method:         http.MethodPost,
+				target:         fmt.Sprintf("/configs/%s/revisions/1:rollback", test.ConfigName1),
+				ifMatch:        `"0"`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "set a key with the ETag of revision 0",
+				method:         http.MethodPut,
+				target:         fmt.Sprintf("/configs/%s/keys/calories", test.ConfigName1),
+				body:           `"230"`,
+				ifMatch:        `"0"`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+			{
+				name:           "set the parents with the ETag of revision 0",
+				method:         http.MethodPut,
+				target:         fmt.Sprintf("/configs/%s/parents", test.ConfigName1),
+				body:           `[]`,
+				ifMatch:        `"0"`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				target := tt.target
+				if target == "" {
+					target = fmt.Sprintf("/configs/%s", test.ConfigName1)
+				}
+				body := tt.body
+				if body == "" {
+					body = requestBody
+				}
+				req := httptest.NewRequest(tt.method, target, strings.NewReader(body))
+				req.Header.Set("If-Match", tt.ifMatch)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				assert.Equal(t, tt.wantHTTPStatus, rr.Code)
+			})
+		}
+
+		t.Run("delete with the current ETag among others", func(t *testing.T) {
+			current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/configs/%s", test.ConfigName1), nil)
+			req.Header.Set("If-Match", fmt.Sprintf(`"9999", "%d"`, current.Revision))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	})
+
+	t.Run("patch config", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			contentType    string
+			ifMatch        string
+			requestBody    string
+			wantHTTPStatus int
+			wantMetadata   string
+		}{
+			{
+				name:           "merge patch is applied",
+				contentType:    "application/merge-patch+json",
+				requestBody:    `{"foo": "patched", "obj": {"aaa": null, "new": "key"}}`,
+				wantHTTPStatus: http.StatusOK,
+				wantMetadata:   `{"foo": "patched", "abc": "123", "obj": {"new": "key"}}`,
+			},
+			{
+				name:           "plain JSON is taken as a merge patch",
+				contentType:    "application/json",
+				requestBody:    `{"abc": null}`,
+				wantHTTPStatus: http.StatusOK,
+				wantMetadata:   `{"foo": "bar", "obj": {"aaa": "bbb"}}`,
+			},
+			{
+				name:           "unsupported content type",
+				contentType:    "text/plain",
+				requestBody:    `{"abc": null}`,
+				wantHTTPStatus: http.StatusUnsupportedMediaType,
+			},
+			{
+				name:           "malformed merge patch",
+				contentType:    "application/merge-patch+json",
+				requestBody:    `{"abc":`,
+				wantHTTPStatus: http.StatusBadRequest,
+			},
+			{
+				name:           "typed values are merged",
+				contentType:    "application/merge-patch+json",
+				requestBody:    `{"abc": 8, "obj": {"list": [true, null]}}`,
+				wantHTTPStatus: http.StatusOK,
+				wantMetadata:   `{"foo": "bar", "abc": 8, "obj": {"aaa": "bbb", "list": [true, null]}}`,
+			},
+			{
+				name:        "JSON patch is applied",
+				contentType: "application/json-patch+json",
+				requestBody: `[
+					{"op": "test", "path": "/foo", "value": "bar"},
+					{"op": "move", "from": "/obj/aaa", "path": "/moved"},
+					{"op": "replace", "path": "/abc", "value": "456"}
+				]`,
+				wantHTTPStatus: http.StatusOK,
+				wantMetadata:   `{"foo": "bar", "abc": "456", "obj": {}, "moved": "bbb"}`,
+			},
+			{
+				name:        "failing JSON patch test leaves the config untouched",
+				contentType: "application/json-patch+json",
+				requestBody: `[
+					{"op": "remove", "path": "/abc"},
+					{"op": "test", "path": "/foo", "value": "nope"}
+				]`,
+				wantHTTPStatus: http.StatusConflict,
+				wantMetadata:   `{"foo": "bar", "abc": "123", "obj": {"aaa": "bbb"}}`,
+			},
+			{
+				name:           "JSON patch on a missing path",
+				contentType:    "application/json-patch+json",
+				requestBody:    `[{"op": "remove", "path": "/nope"}]`,
+				wantHTTPStatus: http.StatusBadRequest,
+			},
+			{
+				name:           "JSON patched metadata fails validation",
+				contentType:    "application/json-patch+json",
+				requestBody:    `[{"op": "replace", "path": "", "value": ["abc"]}]`,
+				wantHTTPStatus: http.StatusBadRequest,
+			},
+			{
+				name:           "stale ETag",
+				contentType:    "application/merge-patch+json",
+				ifMatch:        `"9999"`,
+				requestBody:    `{"abc": null}`,
+				wantHTTPStatus: http.StatusPreconditionFailed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				customData := test.GenerateInMemoryTestData(t)
+				repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+				svc := service.NewConfig(repo)
+				configController := controller.NewConfig(svc)
+
+				r := mux.NewRouter()
+				configController.SetRouter(r)
+
+				req := httptest.NewRequest(http.MethodPatch,
+					fmt.Sprintf("/configs/%s", test.ConfigName1),
+					strings.NewReader(tt.requestBody))
+				req.Header.Set("Content-Type", tt.contentType)
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				assert.Equal(t, tt.wantHTTPStatus, rr.Code)
+
+				if tt.wantMetadata != "" {
+					config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+					require.NoError(t, err)
+					assert.JSONEq(t, tt.wantMetadata, string(config.Metadata))
+				}
+			})
+		}
+	})
 }
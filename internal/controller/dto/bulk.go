@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"errors"
+)
+
+// operation types a BulkOp can have.
+const (
+	OpCreate = "create"
+	OpUpsert = "upsert"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// BulkOp is the data transfer object for an operation of a bulk request.
+type BulkOp struct {
+	// Op is the kind of change made to the config,
+	// one of create, upsert, update and delete.
+	Op string `json:"op"`
+	// Namespace is the name of the namespace holding the config,
+	// the namespace of the request path when it's empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the config.
+	Name string `json:"name"`
+	// Metadata is the metadata the config is left with.
+	// It's required by every operation but delete.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// Parents are the configs the config inherits from. Updates keep the ones
+	// the config has when it's omitted. It's ignored when deleting.
+	Parents []string `json:"parents,omitempty"`
+	// Revision makes the operation conditional on the config still being at revision.
+	// It's ignored when creating.
+	Revision int64 `json:"revision,omitempty"`
+}
+
+// Validate returns an error ErrFailedValidation if BulkOp
+// doesn't pass validation of the schema.
+func (o BulkOp) Validate() (err error) {
+	switch o.Op {
+	case OpCreate, OpUpsert, OpUpdate:
+		if o.Metadata == nil {
+			err = errors.Join(err, fieldError("metadata", "metadata is required"))
+		} else if metadataErr := o.Metadata.Validate(); metadataErr != nil {
+			err = errors.Join(err, metadataErr)
+		}
+	case OpDelete:
+	default:
+		err = errors.Join(err, fieldError("op", "op %q must be one of create, upsert, update and delete", o.Op))
+	}
+
+	if o.Name == "" {
+		err = errors.Join(err, fieldError("name", "name is required"))
+	}
+
+	if parentsErr := ValidateParents(o.Parents); parentsErr != nil {
+		err = errors.Join(err, parentsErr)
+	}
+
+	if err != nil {
+		return errors.Join(ErrFailedValidation, err)
+	}
+
+	return nil
+}
+
+// BulkResult is the data transfer object for the outcome of an operation of a bulk request.
+type BulkResult struct {
+	// Code is the HTTP status the operation would have had on its own.
+	Code int `json:"code"`
+	// Error describes why the operation failed, if it did.
+	Error string `json:"error,omitempty"`
+	// Config is the config as it was left by the operation,
+	// or right before it when it was deleted.
+	Config *Config `json:"config,omitempty"`
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"time"
 )
 
 var (
@@ -14,27 +15,47 @@ var (
 
 // Config is the data transfer object for the config controller request and response.
 type Config struct {
+	// Namespace is the name of the namespace holding the config.
+	// It's ignored in requests, where the namespace comes from the path.
+	Namespace string `json:"namespace,omitempty"`
 	// Name is the name of the config.
 	Name string `json:"name,omitempty"`
 	// Metadata is the arbitrary key value pairs of metadata
 	// that compose a config.
 	Metadata Metadata `json:"metadata"`
+	// Parents are the names of the configs in the same namespace the config
+	// inherits metadata from, each overriding the ones before it.
+	Parents []string `json:"parents,omitempty"`
+	// Revision identifies the version of the config.
+	// It's ignored in requests.
+	Revision int64 `json:"revision,omitempty"`
+	// UpdatedAt is the time when this version of the config was stored.
+	// It's ignored in requests.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
 }
 
 // Validate returns an error ErrFailedValidation if Config
 // doesn't pass validation of the schema.
 func (c Config) Validate() (err error) {
 	if c.Name == "" {
-		err = errors.Join(ErrFailedValidation, errors.New("name is required"))
+		err = errors.Join(err, fieldError("name", "name is required"))
 	}
 
 	if c.Metadata != nil {
 		if metadataErr := c.Metadata.Validate(); metadataErr != nil {
-			err = errors.Join(ErrFailedValidation, metadataErr)
+			err = errors.Join(err, metadataErr)
 		}
 	}
 
-	return err
+	if parentsErr := ValidateParents(c.Parents); parentsErr != nil {
+		err = errors.Join(err, parentsErr)
+	}
+
+	if err != nil {
+		return errors.Join(ErrFailedValidation, err)
+	}
+
+	return nil
 }
 
 // ToDomainConfig converts the dto.Config into a domain.Config.
@@ -47,20 +68,59 @@ func (c Config) ToDomainConfig() (domain.Config, error) {
 	return domain.Config{
 		Name:     c.Name,
 		Metadata: bytes,
+		Parents:  c.Parents,
 	}, nil
 }
 
-// FromDomainConfig converts the domain.Config into a dto.Config.
+// FromDomainConfig converts the domain.Config into a dto.Config,
+// with its secret metadata values redacted.
 func FromDomainConfig(d domain.Config) (Config, error) {
+	redacted, err := domain.RedactSecrets(d.Metadata)
+	if err != nil {
+		return Config{}, err
+	}
+	d.Metadata = redacted
+
+	return FromStoredConfig(d)
+}
+
+// FromStoredConfig converts the domain.Config into a dto.Config, with
+// its secret metadata values left encrypted, as they're exported.
+func FromStoredConfig(d domain.Config) (Config, error) {
 	var metadata map[string]any
 
-	err := json.Unmarshal(d.Metadata, &metadata)
+	err := domain.DecodeJSON(d.Metadata, &metadata)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
-	return Config{
-		Name:     d.Name,
-		Metadata: metadata,
-	}, nil
+	config := Config{
+		Namespace: d.Namespace,
+		Name:      d.Name,
+		Metadata:  metadata,
+		Parents:   d.Parents,
+		Revision:  d.Revision,
+	}
+	if !d.UpdatedAt.IsZero() {
+		config.UpdatedAt = &d.UpdatedAt
+	}
+
+	return config, nil
+}
+
+// ValidateParents returns an error ErrFailedValidation if parents
+// has empty or duplicated names.
+func ValidateParents(parents []string) error {
+	seen := make(map[string]bool, len(parents))
+	for _, parent := range parents {
+		if parent == "" {
+			return errors.Join(ErrFailedValidation, fieldError("parents", "parents can't be empty"))
+		}
+		if seen[parent] {
+			return errors.Join(ErrFailedValidation, fieldError("parents", "parent %q is duplicated", parent))
+		}
+		seen[parent] = true
+	}
+
+	return nil
 }
@@ -12,6 +12,7 @@ func TestConfig_ToDomainConfig(t *testing.T) {
 	dtoConfig := dto.Config{
 		Name:     "config name",
 		Metadata: map[string]any{"foo": "bar"},
+		Parents:  []string{"base"},
 	}
 
 	domainConfig, err := dtoConfig.ToDomainConfig()
@@ -19,6 +20,7 @@ func TestConfig_ToDomainConfig(t *testing.T) {
 
 	assert.Equal(t, "config name", domainConfig.Name)
 	assert.Contains(t, string(domainConfig.Metadata), "foo")
+	assert.Equal(t, []string{"base"}, domainConfig.Parents)
 }
 
 func TestFromDomainConfig(t *testing.T) {
@@ -69,23 +71,34 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: dto.ErrFailedValidation,
 		},
 		{
-			name: "invalid metadata",
+			name: "typed metadata values",
 			config: dto.Config{
 				Name:     "config name",
-				Metadata: map[string]any{"foo": 8},
+				Metadata: map[string]any{"port": 8.0, "enabled": true, "owner": nil, "hosts": []any{"eu-1", 2.0}},
 			},
-			wantErr: dto.ErrFailedValidation,
+			wantErr: nil,
 		},
 		{
-			name: "nested non-string metadata value",
+			name: "nested typed metadata values",
 			config: dto.Config{
 				Name: "config name",
 				Metadata: map[string]any{
 					"nest": map[string]any{
-						"foo": 8,
+						"foo":  8.0,
+						"list": []any{map[string]any{"bar": false}, []any{nil}},
 					},
 				},
 			},
+			wantErr: nil,
+		},
+		{
+			name: "metadata value that isn't JSON",
+			config: dto.Config{
+				Name: "config name",
+				Metadata: map[string]any{
+					"list": []any{struct{}{}},
+				},
+			},
 			wantErr: dto.ErrFailedValidation,
 		},
 		{
@@ -100,6 +113,33 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: dto.ErrFailedValidation,
 		},
+		{
+			name: "valid config with parents",
+			config: dto.Config{
+				Name:     "config name",
+				Metadata: map[string]any{"foo": "bar"},
+				Parents:  []string{"base", "eu"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "empty parent",
+			config: dto.Config{
+				Name:     "config name",
+				Metadata: map[string]any{"foo": "bar"},
+				Parents:  []string{""},
+			},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name: "duplicated parent",
+			config: dto.Config{
+				Name:     "config name",
+				Metadata: map[string]any{"foo": "bar"},
+				Parents:  []string{"base", "base"},
+			},
+			wantErr: dto.ErrFailedValidation,
+		},
 		{
 			name: "nil metadata doesn't cause panic",
 			config: dto.Config{
@@ -116,3 +156,16 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataFromByteSlice(t *testing.T) {
+	t.Run("metadata is converted", func(t *testing.T) {
+		metadata, err := dto.MetadataFromByteSlice([]byte(`{"foo": {"bar": "baz"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"bar": "baz"}, metadata["foo"])
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := dto.MetadataFromByteSlice([]byte(`{"foo":`))
+		assert.Error(t, err)
+	})
+}
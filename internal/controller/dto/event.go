@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"time"
+)
+
+// Event is the data transfer object for the events streamed by the events controller.
+type Event struct {
+	// ID identifies the event.
+	ID int64 `json:"id"`
+	// Type is the kind of change made to the config,
+	// one of created, updated and deleted.
+	Type string `json:"type"`
+	// Config is the config as it was right after the change,
+	// or right before it when it was deleted.
+	Config Config `json:"config"`
+	// Time is the time when the change was made.
+	Time time.Time `json:"time"`
+}
+
+// FromDomainEvent converts a domain.Event into a dto.Event.
+func FromDomainEvent(d domain.Event) (Event, error) {
+	config, err := FromDomainConfig(d.Config)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:     d.ID,
+		Type:   string(d.Type),
+		Config: config,
+		Time:   d.Time,
+	}, nil
+}
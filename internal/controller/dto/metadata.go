@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
 )
 
 // Metadata represents an independent representation of
@@ -21,35 +22,52 @@ func (m Metadata) ToByteSlice() ([]byte, error) {
 	return bytes, nil
 }
 
+// MetadataFromByteSlice converts bytes into Metadata.
+func MetadataFromByteSlice(bytes []byte) (Metadata, error) {
+	var m Metadata
+	if err := domain.DecodeJSON(bytes, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return m, nil
+}
+
 // Validate returns an error ErrFailedValidation if Config.Metadata
 // doesn't pass validation of the schema.
 func (m Metadata) Validate() error {
-	// traverse metadata to find any non-string value.
+	// traverse metadata to find any value that isn't a JSON value.
 	for _, v := range m {
-		if isThereNonStringMetadataKeyValues(v) {
-			return errors.Join(ErrFailedValidation, errors.New("metadata value is invalid"))
+		if !isJSONMetadataValue(v) {
+			return errors.Join(ErrFailedValidation, fieldError("metadata", "metadata value is invalid"))
 		}
 	}
 	return nil
 }
 
-// isThereNonStringMetadataKeyValues traverses v to check if there's
-// any non-string key/value pair in the data structure.
-func isThereNonStringMetadataKeyValues(v any) bool {
+// isJSONMetadataValue traverses v to check if every value
+// in the data structure is a JSON value, as decoded from JSON.
+func isJSONMetadataValue(v any) bool {
 	switch t := v.(type) {
-	// in case v is already a string, we've hit the end of the branch
-	case string:
-		return false
-	// in case it's a map, we still have a way to traverse
+	// in case v is a scalar, we've hit the end of the branch
+	case string, float64, json.Number, bool, nil:
+		return true
+	// in case it's an array or a map, we still have a way to traverse
+	case []any:
+		for _, v := range t {
+			if !isJSONMetadataValue(v) {
+				return false
+			}
+		}
+		return true
 	case map[string]any:
 		for _, v := range t {
-			if isThereNonStringMetadataKeyValues(v) {
-				return true
+			if !isJSONMetadataValue(v) {
+				return false
 			}
 		}
-		return false
-	// if it's neither a string or a map, then it's a non-string type.
-	default:
 		return true
+	// anything else can't come from JSON.
+	default:
+		return false
 	}
 }
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"regexp"
+	"time"
+)
+
+// namespaceNamePattern restricts namespace names to DNS labels,
+// so that they're safe to use in paths.
+var namespaceNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// Namespace is the data transfer object for the namespace controller request and response.
+type Namespace struct {
+	// Name is the name of the namespace.
+	Name string `json:"name"`
+	// CreatedAt is the time when the namespace was created.
+	// It's ignored in requests.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// Validate returns an error ErrFailedValidation if Namespace
+// doesn't pass validation of the schema.
+func (n Namespace) Validate() error {
+	if n.Name == "" {
+		return errors.Join(ErrFailedValidation, fieldError("name", "name is required"))
+	}
+
+	if !namespaceNamePattern.MatchString(n.Name) {
+		return errors.Join(ErrFailedValidation, fieldError("name", "name %q must be a lowercase DNS label", n.Name))
+	}
+
+	return nil
+}
+
+// FromDomainNamespace converts a domain.Namespace into a dto.Namespace.
+func FromDomainNamespace(d domain.Namespace) Namespace {
+	namespace := Namespace{Name: d.Name}
+	if !d.CreatedAt.IsZero() {
+		createdAt := d.CreatedAt
+		namespace.CreatedAt = &createdAt
+	}
+
+	return namespace
+}
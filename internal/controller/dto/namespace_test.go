@@ -0,0 +1,32 @@
+package dto_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNamespace_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ns      string
+		wantErr bool
+	}{
+		{name: "valid name", ns: "team-a"},
+		{name: "missing name", ns: "", wantErr: true},
+		{name: "uppercase name", ns: "Team", wantErr: true},
+		{name: "name with a slash", ns: "team/a", wantErr: true},
+		{name: "name ending with a dash", ns: "team-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dto.Namespace{Name: tt.ns}.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, dto.ErrFailedValidation)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ProblemContentType is the media type of Problem responses.
+const ProblemContentType = "application/problem+json"
+
+// codes a Problem can have, which are stable, so that clients can rely on them.
+const (
+	CodeInternal             = "internal_error"
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeInvalidPage          = "invalid_page"
+	CodeInvalidQuery         = "invalid_query"
+	CodeInvalidOp            = "invalid_op"
+	CodeNamespaceNotFound    = "namespace_not_found"
+	CodeNamespaceExists      = "namespace_exists"
+	CodeNamespaceNotEmpty    = "namespace_not_empty"
+	CodeDefaultNamespace     = "default_namespace"
+	CodeConfigNotFound       = "config_not_found"
+	CodeConfigExists         = "config_exists"
+	CodeConfigHasChildren    = "config_has_children"
+	CodeRevisionNotFound     = "revision_not_found"
+	CodeParentNotFound       = "parent_not_found"
+	CodeParentCycle          = "parent_cycle"
+	CodeKeyNotFound          = "key_not_found"
+	CodeKeyConflict          = "key_conflict"
+	CodeInvalidKey           = "invalid_key"
+	CodeInvalidPatch         = "invalid_patch"
+	CodePatchTestFailed      = "patch_test_failed"
+	CodeInvalidReference     = "invalid_reference"
+	CodeReferenceNotFound    = "reference_not_found"
+	CodeReferenceCycle       = "reference_cycle"
+	CodeFlatKeyCollision     = "flat_key_collision"
+	CodeSchemaNotFound       = "schema_not_found"
+	CodeInvalidSchema        = "invalid_schema"
+	CodeSchemaViolation      = "schema_violation"
+	CodeWebhookNotFound      = "webhook_not_found"
+	CodeRevealForbidden      = "reveal_forbidden"
+	CodeInvalidSecret        = "invalid_secret"
+	CodeSecretsDisabled      = "secrets_disabled"
+)
+
+// Problem is the data transfer object for error responses, as described by RFC 7807.
+type Problem struct {
+	// Type identifies the type of problem, which is always about:blank,
+	// since Code tells problems apart.
+	Type string `json:"type"`
+	// Title is the text of Status.
+	Title string `json:"title"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// Code identifies the problem, in a way clients can rely on.
+	Code string `json:"code"`
+	// Detail describes this occurrence of the problem.
+	Detail string `json:"detail"`
+	// Errors are the fields of the request body failing validation.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem creates a Problem with status and code, described by detail.
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// FieldError is a field of a request body failing validation.
+type FieldError struct {
+	// Field is the name of the field, as it's found in the request body.
+	Field string `json:"field"`
+	// Message describes why the field fails validation.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// fieldError creates a FieldError of field, with the message in format.
+func fieldError(field, format string, args ...any) error {
+	return FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// FieldErrors gets the fields failing validation in err, as returned by Validate,
+// in the order they're validated.
+func FieldErrors(err error) []FieldError {
+	if fieldErr, ok := err.(FieldError); ok {
+		return []FieldError{fieldErr}
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+
+	var fieldErrs []FieldError
+	for _, err := range joined.Unwrap() {
+		fieldErrs = append(fieldErrs, FieldErrors(err)...)
+	}
+
+	return fieldErrs
+}
@@ -0,0 +1,54 @@
+package dto_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	problem := dto.NewProblem(http.StatusConflict, dto.CodeConfigExists, "config already exists")
+
+	assert.Equal(t, dto.Problem{
+		Type:   "about:blank",
+		Title:  "Conflict",
+		Status: http.StatusConflict,
+		Code:   dto.CodeConfigExists,
+		Detail: "config already exists",
+	}, problem)
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []dto.FieldError
+	}{
+		{
+			name: "failed validation",
+			err:  dto.Config{Metadata: map[string]any{"foo": struct{}{}}, Parents: []string{""}}.Validate(),
+			want: []dto.FieldError{
+				{Field: "name", Message: "name is required"},
+				{Field: "metadata", Message: "metadata value is invalid"},
+				{Field: "parents", Message: "parents can't be empty"},
+			},
+		},
+		{
+			name: "passed validation",
+			err:  dto.Config{Name: "foo", Metadata: map[string]any{"foo": "bar"}}.Validate(),
+		},
+		{
+			name: "not a validation error",
+			err:  fmt.Errorf("wrapped: %w", errors.New("boom")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dto.FieldErrors(tt.err))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"time"
+)
+
+// Schema is the data transfer object for the schema controller request and response.
+type Schema struct {
+	// Name identifies the schema.
+	// It's ignored in requests, where the name comes from the path.
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the configs the schema is attached to,
+	// every namespace when it's empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Pattern is either the name of the config the schema is attached to,
+	// or a pattern the names of the configs match, such as `payments-*`.
+	Pattern string `json:"pattern"`
+	// Definition is the JSON Schema the metadata of the configs must satisfy.
+	Definition json.RawMessage `json:"definition" swaggertype:"object"`
+	// CreatedAt is the time when the schema was created.
+	// It's ignored in requests.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	// UpdatedAt is the time when the schema was last changed.
+	// It's ignored in requests.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// Validate returns an error ErrFailedValidation if Schema
+// doesn't pass validation of the schema.
+func (s Schema) Validate() (err error) {
+	if s.Pattern == "" {
+		err = errors.Join(err, fieldError("pattern", "pattern is required"))
+	}
+
+	if len(s.Definition) == 0 {
+		err = errors.Join(err, fieldError("definition", "definition is required"))
+	}
+
+	if err != nil {
+		return errors.Join(ErrFailedValidation, err)
+	}
+
+	return nil
+}
+
+// ToDomainSchema converts the dto.Schema into a domain.Schema.
+func (s Schema) ToDomainSchema() domain.Schema {
+	return domain.Schema{
+		Name:       s.Name,
+		Namespace:  s.Namespace,
+		Pattern:    s.Pattern,
+		Definition: s.Definition,
+	}
+}
+
+// FromDomainSchema converts a domain.Schema into a dto.Schema.
+func FromDomainSchema(d domain.Schema) Schema {
+	schema := Schema{
+		Name:       d.Name,
+		Namespace:  d.Namespace,
+		Pattern:    d.Pattern,
+		Definition: d.Definition,
+	}
+	if !d.CreatedAt.IsZero() {
+		createdAt := d.CreatedAt
+		schema.CreatedAt = &createdAt
+	}
+	if !d.UpdatedAt.IsZero() {
+		updatedAt := d.UpdatedAt
+		schema.UpdatedAt = &updatedAt
+	}
+
+	return schema
+}
+
+// SchemaResult is the outcome of putting a schema.
+type SchemaResult struct {
+	// Schema is the schema as it's stored.
+	Schema Schema `json:"schema"`
+	// Violators are the existing configs violating the schema.
+	Violators []Violator `json:"violators"`
+}
+
+// Violator is the data transfer object for a config violating a schema.
+type Violator struct {
+	// Namespace is the name of the namespace holding the config.
+	Namespace string `json:"namespace"`
+	// Name is the name of the config.
+	Name string `json:"name"`
+	// Revision is the revision of the config violating the schema.
+	Revision int64 `json:"revision"`
+	// Violations are the ways the metadata of the config violates the schema.
+	Violations []Violation `json:"violations"`
+}
+
+// Violation is the data transfer object for a way metadata violates a schema.
+type Violation struct {
+	// Path is the metadata key the violation is found at, in the `aaa.bbb.ccc`
+	// format, which is empty for the whole metadata.
+	Path string `json:"path"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
@@ -0,0 +1,31 @@
+package dto_test
+
+import (
+	"encoding/json"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  dto.Schema
+		wantErr bool
+	}{
+		{name: "valid schema", schema: dto.Schema{Pattern: "flags-*", Definition: json.RawMessage(`{"type": "object"}`)}},
+		{name: "missing pattern", schema: dto.Schema{Definition: json.RawMessage(`{}`)}, wantErr: true},
+		{name: "missing definition", schema: dto.Schema{Pattern: "flags-*"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, dto.ErrFailedValidation)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// ExportManifest is the data transfer object describing the content of an export archive.
+type ExportManifest struct {
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+	// ExportedAt is the time when the configs were exported.
+	ExportedAt time.Time `json:"exportedAt"`
+	// Configs is the number of configs in the archive.
+	Configs int `json:"configs"`
+	// Files describes every other file in the archive.
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is the data transfer object for a file listed in an ExportManifest.
+type ManifestFile struct {
+	// Name is the name of the file in the archive.
+	Name string `json:"name"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded SHA-256 checksum of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// ImportReport is the data transfer object for the outcome of an import.
+type ImportReport struct {
+	// DryRun tells if the import only reports what it would change.
+	DryRun bool `json:"dryRun"`
+	// Strategy is what the import does with the configs that exist already.
+	Strategy string `json:"strategy"`
+	// Created is the number of configs created.
+	Created int `json:"created"`
+	// Overwritten is the number of existing configs overwritten.
+	Overwritten int `json:"overwritten"`
+	// Skipped is the number of existing configs left as they are.
+	Skipped int `json:"skipped"`
+	// Conflicts is the number of existing configs failing the import.
+	Conflicts int `json:"conflicts"`
+	// Invalid is the number of lines that can't be imported.
+	Invalid int `json:"invalid"`
+	// Items describes what the import does with every line.
+	Items []ImportItem `json:"items"`
+}
+
+// ImportItem is the data transfer object for what an import does with a line.
+type ImportItem struct {
+	// Line is the number of the line in the imported configs, starting at 1.
+	Line int `json:"line"`
+	// Namespace is the name of the namespace holding the config.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the config.
+	Name string `json:"name,omitempty"`
+	// Action is what's done with the config, one of create, overwrite,
+	// skip, conflict and invalid.
+	Action string `json:"action"`
+	// Error describes why the line is invalid.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// compare targets a TxnCompare can have.
+const (
+	CompareExists   = "exists"
+	CompareRevision = "revision"
+	CompareMetadata = "metadata"
+)
+
+// TxnCompare is the data transfer object for a predicate of a transaction.
+type TxnCompare struct {
+	// Target is what's checked about the config, one of exists, revision and metadata.
+	Target string `json:"target"`
+	// Namespace is the name of the namespace holding the config,
+	// the namespace of the request path when it's empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the config.
+	Name string `json:"name"`
+	// Exists is whether the config must exist, required by the exists target.
+	Exists *bool `json:"exists,omitempty"`
+	// Revision is the revision the config must be at, required by the revision target.
+	Revision int64 `json:"revision,omitempty"`
+	// Key is the path of the value in the metadata, where each dot represents
+	// each key node in a different nest level, required by the metadata target.
+	Key string `json:"key,omitempty"`
+	// Value is the value the key must have, for the metadata target.
+	Value string `json:"value,omitempty"`
+}
+
+// Validate returns an error ErrFailedValidation if TxnCompare
+// doesn't pass validation of the schema.
+func (c TxnCompare) Validate() (err error) {
+	switch c.Target {
+	case CompareExists:
+		if c.Exists == nil {
+			err = errors.Join(err, fieldError("exists", "exists is required"))
+		}
+	case CompareRevision:
+		if c.Revision <= 0 {
+			err = errors.Join(err, fieldError("revision", "revision must be a positive number"))
+		}
+	case CompareMetadata:
+		if c.Key == "" {
+			err = errors.Join(err, fieldError("key", "key is required"))
+		}
+	default:
+		err = errors.Join(err, fieldError("target", "target %q must be one of exists, revision and metadata", c.Target))
+	}
+
+	if c.Name == "" {
+		err = errors.Join(err, fieldError("name", "name is required"))
+	}
+
+	if err != nil {
+		return errors.Join(ErrFailedValidation, err)
+	}
+
+	return nil
+}
+
+// Txn is the data transfer object for a transaction, running either its
+// success or its failure operations depending on whether every predicate holds.
+type Txn struct {
+	// Compare holds the predicates that must all hold for the success operations to run.
+	Compare []TxnCompare `json:"compare"`
+	// Success holds the operations run when every predicate holds.
+	Success []BulkOp `json:"success"`
+	// Failure holds the operations run otherwise.
+	Failure []BulkOp `json:"failure"`
+}
+
+// Validate returns an error ErrFailedValidation if Txn
+// doesn't pass validation of the schema.
+func (t Txn) Validate() (err error) {
+	for n, c := range t.Compare {
+		if compareErr := c.Validate(); compareErr != nil {
+			err = errors.Join(err, fmt.Errorf("compare %d: %w", n, compareErr))
+		}
+	}
+	for n, op := range t.Success {
+		if opErr := op.Validate(); opErr != nil {
+			err = errors.Join(err, fmt.Errorf("success %d: %w", n, opErr))
+		}
+	}
+	for n, op := range t.Failure {
+		if opErr := op.Validate(); opErr != nil {
+			err = errors.Join(err, fmt.Errorf("failure %d: %w", n, opErr))
+		}
+	}
+
+	return err
+}
+
+// TxnResult is the data transfer object for the outcome of a transaction.
+type TxnResult struct {
+	// Succeeded tells if every predicate held, so that
+	// the success operations ran instead of the failure ones.
+	Succeeded bool `json:"succeeded"`
+	// Results holds the outcome of every operation that ran, in the same order.
+	Results []BulkResult `json:"results"`
+}
@@ -0,0 +1,76 @@
+package dto_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTxn_Validate(t *testing.T) {
+	exists := true
+	op := dto.BulkOp{Op: dto.OpDelete, Name: "config"}
+
+	tests := []struct {
+		name    string
+		txn     dto.Txn
+		wantErr error
+	}{
+		{
+			name: "valid",
+			txn: dto.Txn{
+				Compare: []dto.TxnCompare{
+					{Target: dto.CompareExists, Name: "config", Exists: &exists},
+					{Target: dto.CompareRevision, Name: "config", Revision: 3},
+					{Target: dto.CompareMetadata, Name: "config", Key: "foo.bar", Value: "baz"},
+				},
+				Success: []dto.BulkOp{op},
+				Failure: []dto.BulkOp{op},
+			},
+		},
+		{
+			name: "empty",
+			txn:  dto.Txn{},
+		},
+		{
+			name:    "exists without exists",
+			txn:     dto.Txn{Compare: []dto.TxnCompare{{Target: dto.CompareExists, Name: "config"}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name:    "revision without revision",
+			txn:     dto.Txn{Compare: []dto.TxnCompare{{Target: dto.CompareRevision, Name: "config"}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name:    "metadata without key",
+			txn:     dto.Txn{Compare: []dto.TxnCompare{{Target: dto.CompareMetadata, Name: "config"}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name:    "unknown target",
+			txn:     dto.Txn{Compare: []dto.TxnCompare{{Target: "size", Name: "config"}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name:    "compare without name",
+			txn:     dto.Txn{Compare: []dto.TxnCompare{{Target: dto.CompareExists, Exists: &exists}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+		{
+			name:    "invalid operation",
+			txn:     dto.Txn{Failure: []dto.BulkOp{{Op: dto.OpCreate, Name: "config"}}},
+			wantErr: dto.ErrFailedValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.txn.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package dto
+
+import (
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"net/url"
+	"time"
+)
+
+// Webhook is the data transfer object for the webhook controller request and response.
+type Webhook struct {
+	// ID identifies the webhook.
+	// It's ignored in requests.
+	ID string `json:"id,omitempty"`
+	// URL is where the events are posted to.
+	URL string `json:"url"`
+	// Namespace filters the events by the namespace of the config,
+	// every namespace when it's empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Prefix filters the events by the prefix of the config name.
+	Prefix string `json:"prefix,omitempty"`
+	// Query filters the events by an expression of the query language
+	// the metadata of the config must match.
+	Query string `json:"query,omitempty"`
+	// Secret is the shared secret the events are signed with.
+	// It's never included in responses.
+	Secret string `json:"secret,omitempty"`
+	// CreatedAt is the time when the webhook was created.
+	// It's ignored in requests.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// Validate returns an error ErrFailedValidation if Webhook
+// doesn't pass validation of the schema.
+func (w Webhook) Validate() (err error) {
+	if u, parseErr := url.Parse(w.URL); parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = errors.Join(err, fieldError("url", "url %q must be an absolute http or https URL", w.URL))
+	}
+
+	if w.Secret == "" {
+		err = errors.Join(err, fieldError("secret", "secret is required"))
+	}
+
+	if w.Query != "" {
+		if _, queryErr := query.Parse(w.Query); queryErr != nil {
+			err = errors.Join(err, fieldError("query", "%s", queryErr))
+		}
+	}
+
+	if err != nil {
+		return errors.Join(ErrFailedValidation, err)
+	}
+
+	return nil
+}
+
+// ToDomainWebhook converts the dto.Webhook into a domain.Webhook.
+func (w Webhook) ToDomainWebhook() domain.Webhook {
+	return domain.Webhook{
+		URL:       w.URL,
+		Namespace: w.Namespace,
+		Prefix:    w.Prefix,
+		Query:     w.Query,
+		Secret:    w.Secret,
+	}
+}
+
+// FromDomainWebhook converts a domain.Webhook into a dto.Webhook,
+// leaving its secret out.
+func FromDomainWebhook(d domain.Webhook) Webhook {
+	webhook := Webhook{
+		ID:        d.ID,
+		URL:       d.URL,
+		Namespace: d.Namespace,
+		Prefix:    d.Prefix,
+		Query:     d.Query,
+	}
+	if !d.CreatedAt.IsZero() {
+		createdAt := d.CreatedAt
+		webhook.CreatedAt = &createdAt
+	}
+
+	return webhook
+}
+
+// Delivery is the data transfer object for the deliveries of a webhook.
+type Delivery struct {
+	// ID identifies the delivery.
+	ID string `json:"id"`
+	// Event is the event being posted.
+	Event Event `json:"event"`
+	// Status is the state the delivery is in, one of pending, succeeded and failed.
+	Status string `json:"status"`
+	// Attempts is the number of times the event has been posted.
+	Attempts int `json:"attempts"`
+	// ResponseStatus is the HTTP status of the response to the last attempt.
+	ResponseStatus int `json:"responseStatus,omitempty"`
+	// Error describes why the last attempt failed.
+	Error string `json:"error,omitempty"`
+	// CreatedAt is the time when the delivery was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time of the last attempt.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// FromDomainDelivery converts a domain.Delivery into a dto.Delivery.
+func FromDomainDelivery(d domain.Delivery) (Delivery, error) {
+	event, err := FromDomainEvent(d.Event)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	return Delivery{
+		ID:             d.ID,
+		Event:          event,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}, nil
+}
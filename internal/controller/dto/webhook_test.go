@@ -0,0 +1,48 @@
+package dto_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook dto.Webhook
+		wantErr bool
+	}{
+		{name: "valid webhook", webhook: dto.Webhook{URL: "https://example.com/hook", Secret: "s3cr3t", Query: "region = eu"}},
+		{name: "missing URL", webhook: dto.Webhook{Secret: "s3cr3t"}, wantErr: true},
+		{name: "relative URL", webhook: dto.Webhook{URL: "/hook", Secret: "s3cr3t"}, wantErr: true},
+		{name: "unsupported scheme", webhook: dto.Webhook{URL: "ftp://example.com", Secret: "s3cr3t"}, wantErr: true},
+		{name: "missing secret", webhook: dto.Webhook{URL: "https://example.com/hook"}, wantErr: true},
+		{name: "invalid query", webhook: dto.Webhook{URL: "https://example.com/hook", Secret: "s3cr3t", Query: "region like eu"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.webhook.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, dto.ErrFailedValidation)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFromDomainWebhook(t *testing.T) {
+	createdAt := time.Now()
+	webhook := dto.FromDomainWebhook(domain.Webhook{ID: "abc", URL: "https://example.com", Secret: "s3cr3t", CreatedAt: createdAt})
+
+	t.Run("secret is left out", func(t *testing.T) {
+		assert.Empty(t, webhook.Secret)
+	})
+
+	t.Run("everything else is kept", func(t *testing.T) {
+		assert.Equal(t, dto.Webhook{ID: "abc", URL: "https://example.com", CreatedAt: &createdAt}, webhook)
+	})
+}
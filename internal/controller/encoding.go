@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"io"
+	"log"
+	"net/http"
+)
+
+// errUnsupportedMediaType is used when the request body is in a media type that isn't supported.
+var errUnsupportedMediaType = errors.New("only application/json and application/yaml request bodies are supported")
+
+// maxBodySize is the maximum size in bytes of request bodies, but the ones of imports.
+const maxBodySize = 8 << 20
+
+// limitBody gets the body of r limited to maxBodySize bytes, past which
+// reading it fails with *http.MaxBytesError.
+func limitBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	return http.MaxBytesReader(w, r.Body, maxBodySize)
+}
+
+// decodeBody decodes the request body into v, as JSON or YAML according
+// to its content type, taking it as JSON when there's none. Numbers are
+// decoded as json.Number, so that they're kept as they are.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	switch contentType := mediaType(r); {
+	case contentType == "" || contentType == middleware.JSONContentType:
+		decoder := json.NewDecoder(limitBody(w, r))
+		decoder.UseNumber()
+		return decoder.Decode(v)
+	case middleware.IsYAML(contentType):
+		body, err := io.ReadAll(limitBody(w, r))
+		if err != nil {
+			return err
+		}
+		document, err := yamlToJSON(body)
+		if err != nil {
+			return err
+		}
+		return domain.DecodeJSON(document, v)
+	default:
+		return errUnsupportedMediaType
+	}
+}
+
+// writeDecodeError writes the error response of err, met while decoding the request body.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, errUnsupportedMediaType) || errors.As(err, &maxBytesErr) {
+		writeError(w, err)
+		return
+	}
+	writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, err.Error())
+}
+
+// writeResponse marshals v in the content type negotiated for r,
+// and writes it as the response body along with status.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if middleware.ContentType(r) != middleware.YAMLContentType {
+		writeJSON(w, status, v)
+		return
+	}
+
+	document, err := json.Marshal(v)
+	if err == nil {
+		document, err = jsonToYAML(document)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(document); err != nil {
+		log.Printf("Failed to write response: %s", err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// prefixParam filters the streamed events by the prefix of the config name.
+	prefixParam = "prefix"
+	// lastEventIDHeader is the header clients resume a stream of events with.
+	lastEventIDHeader = "Last-Event-ID"
+	// defaultHeartbeat is how often a heartbeat is sent to idle streams by default.
+	defaultHeartbeat = 15 * time.Second
+)
+
+// EventsOption customizes an Events controller instance.
+type EventsOption func(*Events)
+
+// WithHeartbeat sends a heartbeat to idle streams every interval,
+// so that proxies don't take them for dead connections.
+func WithHeartbeat(interval time.Duration) EventsOption {
+	return func(e *Events) {
+		e.heartbeat = interval
+	}
+}
+
+// NewEvents creates a new Events controller instance.
+// It expects the events published by the config service as a dependency.
+func NewEvents(events *service.Events, opts ...EventsOption) *Events {
+	e := &Events{events: events, heartbeat: defaultHeartbeat}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Events is the events controller.
+// It defines routes and handlers to stream the changes made to configs.
+type Events struct {
+	events    *service.Events
+	heartbeat time.Duration
+}
+
+// SetRouter returns the router r with all the necessary routes for the
+// Events controller setup.
+func (e Events) SetRouter(r *mux.Router) {
+	r.HandleFunc("/events", e.stream).
+		Methods(http.MethodGet)
+	r.HandleFunc("/namespaces/{namespace}/events", e.stream).
+		Methods(http.MethodGet)
+}
+
+// @Summary Stream config changes
+// @Description Streams an event for every config created, updated or deleted as Server-Sent Events.
+// @Description Events are streamed across every namespace, unless a namespace is given,
+// @Description and can be filtered by name prefix and by the same query params /search takes.
+// @Tags events
+// @Produce text/event-stream
+// @Param namespace path string false "Namespace of the configs, every namespace when omitted"
+// @Param prefix query string false "Prefix of the names of the configs"
+// @Param q query string false "Expression of the query language the metadata must match"
+// @Param Last-Event-ID header int false "ID of the last event received, to resume the stream from"
+// @Success 200 {object} dto.Event
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /events [get]
+// @Router /namespaces/{namespace}/events [get]
+func (e Events) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, http.StatusInternalServerError, dto.CodeInternal, "streaming is not supported")
+		return
+	}
+
+	namespace := mux.Vars(r)["namespace"]
+
+	values := r.URL.Query()
+	prefix := values.Get(prefixParam)
+	values.Del(prefixParam)
+
+	expr, err := query.ParseValues(values)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var lastEventID int64
+	if rawID := r.Header.Get(lastEventIDHeader); rawID != "" {
+		lastEventID, err = strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "Last-Event-ID must be an integer")
+			return
+		}
+	}
+
+	matches := func(event domain.Event) bool {
+		return (namespace == "" || event.Config.Namespace == namespace) &&
+			strings.HasPrefix(event.Config.Name, prefix) &&
+			query.Match(expr, domain.WithoutSecrets(event.Config.Metadata))
+	}
+
+	sub := e.events.Subscribe(lastEventID)
+	defer e.events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range sub.Backlog {
+		if matches(event) {
+			writeEvent(w, event)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(e.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			// the subscription is dropped either when falling behind or when
+			// shutting down, so the client is left to resume from the last event.
+			if !ok {
+				return
+			}
+			if !matches(event) {
+				continue
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes event in the Server-Sent Events format.
+func writeEvent(w http.ResponseWriter, event domain.Event) {
+	dtoEvent, err := dto.FromDomainEvent(event)
+	if err != nil {
+		return
+	}
+
+	// the JSON encoding never spans more than a line,
+	// so it fits in a single data field.
+	data, err := json.Marshal(dtoEvent)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,169 @@
+package controller_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is an event read off a Server-Sent Events stream.
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads the next event off stream, skipping comments.
+func readEvent(t *testing.T, stream *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.id != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	events := service.NewEvents(service.DefaultEventBufferSize)
+	svc := service.NewConfig(repository.NewInMemoryConfig(), service.WithEvents(events))
+
+	r := mux.NewRouter()
+	controller.NewEvents(events, controller.WithHeartbeat(10*time.Millisecond)).SetRouter(r)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// subscribe opens a stream at target, returning it once it's established.
+	subscribe := func(t *testing.T, target string, header http.Header) *bufio.Reader {
+		req, err := http.NewRequest(http.MethodGet, server.URL+target, nil)
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { res.Body.Close() })
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		return bufio.NewReader(res.Body)
+	}
+
+	t.Run("changes are streamed", func(t *testing.T) {
+		stream := subscribe(t, "/events", nil)
+
+		require.NoError(t, svc.Create(domain.Config{Name: "web-1", Metadata: []byte(`{"region": "eu"}`)}))
+		require.NoError(t, svc.Update(domain.DefaultNamespace, "web-1", []byte(`{"region": "us"}`)))
+		require.NoError(t, svc.Delete(domain.DefaultNamespace, "web-1"))
+
+		for _, wantType := range []string{"created", "updated", "deleted"} {
+			e := readEvent(t, stream)
+			assert.Equal(t, wantType, e.event)
+
+			var event dto.Event
+			require.NoError(t, json.Unmarshal([]byte(e.data), &event))
+			assert.Equal(t, "web-1", event.Config.Name)
+		}
+	})
+
+	t.Run("changes are filtered", func(t *testing.T) {
+		stream := subscribe(t, "/events?prefix=api-&region=eu", nil)
+
+		require.NoError(t, svc.Create(domain.Config{Name: "web-2", Metadata: []byte(`{"region": "eu"}`)}))
+		require.NoError(t, svc.Create(domain.Config{Name: "api-1", Metadata: []byte(`{"region": "us"}`)}))
+		require.NoError(t, svc.Create(domain.Config{Name: "api-2", Metadata: []byte(`{"region": "eu"}`)}))
+
+		var event dto.Event
+		require.NoError(t, json.Unmarshal([]byte(readEvent(t, stream).data), &event))
+		assert.Equal(t, "api-2", event.Config.Name)
+	})
+
+	t.Run("secret values aren't matched", func(t *testing.T) {
+		secrets, err := service.NewSecrets([]byte("0123456789abcdef0123456789abcdef"))
+		require.NoError(t, err)
+		sealing := service.NewConfig(repository.NewInMemoryConfig(), service.WithEvents(events), service.WithSecrets(secrets))
+
+		stream := subscribe(t, "/events?prefix=db-&password[exists]=true", nil)
+
+		require.NoError(t, sealing.Create(domain.Config{Name: "db-1", Metadata: []byte(`{"password": "secret:hunter2"}`)}))
+		require.NoError(t, sealing.Create(domain.Config{Name: "db-2", Metadata: []byte(`{"password": "hunter2"}`)}))
+
+		var event dto.Event
+		require.NoError(t, json.Unmarshal([]byte(readEvent(t, stream).data), &event))
+		assert.Equal(t, "db-2", event.Config.Name)
+	})
+
+	t.Run("stream resumes from the last event", func(t *testing.T) {
+		stream := subscribe(t, "/events", http.Header{"Last-Event-Id": {"1"}})
+
+		e := readEvent(t, stream)
+		assert.Equal(t, "2", e.id)
+		assert.Equal(t, "updated", e.event)
+	})
+
+	t.Run("idle stream gets heartbeats", func(t *testing.T) {
+		stream := subscribe(t, "/namespaces/nope/events", nil)
+
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": heartbeat\n", line)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/events?q=" + "region%20like%20eu")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("invalid last event ID", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "latest")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("streams end when events are closed", func(t *testing.T) {
+		stream := subscribe(t, "/events", nil)
+
+		events.Close()
+
+		// heartbeats may still be in flight, but the stream ends right after.
+		_, err := io.Copy(io.Discard, stream)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formats flattened metadata can be rendered in, and sent in.
+const (
+	// formatDotenv is an env file, with a KEY=value pair per line.
+	formatDotenv = "dotenv"
+	// formatProperties is a Java .properties file, with a key=value pair per line.
+	formatProperties = "properties"
+	// formatFlatJSON is a JSON object with a "key.path": "value" pair per key.
+	formatFlatJSON = "flat-json"
+)
+
+var (
+	// errInvalidFlatDocument is used when flattened metadata can't be read.
+	errInvalidFlatDocument = errors.New("invalid flattened metadata")
+	// errFlatKeyCollision is used when different keys are rendered the same.
+	errFlatKeyCollision = errors.New("keys collide once rendered")
+)
+
+// flatFormat renders flattened metadata in a format, and reads it back.
+type flatFormat struct {
+	contentType string
+	encode      func(flat map[string]string) ([]byte, error)
+	decode      func(document []byte) (map[string]string, error)
+}
+
+// flatFormats are the formats flattened metadata can be in, by their name.
+var flatFormats = map[string]flatFormat{
+	formatDotenv:     {"text/plain; charset=utf-8", encodeDotenv, decodeDotenv},
+	formatProperties: {"text/plain; charset=utf-8", encodeProperties, decodeProperties},
+	formatFlatJSON:   {"application/json", encodeFlatJSON, decodeFlatJSON},
+}
+
+// nameParam is the query param naming a config created out of flattened metadata.
+const nameParam = "name"
+
+// @Summary Render a config
+// @Description Renders the metadata of a config flattened into key value pairs, sorted by key, in one of the formats:
+// @Description dotenv, as KEY_PATH=value lines, properties, as key.path=value lines, or flat-json, as an object of "key.path": "value" pairs.
+// @Description Strings are rendered as they are, null as an empty string, and any other value as its JSON encoding.
+// @Tags config
+// @Produce plain
+// @Produce json
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param format query string true "Format to render the config in, one of dotenv, properties and flat-json"
+// @Param resolved query bool false "Deep merge the metadata of the parents of the config under its own"
+// @Param interpolate query bool false "Replace the references in the metadata values of the config by the values they point at"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
+// @Success 200 {string} string "Rendered config"
+// @Header 200 {string} ETag "Entity tag of the config revision"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/render [get]
+// @Router /namespaces/{namespace}/configs/{name}/render [get]
+func (c Config) render(w http.ResponseWriter, r *http.Request) {
+	format, err := flatFormatOf(r.URL.Query().Get(formatParam))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	get := c.service.Get
+	if resolved, _ := strconv.ParseBool(r.URL.Query().Get(resolvedParam)); resolved {
+		get = c.service.Resolve
+	}
+
+	interpolate, _ := strconv.ParseBool(r.URL.Query().Get(interpolateParam))
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config, err := get(namespaceOf(r), mux.Vars(r)["name"])
+	if err == nil && interpolate {
+		config, err = c.service.Interpolate(config)
+	}
+	if err == nil {
+		config, err = c.presentSecrets(config, reveal)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flat, err := domain.FlattenMetadata(config.Metadata)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	document, err := format.encode(flat)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("ETag", etag(config.Revision))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(document); err != nil {
+		log.Printf("Failed to write response: %s", err.Error())
+	}
+}
+
+// decodeFlatMetadata decodes the request body as flattened metadata, in the format
+// picked by the format query param, and expands it back into nested metadata.
+func decodeFlatMetadata(w http.ResponseWriter, r *http.Request) (dto.Metadata, error) {
+	format, err := flatFormatOf(r.URL.Query().Get(formatParam))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(limitBody(w, r))
+	if err != nil {
+		return nil, err
+	}
+
+	flat, err := format.decode(body)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := domain.ExpandMetadata(flat)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestBody dto.Metadata
+	if err := json.Unmarshal(metadata, &requestBody); err != nil {
+		return nil, err
+	}
+
+	return requestBody, nil
+}
+
+// flatFormatOf gets the flat format named name.
+func flatFormatOf(name string) (flatFormat, error) {
+	format, ok := flatFormats[name]
+	if !ok {
+		return flatFormat{}, fmt.Errorf("format %q must be one of dotenv, properties and flat-json", name)
+	}
+
+	return format, nil
+}
+
+var (
+	// plainDotenvValue matches the values that don't need to be quoted in an env file.
+	plainDotenvValue = regexp.MustCompile(`^[A-Za-z0-9_./:@,+-]*$`)
+	// dotenvKey matches the valid keys of an env file.
+	dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// dotenvEscaper escapes the characters that are special in a double quoted env value.
+	dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+)
+
+// encodeDotenv renders flat as an env file, sorted by key. Keys are upper cased,
+// and every character other than letters, digits and underscores, dots included,
+// becomes an underscore, so `db.host` becomes DB_HOST. Values are double quoted
+// unless they're made of nothing but characters that are safe unquoted.
+func encodeDotenv(flat map[string]string) ([]byte, error) {
+	keys := make(map[string]string, len(flat))
+	for key := range flat {
+		envKey := strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+				return r
+			default:
+				return '_'
+			}
+		}, key)
+		if envKey[0] >= '0' && envKey[0] <= '9' {
+			envKey = "_" + envKey
+		}
+
+		if other, ok := keys[envKey]; ok {
+			a, b := min(key, other), max(key, other)
+			return nil, fmt.Errorf("%w: %q and %q are both rendered as %s", errFlatKeyCollision, a, b, envKey)
+		}
+		keys[envKey] = key
+	}
+
+	var buf bytes.Buffer
+	for _, envKey := range sortedKeys(keys) {
+		value := flat[keys[envKey]]
+		if !plainDotenvValue.MatchString(value) {
+			value = `"` + dotenvEscaper.Replace(value) + `"`
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", envKey, value)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeDotenv reads an env file back, lower casing the keys and taking
+// their underscores as dots, so DB_HOST becomes `db.host`.
+// Lines can start with export, and the ones starting with # are comments.
+// Values can be unquoted, where anything after a # preceded by a space is a
+// comment, single quoted, where they're taken literally, or double quoted,
+// where \\, \", \$, \n, \r and \t are unescaped.
+func decodeDotenv(document []byte) (map[string]string, error) {
+	flat := make(map[string]string)
+
+	for n, line := range strings.Split(string(document), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		envKey, raw, ok := strings.Cut(line, "=")
+		envKey = strings.TrimSpace(envKey)
+		if !ok || !dotenvKey.MatchString(envKey) {
+			return nil, fmt.Errorf("%w: line %d must be a KEY=value pair", errInvalidFlatDocument, n+1)
+		}
+
+		value, err := dotenvValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", errInvalidFlatDocument, n+1, err.Error())
+		}
+
+		key := strings.ReplaceAll(strings.ToLower(envKey), "_", ".")
+		if _, ok := flat[key]; ok {
+			return nil, fmt.Errorf("%w: line %d: %s is duplicated", errInvalidFlatDocument, n+1, envKey)
+		}
+		flat[key] = value
+	}
+
+	return flat, nil
+}
+
+// dotenvValue unquotes the value of an env file pair.
+func dotenvValue(raw string) (string, error) {
+	var value strings.Builder
+	var rest string
+
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", errors.New("single quoted value isn't closed")
+		}
+		value.WriteString(raw[1 : end+1])
+		rest = raw[end+2:]
+	case strings.HasPrefix(raw, `"`):
+		closed := false
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			if c == '"' {
+				closed, rest = true, raw[i+1:]
+				break
+			}
+			if c != '\\' || i+1 == len(raw) {
+				value.WriteByte(c)
+				continue
+			}
+
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '\\', '"', '$':
+				value.WriteByte(raw[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(raw[i])
+			}
+		}
+		if !closed {
+			return "", errors.New("double quoted value isn't closed")
+		}
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", errors.New("unexpected characters after the quoted value")
+	}
+
+	return value.String(), nil
+}
+
+// encodeProperties renders flat as a Java .properties file, sorted by key,
+// escaping keys and values the way java.util.Properties does, with
+// any character outside of printable ASCII as a \uXXXX escape.
+func encodeProperties(flat map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(flat) {
+		buf.WriteString(escapeProperty(key, true))
+		buf.WriteByte('=')
+		buf.WriteString(escapeProperty(flat[key], false))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// escapeProperty escapes s as a key or a value of a .properties file,
+// where only the leading spaces of values must be escaped.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		case '\\', '=', ':', '#', '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r >= 0x20 && r <= 0x7e {
+				b.WriteRune(r)
+				continue
+			}
+			// characters out of the Basic Multilingual Plane are escaped as UTF-16 surrogate pairs.
+			var units [2]uint16
+			n := 1
+			if r > 0xffff {
+				r -= 0x10000
+				units[0], units[1] = uint16(0xd800+(r>>10)), uint16(0xdc00+(r&0x3ff))
+				n = 2
+			} else {
+				units[0] = uint16(r)
+			}
+			for _, unit := range units[:n] {
+				fmt.Fprintf(&b, `\u%04X`, unit)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// decodeProperties reads a Java .properties file back, the way
+// java.util.Properties does, except that duplicated keys are rejected.
+func decodeProperties(document []byte) (map[string]string, error) {
+	flat := make(map[string]string)
+
+	lines := strings.Split(strings.ReplaceAll(string(document), "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		start := n + 1
+		line := strings.TrimLeft(lines[n], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// a line ending with an odd number of backslashes goes on in the next one.
+		for continues(line) && n+1 < len(lines) {
+			n++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[n], " \t\f")
+		}
+		if continues(line) {
+			line = line[:len(line)-1]
+		}
+
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", errInvalidFlatDocument, start, err.Error())
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", errInvalidFlatDocument, start, err.Error())
+		}
+
+		if _, ok := flat[key]; ok {
+			return nil, fmt.Errorf("%w: line %d: %s is duplicated", errInvalidFlatDocument, start, key)
+		}
+		flat[key] = value
+	}
+
+	return flat, nil
+}
+
+// continues tells if line ends with an odd number of backslashes,
+// going on in the next line.
+func continues(line string) bool {
+	backslashes := len(line) - len(strings.TrimRight(line, `\`))
+	return backslashes%2 == 1
+}
+
+// splitProperty splits a line of a .properties file into its key and value,
+// separated by the first unescaped =, : or whitespace.
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	// a single = or : can follow the whitespace separating the key and value.
+	value := strings.TrimLeft(line[end:], " \t\f")
+	if value != "" && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+
+	return line[:end], value
+}
+
+// unescapeProperty unescapes a key or a value of a .properties file.
+func unescapeProperty(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, size, err := unescapeUnicode(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+			i += size
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// unescapeUnicode reads the code point of a \uXXXX escape, out of the four hex
+// digits at the start of s, along with the low surrogate escape that follows
+// when it's a high surrogate. It returns the number of bytes read.
+func unescapeUnicode(s string) (rune, int, error) {
+	if len(s) < 4 {
+		return 0, 0, errors.New(`malformed \u escape`)
+	}
+	unit, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, 0, errors.New(`malformed \u escape`)
+	}
+
+	r := rune(unit)
+	if r < 0xd800 || r > 0xdbff || len(s) < 10 || s[4:6] != `\u` {
+		return r, 4, nil
+	}
+
+	low, err := strconv.ParseUint(s[6:10], 16, 16)
+	if err != nil || low < 0xdc00 || low > 0xdfff {
+		return r, 4, nil
+	}
+
+	return 0x10000 + (r-0xd800)<<10 + (rune(low) - 0xdc00), 10, nil
+}
+
+// encodeFlatJSON renders flat as a JSON object, sorted by key.
+func encodeFlatJSON(flat map[string]string) ([]byte, error) {
+	document, err := json.MarshalIndent(flat, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(document, '\n'), nil
+}
+
+// decodeFlatJSON reads a JSON object of string values back.
+func decodeFlatJSON(document []byte) (map[string]string, error) {
+	var flat map[string]string
+	if err := json.Unmarshal(document, &flat); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidFlatDocument, err.Error())
+	}
+	if flat == nil {
+		return nil, fmt.Errorf("%w: must be a JSON object", errInvalidFlatDocument)
+	}
+
+	return flat, nil
+}
+
+// sortedKeys gets the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,246 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Render(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	r := mux.NewRouter()
+	controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	metadata := `{
+		"db": {"host": "db.internal", "port": 5432, "password": "p@ss word \"quoted\" $HOME", "tls": true},
+		"motd": "hello\nworld",
+		"name": "café 🚀",
+		"key with=sep": " leading space",
+		"empty": null,
+		"tags": ["a", "b"],
+		"unset": {}
+	}`
+	_, err := repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: "app", Metadata: []byte(metadata)})
+	require.NoError(t, err)
+
+	t.Run("render", func(t *testing.T) {
+		tests := []struct {
+			format      string
+			contentType string
+			expected    string
+		}{
+			{
+				format:      "dotenv",
+				contentType: "text/plain; charset=utf-8",
+				expected: `DB_HOST=db.internal
+DB_PASSWORD="p@ss word \"quoted\" \$HOME"
+DB_PORT=5432
+DB_TLS=true
+EMPTY=
+KEY_WITH_SEP=" leading space"
+MOTD="hello\nworld"
+NAME="café 🚀"
+TAGS="[\"a\",\"b\"]"
+`,
+			},
+			{
+				format:      "properties",
+				contentType: "text/plain; charset=utf-8",
+				expected: `db.host=db.internal
+db.password=p@ss word "quoted" $HOME
+db.port=5432
+db.tls=true
+empty=
+key\ with\=sep=\ leading space
+motd=hello\nworld
+name=caf\u00E9 \uD83D\uDE80
+tags=["a","b"]
+`,
+			},
+			{
+				format:      "flat-json",
+				contentType: "application/json",
+				expected: `{
+  "db.host": "db.internal",
+  "db.password": "p@ss word \"quoted\" $HOME",
+  "db.port": "5432",
+  "db.tls": "true",
+  "empty": "",
+  "key with=sep": " leading space",
+  "motd": "hello\nworld",
+  "name": "café 🚀",
+  "tags": "[\"a\",\"b\"]"
+}
+`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.format, func(t *testing.T) {
+				rr := serve(http.MethodGet, "/configs/app/render?format="+tt.format, "")
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.Equal(t, tt.contentType, rr.Header().Get("Content-Type"))
+				assert.NotEmpty(t, rr.Header().Get("ETag"))
+				assert.Equal(t, tt.expected, rr.Body.String())
+			})
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		// values other than strings come back as the strings they're rendered as.
+		expected := `{
+			"db": {"host": "db.internal", "port": "5432", "password": "p@ss word \"quoted\" $HOME", "tls": "true"},
+			"motd": "hello\nworld",
+			"name": "café 🚀",
+			"key with=sep": " leading space",
+			"empty": "",
+			"tags": "[\"a\",\"b\"]"
+		}`
+
+		for _, format := range []string{"properties", "flat-json"} {
+			t.Run(format, func(t *testing.T) {
+				rendered := serve(http.MethodGet, "/configs/app/render?format="+format, "").Body.String()
+
+				rr := serve(http.MethodPost, "/configs?format="+format+"&name=app-"+format, rendered)
+				require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+				config, err := repo.Get(domain.DefaultNamespace, "app-"+format)
+				require.NoError(t, err)
+				assert.JSONEq(t, expected, string(config.Metadata))
+			})
+		}
+
+		t.Run("dotenv", func(t *testing.T) {
+			_, err := repo.Save(domain.Config{
+				Namespace: domain.DefaultNamespace,
+				Name:      "env",
+				Metadata:  []byte(`{"db": {"host": "db.internal", "password": "p@ss word \"quoted\" $HOME"}, "motd": "hello\nworld"}`),
+			})
+			require.NoError(t, err)
+			rendered := serve(http.MethodGet, "/configs/env/render?format=dotenv", "").Body.String()
+
+			rr := serve(http.MethodPut, "/configs/env?format=dotenv", rendered)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			config, err := repo.Get(domain.DefaultNamespace, "env")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"db": {"host": "db.internal", "password": "p@ss word \"quoted\" $HOME"}, "motd": "hello\nworld"}`, string(config.Metadata))
+		})
+	})
+
+	t.Run("send", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			format   string
+			body     string
+			expected string
+		}{
+			{
+				name:   "dotenv",
+				format: "dotenv",
+				body: `# database
+export DB_HOST=db.internal # primary
+DB_USER='admin # not a comment'
+DB_PASSWORD="a\"b\$c\\d"
+
+FEATURE_ENABLED=true
+`,
+				expected: `{"db": {"host": "db.internal", "user": "admin # not a comment", "password": "a\"b$c\\d"}, "feature": {"enabled": "true"}}`,
+			},
+			{
+				name:   "properties",
+				format: "properties",
+				body: `# database
+! also a comment
+db.host = db.internal
+db.user:admin
+db.password  secret
+db.hosts=a,\
+         b
+greeting=café
+`,
+				expected: `{"db": {"host": "db.internal", "user": "admin", "password": "secret", "hosts": "a,b"}, "greeting": "café"}`,
+			},
+			{
+				name:     "flat-json",
+				format:   "flat-json",
+				body:     `{"db.host": "db.internal", "db.port": "5432"}`,
+				expected: `{"db": {"host": "db.internal", "port": "5432"}}`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodPut, "/configs/app?format="+tt.format, tt.body)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+				config, err := repo.Get(domain.DefaultNamespace, "app")
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.expected, string(config.Metadata))
+			})
+		}
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		_, err := repo.Save(domain.Config{
+			Namespace: domain.DefaultNamespace,
+			Name:      "colliding",
+			Metadata:  []byte(`{"db": {"host": "a"}, "db_host": "b"}`),
+		})
+		require.NoError(t, err)
+
+		tests := []struct {
+			name     string
+			method   string
+			target   string
+			body     string
+			expected int
+		}{
+			{name: "without a format", method: http.MethodGet, target: "/configs/app/render", expected: http.StatusBadRequest},
+			{name: "with an unknown format", method: http.MethodGet, target: "/configs/app/render?format=toml", expected: http.StatusBadRequest},
+			{name: "when the config doesn't exist", method: http.MethodGet, target: "/configs/missing/render?format=dotenv", expected: http.StatusNotFound},
+			{name: "when keys collide as env keys", method: http.MethodGet, target: "/configs/colliding/render?format=dotenv", expected: http.StatusConflict},
+			{name: "to create without a name", method: http.MethodPost, target: "/configs?format=flat-json", body: `{"a": "b"}`, expected: http.StatusBadRequest},
+			{name: "to send an unknown format", method: http.MethodPut, target: "/configs/app?format=toml", body: `a=b`, expected: http.StatusBadRequest},
+			{name: "to send conflicting keys", method: http.MethodPut, target: "/configs/app?format=flat-json", body: `{"a": "b", "a.c": "d"}`, expected: http.StatusBadRequest},
+			{name: "to send empty key nodes", method: http.MethodPut, target: "/configs/app?format=properties", body: `a..b=c`, expected: http.StatusBadRequest},
+			{name: "to send non string values", method: http.MethodPut, target: "/configs/app?format=flat-json", body: `{"a": 1}`, expected: http.StatusBadRequest},
+			{name: "to send a line that isn't a pair", method: http.MethodPut, target: "/configs/app?format=dotenv", body: "A=b\nnot a pair\n", expected: http.StatusBadRequest},
+			{name: "to send an unclosed quote", method: http.MethodPut, target: "/configs/app?format=dotenv", body: `A="b`, expected: http.StatusBadRequest},
+			{name: "to send duplicated keys", method: http.MethodPut, target: "/configs/app?format=dotenv", body: "A=b\nA=c\n", expected: http.StatusBadRequest},
+			{name: "to send a malformed unicode escape", method: http.MethodPut, target: "/configs/app?format=properties", body: `a=\u00zz`, expected: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(tt.method, tt.target, tt.body)
+				assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+			})
+		}
+
+		t.Run("and leaves the config untouched", func(t *testing.T) {
+			config, err := repo.Get(domain.DefaultNamespace, "app")
+			require.NoError(t, err)
+
+			var metadata map[string]any
+			require.NoError(t, json.Unmarshal(config.Metadata, &metadata))
+			assert.Equal(t, map[string]any{"db": map[string]any{"host": "db.internal", "port": "5432"}}, metadata)
+		})
+	})
+}
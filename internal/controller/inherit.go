@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"net/http"
+)
+
+// resolvedParam is the query param serving configs with the metadata
+// they inherit from their parents resolved.
+const resolvedParam = "resolved"
+
+// @Summary Set the parents of a config
+// @Description Sets the configs in the same namespace a config inherits metadata from, each overriding the ones before it.
+// @Description Parents must exist, and can't inherit from the config, directly or not.
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param parents body []string true "Names of the parents, empty for none"
+// @Param If-Match header string false "Only set the parents if the config still matches the entity tag"
+// @Success 200
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/parents [put]
+// @Router /namespaces/{namespace}/configs/{name}/parents [put]
+func (c Config) setParents(w http.ResponseWriter, r *http.Request) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	var parents []string
+	if err := decodeBody(w, r, &parents); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := dto.ValidateParents(parents); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	revision, _, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		err = c.service.SetParents(namespace, name, revision, parents)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary List the descendants of a config
+// @Description Lists every config inheriting from a config, directly or not, sorted by name
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Success 200 {array} dto.Config
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/descendants [get]
+// @Router /namespaces/{namespace}/configs/{name}/descendants [get]
+func (c Config) descendants(w http.ResponseWriter, r *http.Request) {
+	descendants, err := c.service.Descendants(namespaceOf(r), mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeConfigs(w, r, descendants)
+}
@@ -0,0 +1,173 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Inheritance(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	r := mux.NewRouter()
+	controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+
+	serve := func(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for n := 0; n < len(headers); n += 2 {
+			req.Header.Set(headers[n], headers[n+1])
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("create with parents", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "burger-nutrition", "metadata": {"calories": "230", "allergens": {"gluten": "yes", "nuts": "no"}}}`,
+			`{"name": "burger-nutrition-eu", "metadata": {"allergens": {"nuts": "traces"}}, "parents": ["burger-nutrition"]}`,
+			`{"name": "burger-nutrition-de", "metadata": {"calories": "250"}, "parents": ["burger-nutrition-eu"]}`,
+			`{"name": "fries-nutrition", "metadata": {"calories": "300"}}`,
+		} {
+			rr := serve(http.MethodPost, "/configs", body)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/burger-nutrition-de", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var config dto.Config
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+		assert.Equal(t, []string{"burger-nutrition-eu"}, config.Parents)
+		assert.Equal(t, dto.Metadata{"calories": "250"}, config.Metadata)
+
+		t.Run("resolved", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/configs/burger-nutrition-de?resolved=true", "")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			var config dto.Config
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+			assert.Equal(t, dto.Metadata{"calories": "250", "allergens": map[string]any{"gluten": "yes", "nuts": "traces"}}, config.Metadata)
+
+			t.Run("changes along with its ancestors", func(t *testing.T) {
+				etag := rr.Header().Get("ETag")
+
+				rr := serve(http.MethodPut, "/configs/burger-nutrition/keys/allergens.milk", `"no"`)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+				rr = serve(http.MethodGet, "/configs/burger-nutrition-de?resolved=true", "")
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+				assert.Contains(t, rr.Body.String(), `"milk":"no"`)
+			})
+		})
+
+		t.Run("resolved with a revision", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/configs/burger-nutrition-de?resolved=true&revision=1", "")
+			assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		})
+	})
+
+	t.Run("list and search resolved", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/search?allergens.gluten=yes", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "1", rr.Header().Get("X-Total-Count"))
+
+		rr = serve(http.MethodGet, "/search?allergens.gluten=yes&resolved=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+
+		rr = serve(http.MethodGet, "/configs?resolved=true&limit=1&continue=", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"gluten":"yes"`)
+	})
+
+	t.Run("render resolved", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/burger-nutrition-de/render?format=properties&resolved=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "allergens.gluten=yes\nallergens.milk=no\nallergens.nuts=traces\ncalories=250\n", rr.Body.String())
+	})
+
+	t.Run("descendants", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/burger-nutrition/descendants", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var configs []dto.Config
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &configs))
+		require.Len(t, configs, 2)
+		assert.Equal(t, "burger-nutrition-de", configs[0].Name)
+		assert.Equal(t, "burger-nutrition-eu", configs[1].Name)
+
+		rr = serve(http.MethodGet, "/configs/missing/descendants", "")
+		assert.Equal(t, http.StatusNotFound, rr.Code, rr.Body.String())
+	})
+
+	t.Run("set parents", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/fries-nutrition/parents", `["burger-nutrition"]`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		config, err := repo.Get(domain.DefaultNamespace, "fries-nutrition")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"burger-nutrition"}, config.Parents)
+
+		t.Run("if match", func(t *testing.T) {
+			rr := serve(http.MethodPut, "/configs/fries-nutrition/parents", `[]`, "If-Match", fmt.Sprintf(`"%d"`, config.Revision-1))
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code, rr.Body.String())
+
+			rr = serve(http.MethodPut, "/configs/fries-nutrition/parents", `[]`, "If-Match", fmt.Sprintf(`"%d"`, config.Revision))
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			config, err := repo.Get(domain.DefaultNamespace, "fries-nutrition")
+			require.NoError(t, err)
+			assert.Empty(t, config.Parents)
+		})
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			method   string
+			target   string
+			body     string
+			expected int
+		}{
+			{name: "to create with a missing parent", method: http.MethodPost, target: "/configs", body: `{"name": "new", "metadata": {}, "parents": ["missing"]}`, expected: http.StatusBadRequest},
+			{name: "to create with a duplicated parent", method: http.MethodPost, target: "/configs", body: `{"name": "new", "metadata": {}, "parents": ["burger-nutrition", "burger-nutrition"]}`, expected: http.StatusBadRequest},
+			{name: "to make a cycle", method: http.MethodPut, target: "/configs/burger-nutrition/parents", body: `["burger-nutrition-de"]`, expected: http.StatusConflict},
+			{name: "to inherit from itself", method: http.MethodPut, target: "/configs/burger-nutrition/parents", body: `["burger-nutrition"]`, expected: http.StatusConflict},
+			{name: "to set a missing parent", method: http.MethodPut, target: "/configs/burger-nutrition/parents", body: `["missing"]`, expected: http.StatusBadRequest},
+			{name: "to set parents of a missing config", method: http.MethodPut, target: "/configs/missing/parents", body: `[]`, expected: http.StatusNotFound},
+			{name: "to set parents that aren't a list", method: http.MethodPut, target: "/configs/burger-nutrition/parents", body: `"burger-nutrition-eu"`, expected: http.StatusBadRequest},
+			{name: "to delete a config with children", method: http.MethodDelete, target: "/configs/burger-nutrition-eu", expected: http.StatusConflict},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(tt.method, tt.target, tt.body)
+				assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+			})
+		}
+
+		t.Run("to make a cycle in bulk", func(t *testing.T) {
+			rr := serve(http.MethodPost, "/configs:bulk", `[{"op": "upsert", "name": "burger-nutrition", "metadata": {}, "parents": ["burger-nutrition-eu"]}]`)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			var results []dto.BulkResult
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+			require.Len(t, results, 1)
+			assert.Equal(t, http.StatusConflict, results[0].Code)
+		})
+	})
+}
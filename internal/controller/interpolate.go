@@ -0,0 +1,5 @@
+package controller
+
+// interpolateParam is the query param serving configs with the references
+// in their metadata values replaced by the values they point at.
+const interpolateParam = "interpolate"
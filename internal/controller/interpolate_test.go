@@ -0,0 +1,120 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Interpolation(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	r := mux.NewRouter()
+	controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, body := range []string{
+		`{"name": "shared-infra", "metadata": {"db": {"host": "db.internal"}, "bucket": "assets"}}`,
+		`{"name": "payments", "metadata": {"db": {"url": "postgres://${shared-infra:db.host}/payments"}, "region": "${shared-infra:region:-eu}"}}`,
+	} {
+		rr := serve(http.MethodPost, "/configs", body)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	t.Run("get", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/payments", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"url":"postgres://${shared-infra:db.host}/payments"`, "values are raw by default")
+
+		rr = serve(http.MethodGet, "/configs/payments?interpolate=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var config dto.Config
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+		assert.Equal(t, dto.Metadata{"db": map[string]any{"url": "postgres://db.internal/payments"}, "region": "eu"}, config.Metadata)
+
+		t.Run("changes along with the referenced configs", func(t *testing.T) {
+			etag := rr.Header().Get("ETag")
+
+			rr := serve(http.MethodPut, "/configs/shared-infra/keys/db.host", `"db.example.com"`)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			rr = serve(http.MethodGet, "/configs/payments?interpolate=true", "")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+			assert.Contains(t, rr.Body.String(), `"url":"postgres://db.example.com/payments"`)
+		})
+
+		t.Run("with a revision", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/configs/payments?interpolate=true&revision=1", "")
+			assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		})
+	})
+
+	t.Run("list and search", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs?interpolate=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"url":"postgres://db.example.com/payments"`)
+
+		rr = serve(http.MethodGet, "/search?region=eu&interpolate=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "0", rr.Header().Get("X-Total-Count"), "searches match the raw values")
+
+		rr = serve(http.MethodGet, "/search?region[prefix]=$&interpolate=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "1", rr.Header().Get("X-Total-Count"))
+		assert.Contains(t, rr.Body.String(), `"region":"eu"`)
+	})
+
+	t.Run("render", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/payments/render?format=dotenv&interpolate=true", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "DB_URL=postgres://db.example.com/payments\nREGION=eu\n", rr.Body.String())
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "dangling", "metadata": {"host": "${shared-infra:nope}"}}`,
+			`{"name": "loop", "metadata": {"a": "${loop:b}", "b": "${loop:a}"}}`,
+		} {
+			rr := serve(http.MethodPost, "/configs", body)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		}
+
+		tests := []struct {
+			name   string
+			target string
+		}{
+			{name: "to get a missing reference", target: "/configs/dangling?interpolate=true"},
+			{name: "to get a cycle", target: "/configs/loop?interpolate=true"},
+			{name: "to render a cycle", target: "/configs/loop/render?format=dotenv&interpolate=true"},
+			{name: "to list a missing reference", target: "/configs?interpolate=true"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodGet, tt.target, "")
+				assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+			})
+		}
+
+		t.Run("but the raw values are still served", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/configs/loop", "")
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		})
+	})
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"net/http"
+)
+
+// @Summary Get a config key
+// @Description Gets the value of a metadata key of a config, which is the nested metadata under it when it holds other keys
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param path path string true "Metadata key, with a dot for each nest level, such as `aaa.bbb.ccc`"
+// @Param reveal query bool false "Reveal the secret metadata values, which are redacted otherwise, as long as the request bears the reveal token"
+// @Success 200 {object} object
+// @Header 200 {string} ETag "Entity tag of the config revision"
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/keys/{path} [get]
+// @Router /namespaces/{namespace}/configs/{name}/keys/{path} [get]
+func (c Config) getKey(w http.ResponseWriter, r *http.Request) {
+	namespace, name, key := namespaceOf(r), mux.Vars(r)["name"], mux.Vars(r)["path"]
+
+	if _, err := domain.SplitKey(key); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config, err := c.service.Get(namespace, name)
+	if err == nil {
+		config, err = c.presentSecrets(config, reveal)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	value, ok := config.LookupMetadataValue(key)
+	if !ok {
+		writeError(w, fmt.Errorf("%w: %q", domain.ErrKeyNotFound, key))
+		return
+	}
+
+	w.Header().Set("ETag", etag(config.Revision))
+	writeResponse(w, r, http.StatusOK, value)
+}
+
+// @Summary Set a config key
+// @Description Sets the value of a metadata key of a config, creating the parent keys it's nested in when they don't exist.
+// @Description The value is any JSON value, and elements of arrays are set by their index, as in `hosts.0`.
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param path path string true "Metadata key, with a dot for each nest level, such as `aaa.bbb.ccc`"
+// @Param value body object true "Value of the key"
+// @Param If-Match header string false "Only set the key if the config still matches the entity tag"
+// @Success 200
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 415 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/keys/{path} [put]
+// @Router /namespaces/{namespace}/configs/{name}/keys/{path} [put]
+func (c Config) setKey(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["path"]
+
+	var value any
+	if err := decodeBody(w, r, &value); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	c.patchKey(w, r, func(metadata []byte) ([]byte, error) {
+		return domain.SetMetadataValue(metadata, key, value)
+	})
+}
+
+// @Summary Delete a config key
+// @Description Deletes a metadata key of a config, along with the parent keys it leaves empty
+// @Tags config
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string false "Namespace of the config, the default namespace when omitted"
+// @Param name path string true "Name of the config"
+// @Param path path string true "Metadata key, with a dot for each nest level, such as `aaa.bbb.ccc`"
+// @Param If-Match header string false "Only delete the key if the config still matches the entity tag"
+// @Success 200
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 406 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /configs/{name}/keys/{path} [delete]
+// @Router /namespaces/{namespace}/configs/{name}/keys/{path} [delete]
+func (c Config) deleteKey(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["path"]
+
+	c.patchKey(w, r, func(metadata []byte) ([]byte, error) {
+		return domain.DeleteMetadataValue(metadata, key)
+	})
+}
+
+// patchKey atomically changes the metadata of the config in the path of r
+// with change, as long as the changed metadata is valid, and writes the response.
+func (c Config) patchKey(w http.ResponseWriter, r *http.Request, change repository.PatchFunc) {
+	namespace, name := namespaceOf(r), mux.Vars(r)["name"]
+
+	revision, _, err := c.ifMatch(r, namespace, name)
+	if err == nil {
+		err = c.service.Patch(namespace, name, revision, func(metadata []byte) ([]byte, error) {
+			changed, err := change(metadata)
+			if err != nil {
+				return nil, err
+			}
+			return changed, validateMetadata(changed)
+		})
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,223 @@
+package controller_test
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConfig_Keys(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	r := mux.NewRouter()
+	controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+
+	serve := func(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for n := 0; n < len(headers); n += 2 {
+			req.Header.Set(headers[n], headers[n+1])
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	metadataOf := func(t *testing.T) string {
+		config, err := repo.Get(domain.DefaultNamespace, "app")
+		require.NoError(t, err)
+		return string(config.Metadata)
+	}
+
+	_, err := repo.Save(domain.Config{
+		Namespace: domain.DefaultNamespace,
+		Name:      "app",
+		Metadata:  []byte(`{"flag": "off", "db": {"host": "db.internal", "pool": {"size": "10"}}}`),
+	})
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			path     string
+			expected string
+		}{
+			{name: "value", path: "flag", expected: `"off"`},
+			{name: "nested value", path: "db.pool.size", expected: `"10"`},
+			{name: "subtree", path: "db", expected: `{"host": "db.internal", "pool": {"size": "10"}}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodGet, "/configs/app/keys/"+tt.path, "")
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.NotEmpty(t, rr.Header().Get("ETag"))
+				assert.JSONEq(t, tt.expected, rr.Body.String())
+			})
+		}
+
+		t.Run("as YAML", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/configs/app/keys/db.pool", "", "Accept", "application/yaml")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Equal(t, "size: \"10\"\n", rr.Body.String())
+		})
+	})
+
+	t.Run("put", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			path     string
+			body     string
+			expected string
+		}{
+			{
+				name:     "replaces a value",
+				path:     "flag",
+				body:     `"on"`,
+				expected: `{"flag": "on", "db": {"host": "db.internal", "pool": {"size": "10"}}}`,
+			},
+			{
+				name:     "creates parent keys",
+				path:     "features.beta.enabled",
+				body:     `"true"`,
+				expected: `{"flag": "on", "db": {"host": "db.internal", "pool": {"size": "10"}}, "features": {"beta": {"enabled": "true"}}}`,
+			},
+			{
+				name:     "replaces a subtree",
+				path:     "db.pool",
+				body:     `{"size": "20", "idle": "5"}`,
+				expected: `{"flag": "on", "db": {"host": "db.internal", "pool": {"size": "20", "idle": "5"}}, "features": {"beta": {"enabled": "true"}}}`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodPut, "/configs/app/keys/"+tt.path, tt.body)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.JSONEq(t, tt.expected, metadataOf(t))
+			})
+		}
+
+		t.Run("as YAML", func(t *testing.T) {
+			rr := serve(http.MethodPut, "/configs/app/keys/db.host", "db.example.com\n", "Content-Type", "application/yaml")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Contains(t, metadataOf(t), `"host":"db.example.com"`)
+		})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rr := serve(http.MethodDelete, "/configs/app/keys/features.beta.enabled", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.JSONEq(t, `{"flag": "on", "db": {"host": "db.example.com", "pool": {"size": "20", "idle": "5"}}}`, metadataOf(t), "empty parents are pruned")
+
+		rr = serve(http.MethodDelete, "/configs/app/keys/db.pool.idle", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.JSONEq(t, `{"flag": "on", "db": {"host": "db.example.com", "pool": {"size": "20"}}}`, metadataOf(t))
+	})
+
+	t.Run("if match", func(t *testing.T) {
+		config, err := repo.Get(domain.DefaultNamespace, "app")
+		require.NoError(t, err)
+
+		rr := serve(http.MethodPut, "/configs/app/keys/flag", `"stale"`, "If-Match", fmt.Sprintf(`"%d"`, config.Revision-1))
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code, rr.Body.String())
+
+		rr = serve(http.MethodPut, "/configs/app/keys/flag", `"fresh"`, "If-Match", fmt.Sprintf(`"%d"`, config.Revision))
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, metadataOf(t), `"flag":"fresh"`)
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/app/keys/hosts", `["eu-1", "us-1"]`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		rr = serve(http.MethodPut, "/configs/app/keys/db.pool.size", `20`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = serve(http.MethodPut, "/configs/app/keys/hosts.1", `"ap-1"`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = serve(http.MethodGet, "/configs/app/keys/hosts.1", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.JSONEq(t, `"ap-1"`, rr.Body.String())
+
+		rr = serve(http.MethodDelete, "/configs/app/keys/hosts.0", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.JSONEq(t, `{"flag": "fresh", "db": {"host": "db.example.com", "pool": {"size": 20}}, "hosts": ["ap-1"]}`, metadataOf(t))
+	})
+
+	t.Run("large integers round-trip", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/app/keys/id", `9007199254740993`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		rr = serve(http.MethodPatch, "/configs/app", `{"other": 9007199254740995}`, "Content-Type", "application/merge-patch+json")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = serve(http.MethodGet, "/configs/app/keys/id", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "9007199254740993", strings.TrimSpace(rr.Body.String()))
+
+		rr = serve(http.MethodGet, "/configs/app", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"id":9007199254740993`)
+		assert.Contains(t, rr.Body.String(), `"other":9007199254740995`)
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			method   string
+			target   string
+			body     string
+			expected int
+		}{
+			{name: "to get a missing config", method: http.MethodGet, target: "/configs/missing/keys/flag", expected: http.StatusNotFound},
+			{name: "to get a missing key", method: http.MethodGet, target: "/configs/app/keys/nope", expected: http.StatusNotFound},
+			{name: "to get a key under a value", method: http.MethodGet, target: "/configs/app/keys/flag.nope", expected: http.StatusNotFound},
+			{name: "to get an empty key node", method: http.MethodGet, target: "/configs/app/keys/db..host", expected: http.StatusBadRequest},
+			{name: "to put on a missing config", method: http.MethodPut, target: "/configs/missing/keys/flag", body: `"on"`, expected: http.StatusNotFound},
+			{name: "to put under a value", method: http.MethodPut, target: "/configs/app/keys/flag.nope", body: `"on"`, expected: http.StatusConflict},
+			{name: "to put an empty key node", method: http.MethodPut, target: "/configs/app/keys/.flag", body: `"on"`, expected: http.StatusBadRequest},
+			{name: "to put past the end of an array", method: http.MethodPut, target: "/configs/app/keys/hosts.1", body: `"us-1"`, expected: http.StatusConflict},
+			{name: "to delete past the end of an array", method: http.MethodDelete, target: "/configs/app/keys/hosts.1", expected: http.StatusNotFound},
+			{name: "to put malformed JSON", method: http.MethodPut, target: "/configs/app/keys/flag", body: `"on`, expected: http.StatusBadRequest},
+			{name: "to delete a missing key", method: http.MethodDelete, target: "/configs/app/keys/nope", expected: http.StatusNotFound},
+			{name: "to delete on a missing config", method: http.MethodDelete, target: "/configs/missing/keys/flag", expected: http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				before := metadataOf(t)
+
+				rr := serve(tt.method, tt.target, tt.body)
+				assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+				assert.Equal(t, before, metadataOf(t))
+			})
+		}
+	})
+
+	t.Run("concurrent writes are atomic", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for n := 0; n < 50; n++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				rr := serve(http.MethodPut, fmt.Sprintf("/configs/app/keys/concurrent.key-%d", n), `"value"`)
+				assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			}(n)
+		}
+		wg.Wait()
+
+		config, err := repo.Get(domain.DefaultNamespace, "app")
+		require.NoError(t, err)
+		for n := 0; n < 50; n++ {
+			assert.Equal(t, "value", config.MetadataValue(fmt.Sprintf("concurrent.key-%d", n)))
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// JSONContentType is the media type of JSON content.
+	JSONContentType = "application/json"
+	// YAMLContentType is the media type of YAML content.
+	YAMLContentType = "application/yaml"
+)
+
+// negotiable are the media types content can be negotiated in, the preferred
+// one first, along with the aliases they're known by.
+var negotiable = []struct {
+	contentType string
+	aliases     []string
+}{
+	{JSONContentType, []string{JSONContentType}},
+	{YAMLContentType, []string{YAMLContentType, "application/x-yaml", "text/yaml", "text/x-yaml"}},
+}
+
+// contentTypeKey is the context key of the negotiated content type.
+type contentTypeKey struct{}
+
+// Negotiate serves the content in the media type the Accept header prefers
+// out of JSON and YAML, JSON when there's no preference. Requests accepting
+// neither of them get a 406 response.
+func Negotiate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contentType, ok := negotiate(r.Header.Values("Accept"))
+		if !ok {
+			WriteProblem(w, dto.NewProblem(http.StatusNotAcceptable, dto.CodeNotAcceptable, "only application/json and application/yaml are supported"))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		next(w, r.WithContext(context.WithValue(r.Context(), contentTypeKey{}, contentType)))
+	}
+}
+
+// ContentType gets the content type negotiated for r, which is JSON
+// when the request didn't go through Negotiate.
+func ContentType(r *http.Request) string {
+	if contentType, ok := r.Context().Value(contentTypeKey{}).(string); ok {
+		return contentType
+	}
+
+	return JSONContentType
+}
+
+// IsYAML tells if mediaType is YAML, under any of the names it's known by.
+func IsYAML(mediaType string) bool {
+	for _, alias := range negotiable[1].aliases {
+		if mediaType == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiate picks the negotiable content type with the highest quality
+// in the Accept header values, preferring JSON on a tie.
+func negotiate(accept []string) (string, bool) {
+	var ranges []string
+	for _, value := range accept {
+		ranges = append(ranges, strings.Split(value, ",")...)
+	}
+	if len(ranges) == 0 {
+		return JSONContentType, true
+	}
+
+	best, bestQuality := "", 0.0
+	for _, n := range negotiable {
+		// the quality of a content type is the one of the most
+		// specific media range it matches.
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+			if err != nil {
+				continue
+			}
+			s := matchRange(mediaRange, n.aliases)
+			if s <= specificity {
+				continue
+			}
+			specificity, quality = s, 1.0
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+				quality = q
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = n.contentType, quality
+		}
+	}
+
+	return best, best != ""
+}
+
+// matchRange tells how specifically mediaRange matches any of aliases:
+// 2 for the exact media type, 1 for its type with any subtype,
+// 0 for any media type, and -1 when it doesn't match at all.
+func matchRange(mediaRange string, aliases []string) int {
+	if mediaRange == "*/*" {
+		return 0
+	}
+
+	specificity := -1
+	for _, alias := range aliases {
+		if mediaRange == alias {
+			return 2
+		}
+		if t, _, _ := strings.Cut(alias, "/"); mediaRange == t+"/*" {
+			specificity = 1
+		}
+	}
+
+	return specificity
+}
@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(middleware.ContentType(r)))
+	}
+
+	tests := []struct {
+		name            string
+		accept          []string
+		wantCode        int
+		wantContentType string
+	}{
+		{
+			name:            "no preference",
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.JSONContentType,
+		},
+		{
+			name:            "any media type",
+			accept:          []string{"*/*"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.JSONContentType,
+		},
+		{
+			name:            "json",
+			accept:          []string{"application/json"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.JSONContentType,
+		},
+		{
+			name:            "yaml",
+			accept:          []string{"application/yaml"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.YAMLContentType,
+		},
+		{
+			name:            "yaml alias",
+			accept:          []string{"text/yaml"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.YAMLContentType,
+		},
+		{
+			name:            "highest quality wins",
+			accept:          []string{"application/json;q=0.5, application/yaml"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.YAMLContentType,
+		},
+		{
+			name:            "most specific range sets the quality",
+			accept:          []string{"application/*;q=0.9, application/json;q=0.1"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.YAMLContentType,
+		},
+		{
+			name:            "across several headers",
+			accept:          []string{"text/html", "text/yaml"},
+			wantCode:        http.StatusOK,
+			wantContentType: middleware.YAMLContentType,
+		},
+		{
+			name:     "not acceptable",
+			accept:   []string{"text/html"},
+			wantCode: http.StatusNotAcceptable,
+		},
+		{
+			name:     "explicitly refused",
+			accept:   []string{"application/json;q=0, application/yaml;q=0"},
+			wantCode: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, accept := range tt.accept {
+				req.Header.Add("Accept", accept)
+			}
+			rr := httptest.NewRecorder()
+
+			middleware.Negotiate(handler)(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantContentType, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusNotAcceptable {
+				assert.Equal(t, dto.ProblemContentType, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"log"
+	"net/http"
+)
+
+// WriteProblem writes problem as the response body, in the Problem Details
+// format, whatever content type was negotiated for the response.
+func WriteProblem(w http.ResponseWriter, problem dto.Problem) {
+	// drop whatever was set for the response that won't be written anymore.
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", dto.ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.Printf("Failed to write response: %s", err.Error())
+	}
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"net/http"
+)
+
+// NewNamespace creates a new Namespace controller instance.
+// It expects a service as a dependency.
+func NewNamespace(svc *service.Config) *Namespace {
+	return &Namespace{service: svc}
+}
+
+// Namespace is the namespace controller.
+// It defines routes and handlers for the namespace resources.
+type Namespace struct {
+	service *service.Config
+}
+
+// SetRouter returns the router r with all the necessary routes for the
+// Namespace controller setup.
+func (n Namespace) SetRouter(r *mux.Router) {
+	r.HandleFunc("/namespaces", middleware.SetJSONContent(n.list)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/namespaces", middleware.SetJSONContent(n.create)).
+		Methods(http.MethodPost)
+	r.HandleFunc("/namespaces/{namespace}", middleware.SetJSONContent(n.delete)).
+		Methods(http.MethodDelete)
+}
+
+// @Summary List namespaces
+// @Description Lists all available namespaces, sorted by name
+// @Tags namespace
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.Namespace
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /namespaces [get]
+func (n Namespace) list(w http.ResponseWriter, r *http.Request) {
+	namespaces, err := n.service.ListNamespaces()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := make([]dto.Namespace, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		response = append(response, dto.FromDomainNamespace(namespace))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// @Summary Create a new namespace
+// @Description Creates a new empty namespace
+// @Tags namespace
+// @Accept json
+// @Produce json
+// @Param namespace body dto.Namespace true "Namespace object to be created"
+// @Success 201
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /namespaces [post]
+func (n Namespace) create(w http.ResponseWriter, r *http.Request) {
+	var requestBody dto.Namespace
+	if err := json.NewDecoder(limitBody(w, r)).Decode(&requestBody); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := requestBody.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := n.service.CreateNamespace(requestBody.Name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// @Summary Delete a namespace
+// @Description Deletes an empty namespace. The default namespace can't be deleted
+// @Tags namespace
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace name"
+// @Success 200
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /namespaces/{namespace} [delete]
+func (n Namespace) delete(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["namespace"]
+
+	if err := n.service.DeleteNamespace(name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,152 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+	svc := service.NewConfig(repo)
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+	controller.NewNamespace(svc).SetRouter(r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("create namespace", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			body       string
+			wantStatus int
+		}{
+			{
+				name:       "creation is successful",
+				body:       `{"name": "team-a"}`,
+				wantStatus: http.StatusCreated,
+			},
+			{
+				name:       "namespace already exists",
+				body:       `{"name": "team-a"}`,
+				wantStatus: http.StatusConflict,
+			},
+			{
+				name:       "name isn't a DNS label",
+				body:       `{"name": "Team A"}`,
+				wantStatus: http.StatusBadRequest,
+			},
+			{
+				name:       "invalid request body",
+				body:       `{"name":`,
+				wantStatus: http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodPost, "/namespaces", tt.body)
+				assert.Equal(t, tt.wantStatus, rr.Code)
+			})
+		}
+	})
+
+	t.Run("list namespaces", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/namespaces", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var namespaces []dto.Namespace
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &namespaces))
+		assert.Len(t, namespaces, 2)
+	})
+
+	t.Run("configs are scoped to the namespace", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/namespaces/team-a/configs", `{"name": "config 1", "metadata": {"owner": "team-a"}}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		t.Run("config is found in its namespace", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/namespaces/team-a/configs/config%201", "")
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var config dto.Config
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+			assert.Equal(t, "team-a", config.Namespace)
+			assert.Equal(t, "team-a", config.Metadata["owner"])
+		})
+
+		t.Run("config isn't listed in the default namespace", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/search?owner=team-a", "")
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var configs []dto.Config
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &configs))
+			assert.Empty(t, configs)
+		})
+
+		t.Run("config is found searching across every namespace", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/search?owner=team-a&allNamespaces=true", "")
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var configs []dto.Config
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &configs))
+			assert.Len(t, configs, 1)
+		})
+
+		t.Run("missing namespace is not found", func(t *testing.T) {
+			rr := serve(http.MethodGet, "/namespaces/nope/configs", "")
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	})
+
+	t.Run("delete namespace", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			target     string
+			wantStatus int
+		}{
+			{
+				name:       "namespace isn't empty",
+				target:     "/namespaces/team-a",
+				wantStatus: http.StatusConflict,
+			},
+			{
+				name:       "default namespace",
+				target:     "/namespaces/default",
+				wantStatus: http.StatusConflict,
+			},
+			{
+				name:       "namespace not found",
+				target:     "/namespaces/nope",
+				wantStatus: http.StatusNotFound,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodDelete, tt.target, "")
+				assert.Equal(t, tt.wantStatus, rr.Code)
+			})
+		}
+
+		t.Run("deletion is successful", func(t *testing.T) {
+			require.Equal(t, http.StatusOK, serve(http.MethodDelete, "/namespaces/team-a/configs/config%201", "").Code)
+			assert.Equal(t, http.StatusOK, serve(http.MethodDelete, "/namespaces/team-a", "").Code)
+		})
+	})
+}
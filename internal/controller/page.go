@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// query params used to page through configs.
+const (
+	limitParam    = "limit"
+	continueParam = "continue"
+	// sortParam is the field configs are sorted by,
+	// prefixed with "-" to sort them in descending order.
+	sortParam = "sort"
+)
+
+// headers describing a page of configs.
+const (
+	totalCountHeader = "X-Total-Count"
+	continueHeader   = "X-Continue"
+)
+
+// errInvalidPagination is used when the pagination query params aren't valid.
+var errInvalidPagination = errors.New("invalid pagination")
+
+// listOptions gets the options to page through configs out of the query params
+// in values, removing them so that they're not taken for anything else.
+func listOptions(values url.Values) (repository.ListOptions, error) {
+	var opts repository.ListOptions
+
+	if limit := values.Get(limitParam); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("%w: limit must be a non-negative integer", errInvalidPagination)
+		}
+		opts.Limit = n
+	}
+
+	opts.Continue = values.Get(continueParam)
+
+	sortBy := values.Get(sortParam)
+	sortBy, opts.Descending = strings.CutPrefix(sortBy, "-")
+	opts.SortBy = repository.SortField(sortBy)
+
+	opts.Resolved, _ = strconv.ParseBool(values.Get(resolvedParam))
+	opts.Interpolate, _ = strconv.ParseBool(values.Get(interpolateParam))
+
+	values.Del(limitParam)
+	values.Del(continueParam)
+	values.Del(sortParam)
+	values.Del(resolvedParam)
+	values.Del(interpolateParam)
+	values.Del(revealParam)
+
+	return opts, nil
+}
+
+// writePage writes the configs in page as the response body, in the content type
+// negotiated for r, describing the page in the response headers.
+func writePage(w http.ResponseWriter, r *http.Request, page repository.Page) {
+	w.Header().Set(totalCountHeader, strconv.Itoa(page.Total))
+	if page.Continue != "" {
+		w.Header().Set(continueHeader, page.Continue)
+	}
+
+	writeConfigs(w, r, page.Configs)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// errPreconditionFailed is used when the If-Match header of a request
+// doesn't match the current version of the config.
+var errPreconditionFailed = errors.New("config has changed since it was read")
+
+// etag formats revision as the entity tag of a config.
+func etag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
+
+// listETag computes the entity tag of a list of configs, which changes
+// whenever any of them is created, updated or deleted.
+// It's weak because the order of the configs isn't taken into account.
+func listETag(configs []domain.Config) string {
+	var sum uint64
+	for _, c := range configs {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s\x00%d", c.Name, c.Revision)
+		// combine the hashes in a way that doesn't depend on the order.
+		sum ^= h.Sum64()
+	}
+
+	return fmt.Sprintf(`W/"%x"`, sum)
+}
+
+// ifMatch resolves the If-Match header of r into the revision the change
+// to the config identified by name in namespace is conditioned on.
+// It returns false when the change is unconditional, and errPreconditionFailed
+// when none of the entity tags can match the config. A wildcard matches
+// whatever revision the config is at, as long as it exists.
+func (c Config) ifMatch(r *http.Request, namespace, name string) (int64, bool, error) {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return 0, false, nil
+	}
+
+	var revisions []int64
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				return c.currentRevision(namespace, name)
+			}
+
+			// weak entity tags never match for If-Match, and neither do
+			// the ones that weren't issued by this API, so they're skipped.
+			if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+				continue
+			}
+			// configs start at revision 1, so the entity tags of revisions below
+			// never match either, rather than making the change unconditional.
+			revision, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+			if err != nil || revision < 1 {
+				continue
+			}
+			revisions = append(revisions, revision)
+		}
+	}
+
+	switch len(revisions) {
+	case 0:
+		return 0, false, errPreconditionFailed
+	case 1:
+		return revisions[0], true, nil
+	}
+
+	// with several candidates, the change is conditioned on the current
+	// revision, as long as it's one of them.
+	revision, _, err := c.currentRevision(namespace, name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !slices.Contains(revisions, revision) {
+		return 0, false, errPreconditionFailed
+	}
+
+	return revision, true, nil
+}
+
+// currentRevision gets the revision the config identified by name is at, for
+// a change to be conditioned on. It returns errPreconditionFailed when there's
+// no such config, as there's nothing an entity tag could match.
+func (c Config) currentRevision(namespace, name string) (int64, bool, error) {
+	config, err := c.service.Get(namespace, name)
+	if errors.Is(err, repository.ErrConfigNotFound) {
+		return 0, false, errPreconditionFailed
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return config.Revision, true, nil
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"log"
+	"net/http"
+)
+
+// problems are the status and the code each error is reported with,
+// where the first one err matches takes precedence.
+var problems = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{dto.ErrFailedValidation, http.StatusBadRequest, dto.CodeValidationFailed},
+	{errUnsupportedMediaType, http.StatusUnsupportedMediaType, dto.CodeUnsupportedMediaType},
+	{errPreconditionFailed, http.StatusPreconditionFailed, dto.CodePreconditionFailed},
+	{repository.ErrRevisionMismatch, http.StatusPreconditionFailed, dto.CodePreconditionFailed},
+	{errInvalidWatch, http.StatusBadRequest, dto.CodeInvalidRequest},
+	{errInvalidPagination, http.StatusBadRequest, dto.CodeInvalidPage},
+	{repository.ErrInvalidListOptions, http.StatusBadRequest, dto.CodeInvalidPage},
+	{repository.ErrInvalidContinue, http.StatusBadRequest, dto.CodeInvalidPage},
+	{query.ErrInvalidQuery, http.StatusBadRequest, dto.CodeInvalidQuery},
+	{repository.ErrInvalidOp, http.StatusBadRequest, dto.CodeInvalidOp},
+	{repository.ErrNamespaceNotFound, http.StatusNotFound, dto.CodeNamespaceNotFound},
+	{repository.ErrNamespaceExists, http.StatusConflict, dto.CodeNamespaceExists},
+	{repository.ErrNamespaceNotEmpty, http.StatusConflict, dto.CodeNamespaceNotEmpty},
+	{repository.ErrDefaultNamespace, http.StatusConflict, dto.CodeDefaultNamespace},
+	{repository.ErrConfigNotFound, http.StatusNotFound, dto.CodeConfigNotFound},
+	{repository.ErrConfigExists, http.StatusConflict, dto.CodeConfigExists},
+	{repository.ErrConfigHasChildren, http.StatusConflict, dto.CodeConfigHasChildren},
+	{repository.ErrRevisionNotFound, http.StatusNotFound, dto.CodeRevisionNotFound},
+	{domain.ErrParentNotFound, http.StatusBadRequest, dto.CodeParentNotFound},
+	{domain.ErrParentCycle, http.StatusConflict, dto.CodeParentCycle},
+	{domain.ErrKeyNotFound, http.StatusNotFound, dto.CodeKeyNotFound},
+	{domain.ErrKeyConflict, http.StatusConflict, dto.CodeKeyConflict},
+	{domain.ErrInvalidKey, http.StatusBadRequest, dto.CodeInvalidKey},
+	{domain.ErrInvalidPatch, http.StatusBadRequest, dto.CodeInvalidPatch},
+	{domain.ErrPatchTestFailed, http.StatusConflict, dto.CodePatchTestFailed},
+	{domain.ErrInvalidReference, http.StatusConflict, dto.CodeInvalidReference},
+	{domain.ErrReferenceNotFound, http.StatusConflict, dto.CodeReferenceNotFound},
+	{domain.ErrReferenceCycle, http.StatusConflict, dto.CodeReferenceCycle},
+	{errFlatKeyCollision, http.StatusConflict, dto.CodeFlatKeyCollision},
+	{repository.ErrSchemaNotFound, http.StatusNotFound, dto.CodeSchemaNotFound},
+	{domain.ErrInvalidSchema, http.StatusBadRequest, dto.CodeInvalidSchema},
+	{domain.ErrSchemaViolation, http.StatusBadRequest, dto.CodeSchemaViolation},
+	{repository.ErrWebhookNotFound, http.StatusNotFound, dto.CodeWebhookNotFound},
+	{errRevealForbidden, http.StatusForbidden, dto.CodeRevealForbidden},
+	{domain.ErrInvalidSecret, http.StatusBadRequest, dto.CodeInvalidSecret},
+	{domain.ErrSecretsDisabled, http.StatusBadRequest, dto.CodeSecretsDisabled},
+}
+
+// problemOf gets the status and the code err is reported with,
+// which are those of an internal error when it isn't expected.
+// Request bodies that are too large are reported with 413.
+func problemOf(err error) (int, string) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge, dto.CodePayloadTooLarge
+	}
+
+	for _, p := range problems {
+		if errors.Is(err, p.err) {
+			return p.status, p.code
+		}
+	}
+
+	return http.StatusInternalServerError, dto.CodeInternal
+}
+
+// internalDetail describes internal errors to clients, in place of
+// the errors themselves, which may tell about the internals of the service.
+const internalDetail = "something went wrong while handling the request"
+
+// detailOf gets the detail err is reported with, given its code. Internal
+// errors are logged instead of being reported as they are.
+func detailOf(err error, code string) string {
+	if code != dto.CodeInternal {
+		return err.Error()
+	}
+
+	log.Printf("Internal error: %s", err.Error())
+	return internalDetail
+}
+
+// writeError writes err as a Problem Details response, with the status and the
+// code problemOf gets for it, along with the fields failing validation, if any.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := problemOf(err)
+	problem := dto.NewProblem(status, code, detailOf(err, code))
+	problem.Errors = dto.FieldErrors(err)
+
+	middleware.WriteProblem(w, problem)
+}
+
+// writeProblem writes a Problem Details response with status and code, described by detail.
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	middleware.WriteProblem(w, dto.NewProblem(status, code, detail))
+}
@@ -0,0 +1,165 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository/mocks"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+	svc := service.NewConfig(repo)
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+
+	serve := func(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		header     http.Header
+		wantStatus int
+		wantCode   string
+		wantErrors []dto.FieldError
+	}{
+		{
+			name:       "config already exists",
+			method:     http.MethodPost,
+			target:     "/configs",
+			body:       `{"name": "` + test.ConfigName1 + `", "metadata": {"foo": "bar"}}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   dto.CodeConfigExists,
+		},
+		{
+			name:       "config not found",
+			method:     http.MethodGet,
+			target:     "/configs/nope",
+			wantStatus: http.StatusNotFound,
+			wantCode:   dto.CodeConfigNotFound,
+		},
+		{
+			name:       "failed validation",
+			method:     http.MethodPost,
+			target:     "/configs",
+			body:       `{"metadata": {"foo": "bar"}, "parents": [""]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   dto.CodeValidationFailed,
+			wantErrors: []dto.FieldError{
+				{Field: "name", Message: "name is required"},
+				{Field: "parents", Message: "parents can't be empty"},
+			},
+		},
+		{
+			name:       "malformed body",
+			method:     http.MethodPost,
+			target:     "/configs",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   dto.CodeInvalidRequest,
+		},
+		{
+			name:       "stale revision",
+			method:     http.MethodPut,
+			target:     "/configs/" + test.ConfigName1,
+			body:       `{"foo": "bar"}`,
+			header:     http.Header{"If-Match": {`"999"`}},
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   dto.CodePreconditionFailed,
+		},
+		{
+			name:       "unsupported media type",
+			method:     http.MethodPost,
+			target:     "/configs",
+			body:       `name=foo`,
+			header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   dto.CodeUnsupportedMediaType,
+		},
+		{
+			name:       "body too large",
+			method:     http.MethodPost,
+			target:     "/configs",
+			body:       `{"name": "big", "metadata": {"foo": "` + strings.Repeat("a", 8<<20) + `"}}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   dto.CodePayloadTooLarge,
+		},
+		{
+			name:       "patch too large",
+			method:     http.MethodPatch,
+			target:     "/configs/" + test.ConfigName1,
+			body:       `{"foo": "` + strings.Repeat("a", 8<<20) + `"}`,
+			header:     http.Header{"Content-Type": {"application/merge-patch+json"}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   dto.CodePayloadTooLarge,
+		},
+		{
+			name:       "transaction too large",
+			method:     http.MethodPost,
+			target:     "/txn",
+			body:       `{"success": [{"op": "create", "name": "big", "metadata": {"foo": "` + strings.Repeat("a", 8<<20) + `"}}]}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   dto.CodePayloadTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serve(tt.method, tt.target, tt.body, tt.header)
+			require.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			assert.Equal(t, dto.ProblemContentType, rr.Header().Get("Content-Type"))
+
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.Equal(t, "about:blank", problem.Type)
+			assert.Equal(t, http.StatusText(tt.wantStatus), problem.Title)
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.NotEmpty(t, problem.Detail)
+			assert.Equal(t, tt.wantErrors, problem.Errors)
+		})
+	}
+
+	t.Run("internal errors aren't described", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Get", domain.DefaultNamespace, test.ConfigName1).
+			Return(domain.Config{}, errors.New("open /var/lib/config-service/wal.log: permission denied"))
+
+		r := mux.NewRouter()
+		controller.NewConfig(service.NewConfig(mockRepo)).SetRouter(r)
+
+		req := httptest.NewRequest(http.MethodGet, "/configs/"+test.ConfigName1, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+
+		var problem dto.Problem
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+		assert.Equal(t, dto.CodeInternal, problem.Code)
+		assert.NotEmpty(t, problem.Detail)
+		assert.NotContains(t, problem.Detail, "wal.log")
+	})
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"net/http"
+)
+
+// NewSchema creates a new Schema controller instance.
+// It expects a service as a dependency.
+func NewSchema(svc *service.Schemas) *Schema {
+	return &Schema{service: svc}
+}
+
+// Schema is the schema controller.
+// It defines routes and handlers for the schema resources.
+type Schema struct {
+	service *service.Schemas
+}
+
+// SetRouter returns the router r with all the necessary routes for the
+// Schema controller setup.
+func (s Schema) SetRouter(r *mux.Router) {
+	r.HandleFunc("/schemas", middleware.SetJSONContent(s.list)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/schemas/{name}", middleware.SetJSONContent(s.get)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/schemas/{name}", middleware.SetJSONContent(s.put)).
+		Methods(http.MethodPut)
+	r.HandleFunc("/schemas/{name}", middleware.SetJSONContent(s.delete)).
+		Methods(http.MethodDelete)
+	r.HandleFunc("/schemas/{name}/violators", middleware.SetJSONContent(s.violators)).
+		Methods(http.MethodGet)
+}
+
+// @Summary List schemas
+// @Description Lists every schema, sorted by name
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.Schema
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /schemas [get]
+func (s Schema) list(w http.ResponseWriter, r *http.Request) {
+	schemas, err := s.service.List()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := make([]dto.Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		response = append(response, dto.FromDomainSchema(schema))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// @Summary Get a schema by name
+// @Description Gets a schema by its name
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the schema"
+// @Success 200 {object} dto.Schema
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /schemas/{name} [get]
+func (s Schema) get(w http.ResponseWriter, r *http.Request) {
+	schema, err := s.service.Get(mux.Vars(r)["name"])
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.FromDomainSchema(schema))
+}
+
+// @Summary Create or replace a schema by name
+// @Description Attaches a JSON Schema to the configs whose name matches the pattern, rejecting every change leaving
+// @Description their metadata violating it. Supports the type, required, enum, pattern, properties, additionalProperties,
+// @Description minLength and maxLength keywords of draft 2020-12, where patterns are RE2 regular expressions.
+// @Description Existing configs are re-validated, and the ones violating the schema are reported, but left as they are.
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the schema"
+// @Param schema body dto.Schema true "Schema object to be created or replacing the current one"
+// @Success 200 {object} dto.SchemaResult
+// @Success 201 {object} dto.SchemaResult
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /schemas/{name} [put]
+func (s Schema) put(w http.ResponseWriter, r *http.Request) {
+	var requestBody dto.Schema
+	if err := json.NewDecoder(limitBody(w, r)).Decode(&requestBody); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := requestBody.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	schema := requestBody.ToDomainSchema()
+	schema.Name = mux.Vars(r)["name"]
+
+	schema, created, err := s.service.Put(schema)
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	violators, err := s.service.Violators(schema.Name)
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, dto.SchemaResult{Schema: dto.FromDomainSchema(schema), Violators: toViolators(violators)})
+}
+
+// @Summary Delete a schema by name
+// @Description Deletes a schema by its name, detaching it from the configs
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the schema"
+// @Success 200
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /schemas/{name} [delete]
+func (s Schema) delete(w http.ResponseWriter, r *http.Request) {
+	if err := s.service.Delete(mux.Vars(r)["name"]); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary List the violators of a schema
+// @Description Re-validates the existing configs a schema is attached to, listing the ones violating it, sorted by name
+// @Tags schema
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the schema"
+// @Success 200 {array} dto.Violator
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /schemas/{name}/violators [get]
+func (s Schema) violators(w http.ResponseWriter, r *http.Request) {
+	violators, err := s.service.Violators(mux.Vars(r)["name"])
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toViolators(violators))
+}
+
+// toViolators converts violators into their data transfer objects.
+func toViolators(violators []service.Violator) []dto.Violator {
+	result := make([]dto.Violator, len(violators))
+	for n, violator := range violators {
+		violations := make([]dto.Violation, len(violator.Violations))
+		for i, violation := range violator.Violations {
+			violations[i] = dto.Violation{Path: violation.Path, Message: violation.Message}
+		}
+		result[n] = dto.Violator{
+			Namespace:  violator.Namespace,
+			Name:       violator.Name,
+			Revision:   violator.Revision,
+			Violations: violations,
+		}
+	}
+
+	return result
+}
+
+// writeSchemaError writes the error response of err.
+func writeSchemaError(w http.ResponseWriter, err error) {
+	writeError(w, err)
+}
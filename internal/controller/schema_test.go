@@ -0,0 +1,162 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	schemas := service.NewSchemas(repository.NewInMemorySchema(), repo, nil)
+	svc := service.NewConfig(repo, service.WithSchemas(schemas))
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+	controller.NewSchema(schemas).SetRouter(r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, "/configs", `{"name": "flags-web", "metadata": {"enabeld": "true"}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	definition := `{"type": "object", "required": ["enabled"], "properties": {"enabled": {"enum": ["true", "false"]}}, "additionalProperties": false}`
+
+	t.Run("put schema", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/schemas/flags", `{"pattern": "flags-*", "definition": `+definition+`}`)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		var result dto.SchemaResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "flags", result.Schema.Name)
+		assert.JSONEq(t, definition, string(result.Schema.Definition))
+
+		t.Run("reports the violators", func(t *testing.T) {
+			assert.Equal(t, []dto.Violator{{
+				Namespace: "default",
+				Name:      "flags-web",
+				Revision:  1,
+				Violations: []dto.Violation{
+					{Path: "enabled", Message: "is required"},
+					{Path: "enabeld", Message: "isn't allowed"},
+				},
+			}}, result.Violators)
+
+			rr := serve(http.MethodGet, "/schemas/flags/violators", "")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Contains(t, rr.Body.String(), `"name":"flags-web"`)
+		})
+
+		t.Run("replaces it", func(t *testing.T) {
+			rr := serve(http.MethodPut, "/schemas/flags", `{"pattern": "flags-*", "definition": {}}`)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Contains(t, rr.Body.String(), `"violators":[]`)
+
+			rr = serve(http.MethodPut, "/schemas/flags", `{"pattern": "flags-*", "definition": `+definition+`}`)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		})
+	})
+
+	t.Run("get and list", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/schemas/flags", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"pattern":"flags-*"`)
+
+		rr = serve(http.MethodGet, "/schemas", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var list []dto.Schema
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Len(t, list, 1)
+	})
+
+	t.Run("enforce schema", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			method   string
+			target   string
+			body     string
+			expected int
+		}{
+			{name: "create a valid config", method: http.MethodPost, target: "/configs", body: `{"name": "flags-app", "metadata": {"enabled": "true"}}`, expected: http.StatusCreated},
+			{name: "create a config with a typo", method: http.MethodPost, target: "/configs", body: `{"name": "flags-api", "metadata": {"enabeld": "true"}}`, expected: http.StatusBadRequest},
+			{name: "update a config with a typo", method: http.MethodPut, target: "/configs/flags-app", body: `{"enabled": "true", "enabeld": "true"}`, expected: http.StatusBadRequest},
+			{name: "patch a config out of the enum", method: http.MethodPatch, target: "/configs/flags-app", body: `{"enabled": "yes"}`, expected: http.StatusBadRequest},
+			{name: "set a key that isn't allowed", method: http.MethodPut, target: "/configs/flags-app/keys/owner", body: `"ops"`, expected: http.StatusBadRequest},
+			{name: "create a config the schema isn't attached to", method: http.MethodPost, target: "/configs", body: `{"name": "other", "metadata": {"enabeld": "true"}}`, expected: http.StatusCreated},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(tt.method, tt.target, tt.body)
+				assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+			})
+		}
+
+		t.Run("with path-accurate messages", func(t *testing.T) {
+			rr := serve(http.MethodPost, "/configs", `{"name": "flags-api", "metadata": {"enabeld": "true"}}`)
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.Equal(t, dto.CodeSchemaViolation, problem.Code)
+			assert.Equal(t, "metadata violates schema \"flags\": enabled is required, enabeld isn't allowed", problem.Detail)
+		})
+
+		t.Run("in bulk", func(t *testing.T) {
+			rr := serve(http.MethodPost, "/configs:bulk", `[{"op": "upsert", "name": "flags-app", "metadata": {"enabled": "maybe"}}]`)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			var results []dto.BulkResult
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+			require.Len(t, results, 1)
+			assert.Equal(t, http.StatusBadRequest, results[0].Code)
+		})
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			method   string
+			target   string
+			body     string
+			expected int
+		}{
+			{name: "to put a schema without a pattern", method: http.MethodPut, target: "/schemas/bad", body: `{"definition": {}}`, expected: http.StatusBadRequest},
+			{name: "to put a schema without a definition", method: http.MethodPut, target: "/schemas/bad", body: `{"pattern": "*"}`, expected: http.StatusBadRequest},
+			{name: "to put a schema with an unsupported keyword", method: http.MethodPut, target: "/schemas/bad", body: `{"pattern": "*", "definition": {"minimum": 1}}`, expected: http.StatusBadRequest},
+			{name: "to put a schema with a malformed pattern", method: http.MethodPut, target: "/schemas/bad", body: `{"pattern": "[", "definition": {}}`, expected: http.StatusBadRequest},
+			{name: "to put malformed JSON", method: http.MethodPut, target: "/schemas/bad", body: `{`, expected: http.StatusBadRequest},
+			{name: "to get a missing schema", method: http.MethodGet, target: "/schemas/missing", expected: http.StatusNotFound},
+			{name: "to get the violators of a missing schema", method: http.MethodGet, target: "/schemas/missing/violators", expected: http.StatusNotFound},
+			{name: "to delete a missing schema", method: http.MethodDelete, target: "/schemas/missing", expected: http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(tt.method, tt.target, tt.body)
+				assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+			})
+		}
+	})
+
+	t.Run("delete schema", func(t *testing.T) {
+		rr := serve(http.MethodDelete, "/schemas/flags", "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = serve(http.MethodPost, "/configs", `{"name": "flags-api", "metadata": {"enabeld": "true"}}`)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	})
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// revealParam is the query param serving configs with their secret metadata
+// values revealed, which are redacted otherwise.
+const revealParam = "reveal"
+
+// errRevealForbidden is used when secrets are asked to be revealed by
+// a request that doesn't bear the token allowing it.
+var errRevealForbidden = errors.New("revealing secrets isn't allowed")
+
+// revealing tells if r asks for the secrets of the configs it gets to be revealed,
+// failing with errRevealForbidden when it does without bearing the reveal token.
+func (c Config) revealing(r *http.Request) (bool, error) {
+	if reveal, _ := strconv.ParseBool(r.URL.Query().Get(revealParam)); !reveal {
+		return false, nil
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || c.revealToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.revealToken)) != 1 {
+		return false, errRevealForbidden
+	}
+
+	return true, nil
+}
+
+// revealAll gets configs with their secret metadata values revealed.
+func (c Config) revealAll(configs []domain.Config) ([]domain.Config, error) {
+	revealed := make([]domain.Config, len(configs))
+	for n, config := range configs {
+		var err error
+		if revealed[n], err = c.service.Reveal(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return revealed, nil
+}
+
+// presentSecrets gets config with its secret metadata values revealed when
+// reveal is set, or redacted otherwise, for the responses that don't convert
+// it with dto.FromDomainConfig.
+func (c Config) presentSecrets(config domain.Config, reveal bool) (domain.Config, error) {
+	if reveal {
+		return c.service.Reveal(config)
+	}
+
+	metadata, err := domain.RedactSecrets(config.Metadata)
+	if err != nil {
+		return domain.Config{}, err
+	}
+	config.Metadata = metadata
+
+	return config, nil
+}
@@ -0,0 +1,161 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecrets(t *testing.T) {
+	secrets, err := service.NewSecrets([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	repo := repository.NewInMemoryConfig()
+	svc := service.NewConfig(repo, service.WithSecrets(secrets))
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc, controller.WithRevealToken("t0k3n")).SetRouter(r)
+
+	serve := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, "/configs", "", `{"name": "payments", "metadata": {"apiKey": "secret:abc123", "region": "eu"}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	stored, err := repo.Get(domain.DefaultNamespace, "payments")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Metadata), "abc123")
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		for _, target := range []string{
+			"/configs/payments",
+			"/configs",
+			"/search?region=eu",
+			"/configs/payments/revisions",
+			"/configs/payments/keys/apiKey",
+			"/configs/payments/render?format=dotenv",
+		} {
+			rr := serve(http.MethodGet, target, "", "")
+			require.Equal(t, http.StatusOK, rr.Code, target)
+			assert.NotContains(t, rr.Body.String(), "abc123", target)
+			assert.NotContains(t, rr.Body.String(), domain.EncryptedPrefix, target)
+			assert.Contains(t, rr.Body.String(), domain.RedactedSecret, target)
+		}
+	})
+
+	t.Run("secrets are revealed to the requests bearing the token", func(t *testing.T) {
+		for _, target := range []string{
+			"/configs/payments?reveal=true",
+			"/configs?reveal=true",
+			"/search?region=eu&reveal=true",
+			"/configs/payments/keys/apiKey?reveal=true",
+			"/configs/payments/render?format=dotenv&reveal=true",
+			"/export?reveal=true",
+		} {
+			rr := serve(http.MethodGet, target, "t0k3n", "")
+			require.Equal(t, http.StatusOK, rr.Code, target)
+			assert.Contains(t, rr.Body.String(), "secret:abc123", target)
+		}
+	})
+
+	t.Run("secrets are exported encrypted", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/export", "", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "abc123")
+		assert.Contains(t, rr.Body.String(), domain.EncryptedPrefix)
+		exported := rr.Body.String()
+
+		// importInto imports the export into a new service encrypting secrets with key.
+		importInto := func(t *testing.T, key string) (*httptest.ResponseRecorder, repository.Config) {
+			secrets, err := service.NewSecrets([]byte(key))
+			require.NoError(t, err)
+			target := repository.NewInMemoryConfig()
+			targetSvc := service.NewConfig(target, service.WithSecrets(secrets))
+
+			r := mux.NewRouter()
+			controller.NewConfig(targetSvc).SetRouter(r)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(exported)))
+			return rr, target
+		}
+
+		t.Run("and imported back with the same key", func(t *testing.T) {
+			rr, target := importInto(t, "0123456789abcdef0123456789abcdef")
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			imported, err := target.Get(domain.DefaultNamespace, "payments")
+			require.NoError(t, err)
+			revealed, err := secrets.Reveal(imported.Metadata)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"apiKey": "secret:abc123", "region": "eu"}`, string(revealed))
+		})
+
+		t.Run("but not with another key", func(t *testing.T) {
+			rr, target := importInto(t, "fedcba9876543210fedcba9876543210")
+			require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.Equal(t, dto.CodeInvalidSecret, problem.Code)
+
+			_, err := target.Get(domain.DefaultNamespace, "payments")
+			assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+		})
+	})
+
+	t.Run("revealing secrets without the token is forbidden", func(t *testing.T) {
+		for _, token := range []string{"", "nope"} {
+			rr := serve(http.MethodGet, "/configs/payments?reveal=true", token, "")
+			require.Equal(t, http.StatusForbidden, rr.Code)
+
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.Equal(t, dto.CodeRevealForbidden, problem.Code)
+			assert.NotContains(t, rr.Body.String(), "abc123")
+		}
+	})
+
+	t.Run("secrets aren't searchable", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/search?apiKey=abc123", "", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var configs []dto.Config
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &configs))
+		assert.Empty(t, configs)
+	})
+
+	t.Run("redacted secrets can't be written back", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/payments", "", `{"apiKey": "secret:******", "region": "eu"}`)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		var problem dto.Problem
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+		assert.Equal(t, dto.CodeInvalidSecret, problem.Code)
+	})
+
+	t.Run("keys are set as secrets", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/payments/keys/webhookKey", "", `"secret:def456"`)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		stored, err := repo.Get(domain.DefaultNamespace, "payments")
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored.Metadata), "def456")
+	})
+}
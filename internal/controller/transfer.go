@@ -0,0 +1,433 @@
+package controller
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// formatParam is the query param picking the format of an export or a flattened config.
+	formatParam = "format"
+	// strategyParam is the query param picking what an import does with the configs that exist already.
+	strategyParam = "strategy"
+	// dryRunParam is the query param making an import only report what it would change.
+	dryRunParam = "dryRun"
+)
+
+const (
+	// formatNDJSON exports a config per line, as newline delimited JSON.
+	formatNDJSON = "ndjson"
+	// formatArchive exports a gzipped tar archive holding the configs
+	// as newline delimited JSON, along with a manifest and their checksum.
+	formatArchive = "archive"
+
+	// manifestFileName is the name of the manifest in an export archive.
+	manifestFileName = "manifest.json"
+	// configsFileName is the name of the configs in an export archive.
+	configsFileName = "configs.ndjson"
+	// archiveVersion is the version of the export archive format.
+	archiveVersion = 1
+
+	// maxImportSize is the maximum size in bytes of the configs in an import,
+	// whether they're archived or not.
+	maxImportSize = 64 << 20
+)
+
+// errInvalidArchive is used when an imported archive can't be read, or doesn't match its manifest.
+var errInvalidArchive = errors.New("invalid archive")
+
+// @Summary Export every config
+// @Description Streams every config across every namespace, as a config per line of newline delimited JSON,
+// @Description or as a gzipped tar archive holding them in configs.ndjson, along with a manifest.json
+// @Description listing their number and SHA-256 checksum. Secret metadata values are exported encrypted,
+// @Description so that they're imported back as they are by the service holding the same key
+// @Tags config
+// @Produce application/x-ndjson,application/gzip
+// @Param format query string false "Format of the export, one of ndjson and archive" default(ndjson)
+// @Param reveal query bool false "Reveal the secret metadata values, which are encrypted otherwise, as long as the request bears the reveal token"
+// @Success 200 {array} dto.Config
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 403 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /export [get]
+func (c Config) exportConfigs(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get(formatParam)
+	if format == "" {
+		format = formatNDJSON
+	}
+	if format != formatNDJSON && format != formatArchive {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, fmt.Sprintf("format %q must be one of ndjson and archive", format))
+		return
+	}
+
+	reveal, err := c.revealing(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	exported, err := c.service.Export()
+	if err == nil && reveal {
+		exported, err = c.revealAll(exported)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	configs := make([]dto.Config, 0, len(exported))
+	for _, config := range exported {
+		dtoConfig, err := dto.FromStoredConfig(config)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		configs = append(configs, dtoConfig)
+	}
+
+	if format == formatNDJSON {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(w)
+		for _, config := range configs {
+			if err := encoder.Encode(config); err != nil {
+				log.Printf("Failed to write export: %s", err.Error())
+				return
+			}
+		}
+		return
+	}
+
+	exportedAt := time.Now().UTC()
+	archive, err := writeArchive(configs, exportedAt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="configs-%s.tar.gz"`, exportedAt.Format("20060102T150405Z")))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(archive); err != nil {
+		log.Printf("Failed to write export: %s", err.Error())
+	}
+}
+
+// @Summary Import configs
+// @Description Imports configs as exported, whether as newline delimited JSON or as an archive, whose
+// @Description checksums are verified. Every line is validated like a config being created, and any invalid
+// @Description line fails the import. strategy settles what's done with the configs that exist already:
+// @Description skip leaves them as they are, overwrite replaces their metadata, and fail fails the import.
+// @Description Either every config is imported or none is, and the report tells what's done with each line
+// @Tags config
+// @Accept application/x-ndjson,application/gzip
+// @Produce json
+// @Param strategy query string false "What's done with the configs that exist already, one of skip, overwrite and fail" default(fail)
+// @Param dryRun query bool false "Only report what would change"
+// @Param configs body string true "Configs to import"
+// @Success 200 {object} dto.ImportReport
+// @Failure 400 {object} dto.ImportReport
+// @Failure 409 {object} dto.ImportReport
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /import [post]
+func (c Config) importConfigs(w http.ResponseWriter, r *http.Request) {
+	urlQuery := r.URL.Query()
+
+	strategy := service.ImportStrategy(urlQuery.Get(strategyParam))
+	switch strategy {
+	case "":
+		strategy = service.ImportFail
+	case service.ImportSkip, service.ImportOverwrite, service.ImportFail:
+	default:
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, fmt.Sprintf("strategy %q must be one of skip, overwrite and fail", strategy))
+		return
+	}
+
+	dryRun := false
+	if value := urlQuery.Get(dryRunParam); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, "dryRun must be a boolean")
+			return
+		}
+	}
+
+	body, err := readImport(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	report := dto.ImportReport{DryRun: dryRun, Strategy: string(strategy), Items: []dto.ImportItem{}}
+	var configs []domain.Config
+	// positions keeps the item of every config in the report.
+	var positions []int
+	seen := make(map[configKey]int)
+
+	reader := bufio.NewReader(body)
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			writeDecodeError(w, err)
+			return
+		}
+
+		if len(bytes.TrimSpace(raw)) > 0 {
+			item := dto.ImportItem{Line: line}
+			config, parseErr := parseImportLine(raw)
+			item.Namespace, item.Name = config.Namespace, config.Name
+
+			key := configKey{config.Namespace, config.Name}
+			if first, ok := seen[key]; ok && parseErr == nil {
+				parseErr = fmt.Errorf("config is already on line %d", first)
+			}
+
+			if parseErr != nil {
+				item.Action, item.Error = "invalid", parseErr.Error()
+				report.Invalid++
+			} else {
+				seen[key] = line
+				configs = append(configs, config)
+				positions = append(positions, len(report.Items))
+			}
+			report.Items = append(report.Items, item)
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	// nothing is imported along with invalid lines, but what would be
+	// done with the valid ones is still reported.
+	if report.Invalid > 0 {
+		report.DryRun = true
+	}
+
+	actions, err := c.service.Import(configs, strategy, report.DryRun)
+	if err != nil && !errors.Is(err, repository.ErrConfigExists) {
+		writeError(w, err)
+		return
+	}
+
+	for n, action := range actions {
+		report.Items[positions[n]].Action = string(action)
+		switch action {
+		case service.ImportCreated:
+			report.Created++
+		case service.ImportOverwritten:
+			report.Overwritten++
+		case service.ImportSkipped:
+			report.Skipped++
+		case service.ImportConflicted:
+			report.Conflicts++
+		}
+	}
+
+	switch {
+	case report.Invalid > 0:
+		writeJSON(w, http.StatusBadRequest, report)
+	case err != nil:
+		report.DryRun = true
+		writeJSON(w, http.StatusConflict, report)
+	default:
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// configKey identifies a config within an import.
+type configKey struct {
+	namespace string
+	name      string
+}
+
+// parseImportLine parses and validates a line of the imported configs.
+// It returns whatever is known about the config even when it's invalid,
+// so that it can be reported.
+func parseImportLine(line []byte) (domain.Config, error) {
+	var dtoConfig dto.Config
+	if err := domain.DecodeJSON(line, &dtoConfig); err != nil {
+		return domain.Config{}, err
+	}
+
+	namespace := dtoConfig.Namespace
+	if namespace == "" {
+		namespace = domain.DefaultNamespace
+	}
+	known := domain.Config{Namespace: namespace, Name: dtoConfig.Name}
+
+	if err := dtoConfig.Validate(); err != nil {
+		return known, err
+	}
+
+	config, err := dtoConfig.ToDomainConfig()
+	if err != nil {
+		return known, err
+	}
+	config.Namespace = namespace
+
+	return config, nil
+}
+
+// writeArchive writes configs into a gzipped tar archive as newline delimited JSON,
+// along with a manifest describing them.
+func writeArchive(configs []dto.Config, exportedAt time.Time) ([]byte, error) {
+	var ndjson bytes.Buffer
+	encoder := json.NewEncoder(&ndjson)
+	for _, config := range configs {
+		if err := encoder.Encode(config); err != nil {
+			return nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+	}
+
+	checksum := sha256.Sum256(ndjson.Bytes())
+	manifest, err := json.Marshal(dto.ExportManifest{
+		Version:    archiveVersion,
+		ExportedAt: exportedAt,
+		Configs:    len(configs),
+		Files: []dto.ManifestFile{{
+			Name:   configsFileName,
+			Size:   int64(ndjson.Len()),
+			SHA256: hex.EncodeToString(checksum[:]),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{manifestFileName, manifest},
+		{configsFileName, ndjson.Bytes()},
+	} {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0o644,
+			Size:    int64(len(file.content)),
+			ModTime: exportedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(file.content); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return archive.Bytes(), nil
+}
+
+// readImport gets the imported configs out of body as newline delimited JSON,
+// which is either the body itself or, when it's gzipped, the configs in the archive.
+func readImport(body io.Reader) (io.Reader, error) {
+	reader := bufio.NewReader(body)
+	magic, _ := reader.Peek(2)
+	if !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return reader, nil
+	}
+
+	return readArchive(reader)
+}
+
+// readArchive gets the configs out of an export archive, making sure
+// they match the checksum in its manifest. The archive is held to the same
+// limit as the configs in it once decompressed, and the files it isn't
+// expected to hold are skipped, without being kept.
+func readArchive(archive io.Reader) (io.Reader, error) {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidArchive, err.Error())
+	}
+	defer gz.Close()
+
+	// there's no response to tell about the limit here,
+	// it's reported along with the error it's read with.
+	decompressed := http.MaxBytesReader(nil, gz, maxImportSize)
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(decompressed)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, archiveError(err)
+		}
+		if header.Name != manifestFileName && header.Name != configsFileName {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, archiveError(err)
+		}
+		files[header.Name] = content
+	}
+
+	var manifest dto.ExportManifest
+	if err := json.Unmarshal(files[manifestFileName], &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s is missing or malformed", errInvalidArchive, manifestFileName)
+	}
+	if manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", errInvalidArchive, manifest.Version)
+	}
+
+	listed := false
+	for _, file := range manifest.Files {
+		content, ok := files[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", errInvalidArchive, file.Name)
+		}
+		checksum := sha256.Sum256(content)
+		if int64(len(content)) != file.Size || hex.EncodeToString(checksum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("%w: %s doesn't match its checksum", errInvalidArchive, file.Name)
+		}
+		listed = listed || file.Name == configsFileName
+	}
+	if !listed {
+		return nil, fmt.Errorf("%w: %s isn't in the manifest", errInvalidArchive, configsFileName)
+	}
+
+	return bytes.NewReader(files[configsFileName]), nil
+}
+
+// archiveError gets the error an archive is rejected with, given the err
+// it's read with, which is kept as it is when the archive is too large.
+func archiveError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", errInvalidArchive, err.Error())
+}
@@ -0,0 +1,300 @@
+package controller_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ExportImport(t *testing.T) {
+	newRouter := func(t *testing.T, repo repository.Config) *mux.Router {
+		r := mux.NewRouter()
+		controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+		return r
+	}
+
+	source := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+	require.NoError(t, source.CreateNamespace("team-a"))
+	_, err := source.Save(domain.Config{Namespace: "team-a", Name: "scoped", Metadata: []byte(`{"foo":"bar"}`)})
+	require.NoError(t, err)
+	sourceRouter := newRouter(t, source)
+
+	export := func(t *testing.T, format string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/export?format="+format, nil)
+		rr := httptest.NewRecorder()
+		sourceRouter.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		return rr
+	}
+
+	importInto := func(t *testing.T, r *mux.Router, query string, body []byte) (int, dto.ImportReport) {
+		req := httptest.NewRequest(http.MethodPost, "/import"+query, bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var report dto.ImportReport
+		if rr.Header().Get("Content-Type") == "application/json" {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report), rr.Body.String())
+		}
+		return rr.Code, report
+	}
+
+	// assertCloned checks that the configs in source are all found in target.
+	assertCloned := func(t *testing.T, target repository.Config) {
+		want, err := source.Search(repository.AllNamespaces, nil, repository.ListOptions{})
+		require.NoError(t, err)
+		got, err := target.Search(repository.AllNamespaces, nil, repository.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, got.Configs, len(want.Configs))
+
+		for _, config := range want.Configs {
+			cloned, err := target.Get(config.Namespace, config.Name)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(config.Metadata), string(cloned.Metadata))
+		}
+	}
+
+	t.Run("ndjson export", func(t *testing.T) {
+		rr := export(t, "")
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 4)
+		for _, line := range lines {
+			var config dto.Config
+			require.NoError(t, json.Unmarshal([]byte(line), &config))
+			assert.NotEmpty(t, config.Namespace)
+		}
+
+		t.Run("is imported into an empty store", func(t *testing.T) {
+			target := repository.NewInMemoryConfig()
+			code, report := importInto(t, newRouter(t, target), "", rr.Body.Bytes())
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, 4, report.Created)
+			assertCloned(t, target)
+		})
+	})
+
+	t.Run("archive export", func(t *testing.T) {
+		rr := export(t, "archive")
+		assert.Equal(t, "application/gzip", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), ".tar.gz")
+
+		files := readTestArchive(t, rr.Body.Bytes())
+		var manifest dto.ExportManifest
+		require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+		assert.Equal(t, 4, manifest.Configs)
+		require.Len(t, manifest.Files, 1)
+		assert.Equal(t, "configs.ndjson", manifest.Files[0].Name)
+
+		t.Run("is imported into an empty store", func(t *testing.T) {
+			target := repository.NewInMemoryConfig()
+			code, report := importInto(t, newRouter(t, target), "", rr.Body.Bytes())
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, 4, report.Created)
+			assertCloned(t, target)
+		})
+
+		t.Run("a tampered archive is rejected", func(t *testing.T) {
+			configs := bytes.Replace(files["configs.ndjson"], []byte(`"bar"`), []byte(`"baz"`), 1)
+			tampered := writeTestArchive(t, map[string][]byte{"manifest.json": files["manifest.json"], "configs.ndjson": configs})
+
+			target := repository.NewInMemoryConfig()
+			code, _ := importInto(t, newRouter(t, target), "", tampered)
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+
+		t.Run("an archive without a manifest is rejected", func(t *testing.T) {
+			archive := writeTestArchive(t, map[string][]byte{"configs.ndjson": files["configs.ndjson"]})
+
+			code, _ := importInto(t, newRouter(t, repository.NewInMemoryConfig()), "", archive)
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+
+		t.Run("files the archive isn't expected to hold are skipped", func(t *testing.T) {
+			archive := writeTestArchive(t, map[string][]byte{
+				"manifest.json":  files["manifest.json"],
+				"configs.ndjson": files["configs.ndjson"],
+				"README":         []byte("not a config"),
+			})
+
+			target := repository.NewInMemoryConfig()
+			code, report := importInto(t, newRouter(t, target), "", archive)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, 4, report.Created)
+		})
+
+		t.Run("an archive decompressing past the limit is rejected", func(t *testing.T) {
+			// every entry is within the limit, but not all of them together.
+			junk := make([]byte, 24<<20)
+			archive := writeTestArchive(t, map[string][]byte{"junk-1": junk, "junk-2": junk, "junk-3": junk})
+			require.Less(t, len(archive), 1<<20)
+
+			code, _ := importInto(t, newRouter(t, repository.NewInMemoryConfig()), "", archive)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		})
+	})
+
+	t.Run("strategies", func(t *testing.T) {
+		body := []byte(`{"name": "config1", "metadata": {"foo": "imported"}}
+{"name": "new", "metadata": {"foo": "imported"}}
+`)
+
+		tests := []struct {
+			name         string
+			query        string
+			wantCode     int
+			wantActions  []string
+			wantMetadata string
+			wantNew      bool
+		}{
+			{
+				name:         "fail by default",
+				wantCode:     http.StatusConflict,
+				wantActions:  []string{"conflict", "create"},
+				wantMetadata: "bar",
+			},
+			{
+				name:         "skip",
+				query:        "?strategy=skip",
+				wantCode:     http.StatusOK,
+				wantActions:  []string{"skip", "create"},
+				wantMetadata: "bar",
+				wantNew:      true,
+			},
+			{
+				name:         "overwrite",
+				query:        "?strategy=overwrite",
+				wantCode:     http.StatusOK,
+				wantActions:  []string{"overwrite", "create"},
+				wantMetadata: "imported",
+				wantNew:      true,
+			},
+			{
+				name:         "dry run",
+				query:        "?strategy=overwrite&dryRun=true",
+				wantCode:     http.StatusOK,
+				wantActions:  []string{"overwrite", "create"},
+				wantMetadata: "bar",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				target := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+
+				code, report := importInto(t, newRouter(t, target), tt.query, body)
+				require.Equal(t, tt.wantCode, code)
+				require.Len(t, report.Items, len(tt.wantActions))
+				for n, action := range tt.wantActions {
+					assert.Equal(t, action, report.Items[n].Action)
+				}
+
+				config, err := target.Get(domain.DefaultNamespace, test.ConfigName1)
+				require.NoError(t, err)
+				assert.Contains(t, string(config.Metadata), tt.wantMetadata)
+
+				_, err = target.Get(domain.DefaultNamespace, "new")
+				assert.Equal(t, tt.wantNew, err == nil)
+			})
+		}
+	})
+
+	t.Run("invalid lines are reported line by line", func(t *testing.T) {
+		body := []byte(`{"name": "valid", "metadata": {"foo": "bar"}}
+
+{"metadata": {"foo": "bar"}}
+not json
+{"name": "array", "metadata": ["foo"]}
+{"name": "valid", "metadata": {"foo": "bar"}}
+`)
+		target := repository.NewInMemoryConfig()
+
+		code, report := importInto(t, newRouter(t, target), "", body)
+		require.Equal(t, http.StatusBadRequest, code)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Invalid)
+		assert.Equal(t, 1, report.Created)
+
+		wantLines := []int{1, 3, 4, 5, 6}
+		require.Len(t, report.Items, len(wantLines))
+		for n, line := range wantLines {
+			assert.Equal(t, line, report.Items[n].Line)
+		}
+		assert.Equal(t, "create", report.Items[0].Action)
+		for _, item := range report.Items[1:] {
+			assert.Equal(t, "invalid", item.Action)
+			assert.NotEmpty(t, item.Error)
+		}
+
+		_, err := target.Get(domain.DefaultNamespace, "valid")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/export?format=zip", nil)
+		rr := httptest.NewRecorder()
+		sourceRouter.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		for _, query := range []string{"?strategy=merge", "?dryRun=maybe"} {
+			code, _ := importInto(t, sourceRouter, query, nil)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+		}
+	})
+}
+
+// readTestArchive gets the files in a gzipped tar archive.
+func readTestArchive(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		files[header.Name], err = io.ReadAll(tr)
+		require.NoError(t, err)
+	}
+
+	return files
+}
+
+// writeTestArchive writes files into a gzipped tar archive.
+func writeTestArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return archive.Bytes()
+}
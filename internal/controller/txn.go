@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"net/http"
+)
+
+// @Summary Run a transaction
+// @Description Checks every predicate in compare, on whether a config exists, its revision or the value
+// @Description of a key of its metadata, and runs the success operations if they all hold, or the failure
+// @Description operations otherwise. The operations are all-or-nothing: if any of them fails, none is applied,
+// @Description and the transaction fails with the status that operation would have had on its own
+// @Tags config
+// @Accept json
+// @Produce json
+// @Param namespace path string false "Namespace of the predicates and operations without one, the default namespace when omitted"
+// @Param txn body dto.Txn true "Transaction to run"
+// @Success 200 {object} dto.TxnResult
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 409 {object} dto.Problem "Problem Details"
+// @Failure 412 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /txn [post]
+// @Router /namespaces/{namespace}/txn [post]
+func (c Config) txn(w http.ResponseWriter, r *http.Request) {
+	var requestBody dto.Txn
+	decoder := json.NewDecoder(limitBody(w, r))
+	decoder.UseNumber()
+	if err := decoder.Decode(&requestBody); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if len(requestBody.Success) > maxBulkOps || len(requestBody.Failure) > maxBulkOps {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, fmt.Sprintf("a transaction can't have more than %d operations in a branch", maxBulkOps))
+		return
+	}
+
+	if err := requestBody.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	txn, err := toRepositoryTxn(requestBody, namespaceOf(r))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, dto.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := c.service.Txn(txn)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := dto.TxnResult{Succeeded: result.Succeeded, Results: make([]dto.BulkResult, 0, len(result.Results))}
+	for _, opResult := range result.Results {
+		response.Results = append(response.Results, toBulkResult(opResult))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// toRepositoryTxn converts txn into a transaction, whose predicates
+// and operations apply to namespace unless they have their own.
+func toRepositoryTxn(txn dto.Txn, namespace string) (repository.Txn, error) {
+	var result repository.Txn
+
+	for _, c := range txn.Compare {
+		compare := repository.Compare{
+			Target:    repository.CompareTarget(c.Target),
+			Namespace: c.Namespace,
+			Name:      c.Name,
+			Revision:  c.Revision,
+			Key:       c.Key,
+			Value:     c.Value,
+		}
+		if compare.Namespace == "" {
+			compare.Namespace = namespace
+		}
+		if c.Exists != nil {
+			compare.Exists = *c.Exists
+		}
+		result.Compare = append(result.Compare, compare)
+	}
+
+	var err error
+	if result.Success, err = toRepositoryOps(txn.Success, namespace); err != nil {
+		return repository.Txn{}, err
+	}
+	if result.Failure, err = toRepositoryOps(txn.Failure, namespace); err != nil {
+		return repository.Txn{}, err
+	}
+
+	return result, nil
+}
+
+// toRepositoryOps converts every item into an operation, like toRepositoryOp does.
+func toRepositoryOps(items []dto.BulkOp, namespace string) ([]repository.Op, error) {
+	ops := make([]repository.Op, 0, len(items))
+	for _, item := range items {
+		op, err := toRepositoryOp(item, namespace)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
@@ -0,0 +1,165 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Txn(t *testing.T) {
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+	require.NoError(t, repo.CreateNamespace("team-a"))
+	svc := service.NewConfig(repo)
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	decode := func(t *testing.T, rr *httptest.ResponseRecorder) dto.TxnResult {
+		t.Helper()
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var result dto.TxnResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		return result
+	}
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	t.Run("success branch runs when every predicate holds", func(t *testing.T) {
+		body := fmt.Sprintf(`{
+			"compare": [
+				{"target": "revision", "name": %[1]q, "revision": %[2]d},
+				{"target": "metadata", "name": %[1]q, "key": "foo", "value": "bar"},
+				{"target": "exists", "name": "checkout", "exists": false}
+			],
+			"success": [
+				{"op": "update", "name": %[1]q, "metadata": {"foo": "flipped"}},
+				{"op": "create", "name": "checkout", "metadata": {"foo": "flipped"}}
+			],
+			"failure": [
+				{"op": "create", "name": "failed", "metadata": {}}
+			]
+		}`, test.ConfigName1, current.Revision)
+
+		result := decode(t, serve("/txn", body))
+		assert.True(t, result.Succeeded)
+		require.Len(t, result.Results, 2)
+		assert.Equal(t, http.StatusOK, result.Results[0].Code)
+		assert.Equal(t, http.StatusCreated, result.Results[1].Code)
+		assert.Equal(t, dto.Metadata{"foo": "flipped"}, result.Results[1].Config.Metadata)
+	})
+
+	t.Run("failure branch runs otherwise", func(t *testing.T) {
+		body := fmt.Sprintf(`{
+			"compare": [{"target": "revision", "name": %q, "revision": %d}],
+			"success": [{"op": "delete", "name": "checkout"}],
+			"failure": [{"op": "create", "name": "failed", "metadata": {}}]
+		}`, test.ConfigName1, current.Revision)
+
+		result := decode(t, serve("/txn", body))
+		assert.False(t, result.Succeeded)
+		require.Len(t, result.Results, 1)
+		assert.Equal(t, "failed", result.Results[0].Config.Name)
+
+		_, err := repo.Get(domain.DefaultNamespace, "checkout")
+		assert.NoError(t, err)
+	})
+
+	t.Run("predicates and operations default to the namespace in the path", func(t *testing.T) {
+		body := `{
+			"compare": [{"target": "exists", "name": "scoped", "exists": false}],
+			"success": [{"op": "create", "name": "scoped", "metadata": {}}]
+		}`
+
+		result := decode(t, serve("/namespaces/team-a/txn", body))
+		assert.True(t, result.Succeeded)
+
+		_, err := repo.Get("team-a", "scoped")
+		assert.NoError(t, err)
+	})
+
+	t.Run("a failing operation fails the whole transaction", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			op       string
+			wantCode int
+		}{
+			{
+				name:     "missing config",
+				op:       `{"op": "delete", "name": "nope"}`,
+				wantCode: http.StatusNotFound,
+			},
+			{
+				name:     "existing config",
+				op:       `{"op": "create", "name": "checkout", "metadata": {}}`,
+				wantCode: http.StatusConflict,
+			},
+			{
+				name:     "stale revision",
+				op:       fmt.Sprintf(`{"op": "delete", "name": "checkout", "revision": %d}`, current.Revision),
+				wantCode: http.StatusPreconditionFailed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				body := `{"success": [{"op": "delete", "name": "failed"}, ` + tt.op + `]}`
+				assert.Equal(t, tt.wantCode, serve("/txn", body).Code)
+
+				// the operation before the failing one is undone.
+				_, err := repo.Get(domain.DefaultNamespace, "failed")
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{
+				name: "malformed body",
+				body: `[]`,
+			},
+			{
+				name: "invalid predicate",
+				body: `{"compare": [{"target": "exists", "name": "checkout"}]}`,
+			},
+			{
+				name: "invalid operation",
+				body: `{"success": [{"op": "rename", "name": "checkout"}]}`,
+			},
+			{
+				name: "too many operations",
+				body: `{"failure": [` + strings.TrimSuffix(strings.Repeat(`{"op": "delete", "name": "nope"},`, 1001), ",") + `]}`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, serve("/txn", tt.body).Code)
+			})
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// query params used to watch a config.
+const (
+	watchParam         = "watch"
+	sinceRevisionParam = "sinceRevision"
+	// timeoutParam is how long to wait for the config to change,
+	// as a duration such as "30s" or "2m".
+	timeoutParam = "timeout"
+)
+
+const (
+	// defaultWatchTimeout is how long a watch waits when no timeout is given.
+	defaultWatchTimeout = 30 * time.Second
+	// maxWatchTimeout is the longest a watch is allowed to wait.
+	maxWatchTimeout = 5 * time.Minute
+)
+
+// errInvalidWatch is used when the query params to watch a config aren't valid.
+var errInvalidWatch = errors.New("invalid watch")
+
+// watchOptions describes how a config is watched.
+type watchOptions struct {
+	sinceRevision int64
+	timeout       time.Duration
+}
+
+// parseWatch gets the options to watch a config out of the query params in values.
+// It tells false when the config isn't meant to be watched.
+func parseWatch(values url.Values) (watchOptions, bool, error) {
+	opts := watchOptions{timeout: defaultWatchTimeout}
+
+	if watch := values.Get(watchParam); watch == "" {
+		return opts, false, nil
+	} else if ok, err := strconv.ParseBool(watch); err != nil {
+		return opts, false, fmt.Errorf("%w: watch must be a boolean", errInvalidWatch)
+	} else if !ok {
+		return opts, false, nil
+	}
+
+	if values.Has("revision") {
+		return opts, false, fmt.Errorf("%w: a pinned revision can't be watched", errInvalidWatch)
+	}
+
+	since, err := strconv.ParseInt(values.Get(sinceRevisionParam), 10, 64)
+	if err != nil || since < 0 {
+		return opts, false, fmt.Errorf("%w: sinceRevision must be a non-negative integer", errInvalidWatch)
+	}
+	opts.sinceRevision = since
+
+	if timeout := values.Get(timeoutParam); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return opts, false, fmt.Errorf("%w: timeout must be a positive duration", errInvalidWatch)
+		}
+		opts.timeout = min(d, maxWatchTimeout)
+	}
+
+	return opts, true, nil
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/middleware"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"net/http"
+)
+
+// NewWebhook creates a new Webhook controller instance.
+// It expects a service as a dependency.
+func NewWebhook(svc *service.Webhooks) *Webhook {
+	return &Webhook{service: svc}
+}
+
+// Webhook is the webhook controller.
+// It defines routes and handlers for the webhook resources.
+type Webhook struct {
+	service *service.Webhooks
+}
+
+// SetRouter returns the router r with all the necessary routes for the
+// Webhook controller setup.
+func (wh Webhook) SetRouter(r *mux.Router) {
+	r.HandleFunc("/webhooks", middleware.SetJSONContent(wh.list)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/webhooks", middleware.SetJSONContent(wh.create)).
+		Methods(http.MethodPost)
+	r.HandleFunc("/webhooks/{id}", middleware.SetJSONContent(wh.get)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/webhooks/{id}", middleware.SetJSONContent(wh.update)).
+		Methods(http.MethodPut)
+	r.HandleFunc("/webhooks/{id}", middleware.SetJSONContent(wh.delete)).
+		Methods(http.MethodDelete)
+	r.HandleFunc("/webhooks/{id}/deliveries", middleware.SetJSONContent(wh.deliveries)).
+		Methods(http.MethodGet)
+}
+
+// @Summary List webhooks
+// @Description Lists every webhook, leaving their secrets out
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.Webhook
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks [get]
+func (wh Webhook) list(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := wh.service.List()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := make([]dto.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, dto.FromDomainWebhook(webhook))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// @Summary Create a new webhook
+// @Description Subscribes a URL to the changes made to the configs matching its filters.
+// @Description Events are posted signed with the HMAC-SHA256 of the body keyed with the secret,
+// @Description in the X-Signature-256 header, and retried with exponential backoff on failure
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param webhook body dto.Webhook true "Webhook object to be created"
+// @Success 201 {object} dto.Webhook
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks [post]
+func (wh Webhook) create(w http.ResponseWriter, r *http.Request) {
+	requestBody, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := wh.service.Create(requestBody.ToDomainWebhook())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, dto.FromDomainWebhook(webhook))
+}
+
+// @Summary Get a webhook by ID
+// @Description Gets a webhook by its ID, leaving its secret out
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the webhook"
+// @Success 200 {object} dto.Webhook
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks/{id} [get]
+func (wh Webhook) get(w http.ResponseWriter, r *http.Request) {
+	webhook, err := wh.service.Get(mux.Vars(r)["id"])
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.FromDomainWebhook(webhook))
+}
+
+// @Summary Update a webhook by ID
+// @Description Replaces the URL, filters and secret of a webhook
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the webhook"
+// @Param webhook body dto.Webhook true "Webhook object replacing the current one"
+// @Success 200 {object} dto.Webhook
+// @Failure 400 {object} dto.Problem "Problem Details"
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 413 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks/{id} [put]
+func (wh Webhook) update(w http.ResponseWriter, r *http.Request) {
+	requestBody, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	webhook := requestBody.ToDomainWebhook()
+	webhook.ID = mux.Vars(r)["id"]
+
+	webhook, err := wh.service.Update(webhook)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.FromDomainWebhook(webhook))
+}
+
+// @Summary Delete a webhook by ID
+// @Description Deletes a webhook by its ID, giving up on its pending deliveries
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the webhook"
+// @Success 200
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks/{id} [delete]
+func (wh Webhook) delete(w http.ResponseWriter, r *http.Request) {
+	if err := wh.service.Delete(mux.Vars(r)["id"]); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary List the deliveries of a webhook
+// @Description Lists the latest deliveries of a webhook, from the oldest
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the webhook"
+// @Success 200 {array} dto.Delivery
+// @Failure 404 {object} dto.Problem "Problem Details"
+// @Failure 500 {object} dto.Problem "Problem Details"
+// @Router /webhooks/{id}/deliveries [get]
+func (wh Webhook) deliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := wh.service.Deliveries(mux.Vars(r)["id"])
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	response := make([]dto.Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		dtoDelivery, err := dto.FromDomainDelivery(delivery)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		response = append(response, dtoDelivery)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// decodeWebhook decodes and validates the webhook in the request body.
+// It writes the error response when it isn't valid.
+func decodeWebhook(w http.ResponseWriter, r *http.Request) (dto.Webhook, bool) {
+	var requestBody dto.Webhook
+	if err := json.NewDecoder(limitBody(w, r)).Decode(&requestBody); err != nil {
+		writeDecodeError(w, err)
+		return dto.Webhook{}, false
+	}
+
+	if err := requestBody.Validate(); err != nil {
+		writeError(w, err)
+		return dto.Webhook{}, false
+	}
+
+	return requestBody, true
+}
+
+// writeWebhookError writes the error response of err.
+func writeWebhookError(w http.ResponseWriter, err error) {
+	writeError(w, err)
+}
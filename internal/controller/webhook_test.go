@@ -0,0 +1,126 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller/dto"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	received := make(chan struct{}, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+	defer webhooks.Close()
+	svc := service.NewConfig(repository.NewInMemoryConfig(), service.WithWebhooks(webhooks))
+
+	r := mux.NewRouter()
+	controller.NewConfig(svc).SetRouter(r)
+	controller.NewWebhook(webhooks).SetRouter(r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var created dto.Webhook
+
+	t.Run("create webhook", func(t *testing.T) {
+		body := fmt.Sprintf(`{"url": %q, "secret": "s3cr3t", "prefix": "web-"}`, receiver.URL)
+		rr := serve(http.MethodPost, "/webhooks", body)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		assert.NotEmpty(t, created.ID)
+		assert.Equal(t, "web-", created.Prefix)
+
+		t.Run("secret isn't disclosed", func(t *testing.T) {
+			assert.NotContains(t, rr.Body.String(), "s3cr3t")
+		})
+	})
+
+	t.Run("invalid webhook", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{name: "malformed body", body: `{"url":`},
+			{name: "missing secret", body: `{"url": "https://example.com"}`},
+			{name: "invalid query", body: `{"url": "https://example.com", "secret": "s3cr3t", "query": "a like b"}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/webhooks", tt.body).Code)
+			})
+		}
+	})
+
+	t.Run("list webhooks", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/webhooks", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var webhooks []dto.Webhook
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhooks))
+		assert.Equal(t, []dto.Webhook{created}, webhooks)
+	})
+
+	t.Run("get webhook", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/webhooks/"+created.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/webhooks/nope", "").Code)
+	})
+
+	t.Run("update webhook", func(t *testing.T) {
+		body := fmt.Sprintf(`{"url": %q, "secret": "s3cr3t", "prefix": "api-"}`, receiver.URL)
+		rr := serve(http.MethodPut, "/webhooks/"+created.ID, body)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var updated dto.Webhook
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &updated))
+		assert.Equal(t, "api-", updated.Prefix)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/webhooks/nope", body).Code)
+	})
+
+	t.Run("deliveries are listed", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/configs", `{"name": "api-1", "metadata": {"region": "eu"}}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		<-received
+
+		var deliveries []dto.Delivery
+		require.Eventually(t, func() bool {
+			rr := serve(http.MethodGet, "/webhooks/"+created.ID+"/deliveries", "")
+			if err := json.Unmarshal(rr.Body.Bytes(), &deliveries); err != nil {
+				return false
+			}
+			return len(deliveries) == 1 && deliveries[0].Status == string(domain.DeliverySucceeded)
+		}, time.Second, time.Millisecond)
+
+		assert.Equal(t, "api-1", deliveries[0].Event.Config.Name)
+		assert.Equal(t, "created", deliveries[0].Event.Type)
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodDelete, "/webhooks/"+created.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/webhooks/"+created.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/webhooks/"+created.ID+"/deliveries", "").Code)
+	})
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// errInvalidYAML is used when a YAML document can't be taken as JSON.
+var errInvalidYAML = errors.New("invalid YAML")
+
+// yamlToJSON converts a YAML document into JSON, so that it goes through
+// the same decoding and validation JSON does.
+//
+// Anchors and aliases, merge keys included, are rejected, and so are custom tags
+// and keys that aren't scalars. Scalar keys are taken as they're written,
+// so `1: x` and `true: x` get the keys "1" and "true".
+func yamlToJSON(document []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(document))
+
+	var node yaml.Node
+	if err := decoder.Decode(&node); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty document", errInvalidYAML)
+		}
+		return nil, fmt.Errorf("%w: %s", errInvalidYAML, err.Error())
+	}
+	if err := decoder.Decode(&yaml.Node{}); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: only a single document is supported", errInvalidYAML)
+	}
+
+	value, err := yamlValue(&node)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidYAML, err.Error())
+	}
+
+	document, err = json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidYAML, err.Error())
+	}
+
+	return document, nil
+}
+
+// yamlValue gets the value of node as it'd be decoded from JSON.
+func yamlValue(node *yaml.Node) (any, error) {
+	if node.Anchor != "" {
+		return nil, fmt.Errorf("line %d: anchors aren't supported", node.Line)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return nil, fmt.Errorf("line %d: aliases aren't supported", node.Line)
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case yaml.MappingNode:
+		values := make(map[string]any, len(node.Content)/2)
+		for n := 0; n < len(node.Content); n += 2 {
+			key, item := node.Content[n], node.Content[n+1]
+			if key.Kind != yaml.ScalarNode || key.Anchor != "" {
+				return nil, fmt.Errorf("line %d: keys must be scalars", key.Line)
+			}
+			if key.ShortTag() == "!!merge" {
+				return nil, fmt.Errorf("line %d: merge keys aren't supported", key.Line)
+			}
+			if _, ok := values[key.Value]; ok {
+				return nil, fmt.Errorf("line %d: key %q is duplicated", key.Line, key.Value)
+			}
+
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values[key.Value] = value
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!str", "!!timestamp", "!!binary":
+			return node.Value, nil
+		case "!!null":
+			return nil, nil
+		case "!!bool", "!!int", "!!float":
+			var value any
+			if err := node.Decode(&value); err != nil {
+				return nil, fmt.Errorf("line %d: %s", node.Line, err.Error())
+			}
+			return value, nil
+		default:
+			return nil, fmt.Errorf("line %d: tag %s isn't supported", node.Line, node.Tag)
+		}
+	default:
+		return nil, fmt.Errorf("line %d: unexpected node", node.Line)
+	}
+}
+
+// jsonToYAML converts a JSON document into YAML in block style,
+// keeping the order of the keys.
+func jsonToYAML(document []byte) ([]byte, error) {
+	// JSON being YAML as well, it's parsed as is, and only its style is changed.
+	var node yaml.Node
+	if err := yaml.Unmarshal(document, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// blockStyle drops the style of node and its children, leaving
+// the YAML encoder to pick block style and quote only what must be.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
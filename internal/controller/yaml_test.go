@@ -0,0 +1,185 @@
+package controller_test
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/controller"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfig_YAML(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	r := mux.NewRouter()
+	controller.NewConfig(service.NewConfig(repo)).SetRouter(r)
+
+	serve := func(method, target, contentType, accept, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("create", func(t *testing.T) {
+		body := `
+name: payments
+metadata:
+  provider: stripe
+  limits:
+    daily: "1000"
+  1: numeric key
+  true: bool key
+  replicas: 3
+  canary: false
+  zones: [eu, us]
+  owner: ~
+`
+		rr := serve(http.MethodPost, "/configs", "application/yaml", "", body)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		config, err := repo.Get(domain.DefaultNamespace, "payments")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"provider":"stripe","limits":{"daily":"1000"},"1":"numeric key","true":"bool key","replicas":3,"canary":false,"zones":["eu","us"],"owner":null}`, string(config.Metadata))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/payments", "", "application/yaml", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+
+		var config struct {
+			Name     string
+			Metadata map[string]any
+			Revision int64
+		}
+		require.NoError(t, yaml.Unmarshal(rr.Body.Bytes(), &config))
+		assert.Equal(t, "payments", config.Name)
+		assert.Equal(t, "stripe", config.Metadata["provider"])
+		assert.Equal(t, "bool key", config.Metadata["true"], "keys that look like other types are quoted")
+		assert.NotZero(t, config.Revision)
+
+		t.Run("keeps the order of the fields", func(t *testing.T) {
+			assert.True(t, strings.HasPrefix(rr.Body.String(), "namespace: default\nname: payments\n"), rr.Body.String())
+		})
+	})
+
+	t.Run("list and search", func(t *testing.T) {
+		for _, target := range []string{"/configs", "/search?provider=stripe"} {
+			rr := serve(http.MethodGet, target, "", "text/yaml", "")
+			require.Equal(t, http.StatusOK, rr.Code, target)
+			assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+
+			var configs []map[string]any
+			require.NoError(t, yaml.Unmarshal(rr.Body.Bytes(), &configs))
+			assert.Len(t, configs, 1, target)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		rr := serve(http.MethodPut, "/configs/payments", "application/x-yaml", "", "provider: adyen\n")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		config, err := repo.Get(domain.DefaultNamespace, "payments")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"provider":"adyen"}`, string(config.Metadata))
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		rr := serve(http.MethodPatch, "/configs/payments", "application/yaml", "", "region: eu\n")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		config, err := repo.Get(domain.DefaultNamespace, "payments")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"provider":"adyen","region":"eu"}`, string(config.Metadata))
+	})
+
+	t.Run("bulk", func(t *testing.T) {
+		body := `
+- op: create
+  name: checkout
+  metadata:
+    provider: adyen
+`
+		rr := serve(http.MethodPost, "/configs:bulk", "application/yaml", "application/yaml", body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var results []struct{ Code int }
+		require.NoError(t, yaml.Unmarshal(rr.Body.Bytes(), &results))
+		require.Len(t, results, 1)
+		assert.Equal(t, http.StatusCreated, results[0].Code)
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{
+				name: "same validation as JSON",
+				body: "metadata:\n  enabled: true\n",
+			},
+			{
+				name: "anchors and aliases",
+				body: "name: flags\nmetadata:\n  base: &base {a: b}\n  copy: *base\n",
+			},
+			{
+				name: "merge keys",
+				body: "name: flags\nmetadata:\n  <<: {a: b}\n",
+			},
+			{
+				name: "non-scalar keys",
+				body: "name: flags\nmetadata:\n  ? [a, b]\n  : c\n",
+			},
+			{
+				name: "duplicated keys",
+				body: "name: flags\nmetadata:\n  1: a\n  \"1\": b\n",
+			},
+			{
+				name: "custom tags",
+				body: "name: flags\nmetadata:\n  a: !secret b\n",
+			},
+			{
+				name: "several documents",
+				body: "name: flags\n---\nname: other\n",
+			},
+			{
+				name: "malformed",
+				body: "name: [flags\n",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := serve(http.MethodPost, "/configs", "application/yaml", "", tt.body)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				_, err := repo.Get(domain.DefaultNamespace, "flags")
+				assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+			})
+		}
+	})
+
+	t.Run("unsupported media types", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/configs/payments", "", "text/html", "")
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+
+		rr = serve(http.MethodPost, "/configs", "text/plain", "", "name: flags")
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+		rr = serve(http.MethodPut, "/configs/payments", "application/xml", "", "<provider/>")
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	})
+}
@@ -1,27 +1,48 @@
 package domain
 
 import (
-	"encoding/json"
 	"strings"
+	"time"
 )
 
 // Config represents a set of configs identified by its name.
 type Config struct {
+	// Namespace is the name of the namespace holding the config.
+	// Config names are only unique within a namespace.
+	Namespace string `json:"namespace"`
 	// Name is the name of the config.
 	Name string `json:"name"`
 	// Metadata is the arbitrary key value pairs of metadata
 	// that compose a config.
 	Metadata []byte `json:"metadata"`
+	// Parents are the names of the configs in the same namespace this config
+	// inherits metadata from, each overriding the ones before it.
+	Parents []string `json:"parents,omitempty"`
+	// Revision identifies the change that produced this version of the config.
+	// Revisions are assigned from a store-wide counter, so they're monotonically
+	// increasing across the history of a config.
+	Revision int64 `json:"revision"`
+	// UpdatedAt is the time when this version of the config was stored.
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 // MetadataValue traverses the metadata data structure
 // to get the corresponding value for the key.
 //
 // A key is expected to have the following format: `aaa.bbb.ccc`,
-// where each dot represents each key node in a different nest level.
+// where each dot represents each key node in a different nest level,
+// and elements of arrays are matched by their index, as in `hosts.0`.
 //
 // It returns nil if no matching value is found.
 func (c Config) MetadataValue(key string) any {
+	value, _ := c.LookupMetadataValue(key)
+	return value
+}
+
+// LookupMetadataValue is like MetadataValue, but it also tells if a matching
+// value is found, so that a null value can be told apart from a missing one.
+// The value of a key holding other keys is the nested structure under it.
+func (c Config) LookupMetadataValue(key string) (any, bool) {
 	// break down the nested key format
 	// into separate distinct keys.
 	keys := strings.Split(key, ".")
@@ -30,8 +51,8 @@ func (c Config) MetadataValue(key string) any {
 	// of the unstructured nature of the key/value pairs
 	// expected.
 	var m map[string]any
-	if err := json.Unmarshal(c.Metadata, &m); err != nil {
-		return nil
+	if err := DecodeJSON(c.Metadata, &m); err != nil {
+		return nil, false
 	}
 
 	return traverseAndFind(keys, m)
@@ -39,22 +60,37 @@ func (c Config) MetadataValue(key string) any {
 
 // traverseAndFind takes in a key slice representing a nested key structure
 // and the key value data that it's trying to match.
-func traverseAndFind(keys []string, data any) any {
+func traverseAndFind(keys []string, data any) (any, bool) {
+	// once every key is matched, data is the value,
+	// be it a final value or a nested structure.
+	if len(keys) == 0 {
+		return data, true
+	}
+
 	// use type cast to know if the current data
-	// is a key/value pair or if it's the final value.
+	// is a key/value pair, an array, or if it's the final value,
+	// which has no keys under it to match.
 	switch t := data.(type) {
 	case map[string]any:
-		data, ok := t[keys[0]]
-		if !ok {
+		var ok bool
+		if data, ok = t[keys[0]]; !ok {
 			// if it doesn't match any key in the current level
 			// it's safe to assume the key doesn't match.
-			return nil
+			return nil, false
+		}
+	case []any:
+		// the elements of an array are matched by their index.
+		n, ok := elementIndex(keys[0], len(t))
+		if !ok {
+			return nil, false
 		}
-		// In the case that there's a corresponding data matching
-		// the current key, proceed to traverse for the next keys
-		// to make sure the entire set of nested keys is considered.
-		return traverseAndFind(keys[1:], data)
+		data = t[n]
 	default:
-		return data
+		return nil, false
 	}
+
+	// In the case that there's a corresponding data matching
+	// the current key, proceed to traverse for the next keys
+	// to make sure the entire set of nested keys is considered.
+	return traverseAndFind(keys[1:], data)
 }
@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"encoding/json"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
 	"github.com/stretchr/testify/assert"
@@ -37,3 +38,38 @@ func TestConfig_MetadataValue(t *testing.T) {
 		assert.Equal(t, "ccc", got)
 	})
 }
+
+func TestConfig_LookupMetadataValue(t *testing.T) {
+	c := domain.Config{
+		Name:     test.ConfigName1,
+		Metadata: []byte(`{"abc": "123", "empty": null, "obj": {"aaa": {"bbb": "ccc"}}, "port": 8080, "hosts": ["eu-1", {"name": "us-1"}]}`),
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		wantValue any
+		wantFound bool
+	}{
+		{name: "value", key: "abc", wantValue: "123", wantFound: true},
+		{name: "nested value", key: "obj.aaa.bbb", wantValue: "ccc", wantFound: true},
+		{name: "subtree", key: "obj.aaa", wantValue: map[string]any{"bbb": "ccc"}, wantFound: true},
+		{name: "null value", key: "empty", wantValue: nil, wantFound: true},
+		{name: "missing key", key: "nope", wantValue: nil, wantFound: false},
+		{name: "key under a value", key: "abc.def", wantValue: nil, wantFound: false},
+		{name: "number", key: "port", wantValue: json.Number("8080"), wantFound: true},
+		{name: "array", key: "hosts", wantValue: []any{"eu-1", map[string]any{"name": "us-1"}}, wantFound: true},
+		{name: "array element", key: "hosts.0", wantValue: "eu-1", wantFound: true},
+		{name: "key in an array element", key: "hosts.1.name", wantValue: "us-1", wantFound: true},
+		{name: "array element out of range", key: "hosts.2", wantValue: nil, wantFound: false},
+		{name: "array index with a leading zero", key: "hosts.00", wantValue: nil, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := c.LookupMetadataValue(tt.key)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantFound, found)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// EventType is the kind of change an event describes.
+type EventType string
+
+const (
+	// EventCreated is the type of the events of configs being created.
+	EventCreated EventType = "created"
+	// EventUpdated is the type of the events of configs being updated.
+	EventUpdated EventType = "updated"
+	// EventDeleted is the type of the events of configs being deleted.
+	EventDeleted EventType = "deleted"
+)
+
+// Event describes a change made to a config.
+type Event struct {
+	// ID identifies the event. IDs are monotonically increasing,
+	// so that the events following a given one can be told apart.
+	ID int64 `json:"id"`
+	// Type is the kind of change made to the config.
+	Type EventType `json:"type"`
+	// Config is the config as it was right after the change,
+	// or right before it when it was deleted.
+	Config Config `json:"config"`
+	// Time is the time when the change was made.
+	Time time.Time `json:"time"`
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidFlatKey is used when a flattened key can't be expanded,
+	// such as when it has an empty key node.
+	ErrInvalidFlatKey = errors.New("invalid flattened key")
+	// ErrFlatKeyConflict is used when flattened keys can't be expanded together,
+	// because one of them is both a value and a key node of another.
+	ErrFlatKeyConflict = errors.New("conflicting flattened keys")
+)
+
+// FlattenMetadata flattens metadata into a single level of key value pairs,
+// where each key is the path of a value, in the format MetadataValue takes,
+// such as `aaa.bbb.ccc`.
+//
+// Strings are taken as they are, null as an empty string, and any other
+// value as its JSON encoding. Empty objects have no values, so they're left out.
+func FlattenMetadata(metadata []byte) (map[string]string, error) {
+	var m map[string]any
+	if err := DecodeJSON(metadata, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	flat := make(map[string]string)
+	if err := flatten("", m, flat); err != nil {
+		return nil, err
+	}
+
+	return flat, nil
+}
+
+// flatten adds every value in m to flat, with its key prefixed by prefix.
+func flatten(prefix string, m map[string]any, flat map[string]string) error {
+	for key, value := range m {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flatten(key, v, flat); err != nil {
+				return err
+			}
+		case string:
+			flat[key] = v
+		case nil:
+			flat[key] = ""
+		default:
+			bytes, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", key, err)
+			}
+			flat[key] = string(bytes)
+		}
+	}
+
+	return nil
+}
+
+// ExpandMetadata expands flat key value pairs, as flattened by FlattenMetadata,
+// back into nested metadata, where each dot in a key represents each key node
+// in a different nest level.
+func ExpandMetadata(flat map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	// sorting makes the conflict reported the same on every call.
+	sort.Strings(keys)
+
+	m := make(map[string]any)
+	for _, key := range keys {
+		nodes := strings.Split(key, ".")
+		for _, node := range nodes {
+			if node == "" {
+				return nil, fmt.Errorf("%w: %q has an empty key node", ErrInvalidFlatKey, key)
+			}
+		}
+
+		parent := m
+		for n, node := range nodes[:len(nodes)-1] {
+			switch child := parent[node].(type) {
+			case nil:
+				next := make(map[string]any)
+				parent[node] = next
+				parent = next
+			case map[string]any:
+				parent = child
+			default:
+				return nil, fmt.Errorf("%w: %q has a value, so it can't hold %q", ErrFlatKeyConflict, strings.Join(nodes[:n+1], "."), key)
+			}
+		}
+
+		last := nodes[len(nodes)-1]
+		if _, ok := parent[last]; ok {
+			return nil, fmt.Errorf("%w: %q holds other keys, so it can't have a value", ErrFlatKeyConflict, key)
+		}
+		parent[last] = flat[key]
+	}
+
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return bytes, nil
+}
@@ -0,0 +1,102 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFlattenMetadata(t *testing.T) {
+	metadata := []byte(`
+		{
+			"enabled": "true",
+			"abc": 123,
+			"empty": {},
+			"nothing": null,
+			"obj": {
+				"aaa": {
+					"bbb": "ccc"
+				},
+				"ddd": "eee"
+			}
+		}`)
+
+	flat, err := domain.FlattenMetadata(metadata)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"enabled":     "true",
+		"abc":         "123",
+		"nothing":     "",
+		"obj.aaa.bbb": "ccc",
+		"obj.ddd":     "eee",
+	}, flat)
+
+	t.Run("every key is found by MetadataValue", func(t *testing.T) {
+		config := domain.Config{Metadata: metadata}
+		assert.Equal(t, "ccc", config.MetadataValue("obj.aaa.bbb"))
+		assert.Equal(t, "eee", config.MetadataValue("obj.ddd"))
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := domain.FlattenMetadata([]byte(`[]`))
+		assert.Error(t, err)
+	})
+}
+
+func TestExpandMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		flat         map[string]string
+		wantMetadata string
+		wantErr      error
+	}{
+		{
+			name:         "dotted keys are nested",
+			flat:         map[string]string{"enabled": "true", "obj.aaa.bbb": "ccc", "obj.ddd": "eee"},
+			wantMetadata: `{"enabled": "true", "obj": {"aaa": {"bbb": "ccc"}, "ddd": "eee"}}`,
+		},
+		{
+			name:         "nothing",
+			flat:         map[string]string{},
+			wantMetadata: `{}`,
+		},
+		{
+			name:    "empty key node",
+			flat:    map[string]string{"obj..aaa": "bbb"},
+			wantErr: domain.ErrInvalidFlatKey,
+		},
+		{
+			name:    "empty key",
+			flat:    map[string]string{"": "bbb"},
+			wantErr: domain.ErrInvalidFlatKey,
+		},
+		{
+			name:    "value holding other keys",
+			flat:    map[string]string{"obj": "aaa", "obj.bbb": "ccc"},
+			wantErr: domain.ErrFlatKeyConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := domain.ExpandMetadata(tt.flat)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantMetadata, string(metadata))
+		})
+	}
+
+	t.Run("expands what's flattened", func(t *testing.T) {
+		metadata := `{"enabled": "true", "obj": {"aaa": {"bbb": "ccc"}, "ddd": "eee"}}`
+
+		flat, err := domain.FlattenMetadata([]byte(metadata))
+		require.NoError(t, err)
+		expanded, err := domain.ExpandMetadata(flat)
+		require.NoError(t, err)
+		assert.JSONEq(t, metadata, string(expanded))
+	})
+}
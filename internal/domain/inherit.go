@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrParentNotFound is used when a config inherits from a config that doesn't exist.
+	ErrParentNotFound = errors.New("parent config not found")
+	// ErrParentCycle is used when a config inherits from itself, directly or not.
+	ErrParentCycle = errors.New("parent configs form a cycle")
+)
+
+// ConfigLookup finds a config by its name, telling if it's found.
+type ConfigLookup func(name string) (Config, bool)
+
+// CheckParents checks that every config config inherits from, directly or not,
+// can be found through lookup, returning ErrParentNotFound otherwise, and that
+// none of them inherits from config, returning ErrParentCycle otherwise.
+// config itself is never looked up, so it can be checked before it's stored.
+func CheckParents(config Config, lookup ConfigLookup) error {
+	return checkParents(config, lookup, []string{config.Name}, make(map[string]bool))
+}
+
+// checkParents checks the parents of config, where path holds the configs
+// inheriting from one another down to config, and checked the configs whose
+// parents are already checked.
+func checkParents(config Config, lookup ConfigLookup, path []string, checked map[string]bool) error {
+	for _, name := range config.Parents {
+		if slices.Contains(path, name) {
+			cycle := append(slices.Clone(path[slices.Index(path, name):]), name)
+			return fmt.Errorf("%w: %s", ErrParentCycle, strings.Join(cycle, " -> "))
+		}
+		if checked[name] {
+			continue
+		}
+
+		parent, ok := lookup(name)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrParentNotFound, name)
+		}
+		if err := checkParents(parent, lookup, append(path, name), checked); err != nil {
+			return err
+		}
+		checked[name] = true
+	}
+
+	return nil
+}
+
+// Resolve gets config with the metadata of its parents, found through lookup,
+// deep merged under its own. Parents are merged in order, each overriding the
+// ones before it, after having their own parents resolved.
+//
+// The revision and update time of the resolved config are the latest ones
+// among it and the configs it inherits from, so that they change whenever
+// any of them does.
+func Resolve(config Config, lookup ConfigLookup) (Config, error) {
+	return resolve(config, lookup, make(map[string]bool), make(map[string]Config))
+}
+
+// resolve resolves config, where resolving holds the configs being resolved
+// down to config, and resolved the configs already resolved.
+func resolve(config Config, lookup ConfigLookup, resolving map[string]bool, resolved map[string]Config) (Config, error) {
+	if len(config.Parents) == 0 {
+		return config, nil
+	}
+	if r, ok := resolved[config.Name]; ok {
+		return r, nil
+	}
+	if resolving[config.Name] {
+		return Config{}, fmt.Errorf("%w: %q inherits from itself", ErrParentCycle, config.Name)
+	}
+	resolving[config.Name] = true
+	defer delete(resolving, config.Name)
+
+	metadata := []byte(`{}`)
+	revision, updatedAt := config.Revision, config.UpdatedAt
+	for _, name := range config.Parents {
+		parent, ok := lookup(name)
+		if !ok {
+			return Config{}, fmt.Errorf("%w: %q", ErrParentNotFound, name)
+		}
+		parent, err := resolve(parent, lookup, resolving, resolved)
+		if err != nil {
+			return Config{}, err
+		}
+
+		if metadata, err = MergePatch(metadata, parent.Metadata); err != nil {
+			return Config{}, err
+		}
+		revision = max(revision, parent.Revision)
+		if parent.UpdatedAt.After(updatedAt) {
+			updatedAt = parent.UpdatedAt
+		}
+	}
+
+	metadata, err := MergePatch(metadata, config.Metadata)
+	if err != nil {
+		return Config{}, err
+	}
+	config.Metadata, config.Revision, config.UpdatedAt = metadata, revision, updatedAt
+	resolved[config.Name] = config
+
+	return config, nil
+}
@@ -0,0 +1,120 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// lookupOf finds configs among configs by their name.
+func lookupOf(configs ...domain.Config) domain.ConfigLookup {
+	return func(name string) (domain.Config, bool) {
+		for _, c := range configs {
+			if c.Name == name {
+				return c, true
+			}
+		}
+		return domain.Config{}, false
+	}
+}
+
+func TestCheckParents(t *testing.T) {
+	base := domain.Config{Name: "base"}
+	eu := domain.Config{Name: "eu", Parents: []string{"base"}}
+	de := domain.Config{Name: "de", Parents: []string{"eu", "base"}}
+
+	tests := []struct {
+		name    string
+		config  domain.Config
+		wantErr error
+	}{
+		{name: "without parents", config: domain.Config{Name: "new"}},
+		{name: "with ancestors", config: domain.Config{Name: "new", Parents: []string{"de", "eu"}}},
+		{name: "missing parent", config: domain.Config{Name: "new", Parents: []string{"base", "nope"}}, wantErr: domain.ErrParentNotFound},
+		{name: "itself", config: domain.Config{Name: "new", Parents: []string{"new"}}, wantErr: domain.ErrParentCycle},
+		{name: "its own descendant", config: domain.Config{Name: "base", Parents: []string{"de"}}, wantErr: domain.ErrParentCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.CheckParents(tt.config, lookupOf(base, eu, de))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("reports the cycle", func(t *testing.T) {
+		err := domain.CheckParents(domain.Config{Name: "base", Parents: []string{"de"}}, lookupOf(base, eu, de))
+		assert.EqualError(t, err, "parent configs form a cycle: base -> de -> eu -> base")
+	})
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Now().UTC()
+
+	base := domain.Config{
+		Name:      "base",
+		Metadata:  []byte(`{"calories": "230", "allergens": {"gluten": "yes", "nuts": "no"}, "currency": "EUR"}`),
+		Revision:  1,
+		UpdatedAt: now,
+	}
+	uk := domain.Config{
+		Name:      "uk",
+		Metadata:  []byte(`{"currency": "GBP", "allergens": {"nuts": "traces"}}`),
+		Parents:   []string{"base"},
+		Revision:  5,
+		UpdatedAt: now.Add(time.Hour),
+	}
+	vegan := domain.Config{
+		Name:      "vegan",
+		Metadata:  []byte(`{"allergens": {"milk": "no"}, "currency": "USD"}`),
+		Revision:  2,
+		UpdatedAt: now,
+	}
+	lookup := lookupOf(base, uk, vegan)
+
+	t.Run("without parents", func(t *testing.T) {
+		got, err := domain.Resolve(base, lookup)
+		require.NoError(t, err)
+		assert.Equal(t, base, got)
+	})
+
+	t.Run("children override their parents", func(t *testing.T) {
+		got, err := domain.Resolve(domain.Config{
+			Name:     "uk-vegan",
+			Metadata: []byte(`{"calories": "180"}`),
+			Parents:  []string{"uk", "vegan"},
+			Revision: 3,
+		}, lookup)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"calories": "180", "allergens": {"gluten": "yes", "nuts": "traces", "milk": "no"}, "currency": "USD"}`, string(got.Metadata))
+
+		t.Run("with the latest revision and update time", func(t *testing.T) {
+			assert.Equal(t, int64(5), got.Revision)
+			assert.Equal(t, now.Add(time.Hour), got.UpdatedAt)
+		})
+	})
+
+	t.Run("later parents override the ones before", func(t *testing.T) {
+		got, err := domain.Resolve(domain.Config{Name: "x", Metadata: []byte(`{}`), Parents: []string{"vegan", "uk"}}, lookup)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"calories": "230", "allergens": {"gluten": "yes", "nuts": "traces", "milk": "no"}, "currency": "GBP"}`, string(got.Metadata))
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		_, err := domain.Resolve(domain.Config{Name: "x", Metadata: []byte(`{}`), Parents: []string{"nope"}}, lookup)
+		assert.ErrorIs(t, err, domain.ErrParentNotFound)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		a := domain.Config{Name: "a", Metadata: []byte(`{}`), Parents: []string{"b"}}
+		b := domain.Config{Name: "b", Metadata: []byte(`{}`), Parents: []string{"a"}}
+		_, err := domain.Resolve(a, lookupOf(a, b))
+		assert.ErrorIs(t, err, domain.ErrParentCycle)
+	})
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidReference is used when a reference in a metadata value isn't
+	// in the `${config:key}` format, or it points at nested metadata, an array, null or a secret.
+	ErrInvalidReference = errors.New("invalid metadata reference")
+	// ErrReferenceNotFound is used when a reference without a default value
+	// points at a config or a key that doesn't exist.
+	ErrReferenceNotFound = errors.New("metadata reference not found")
+	// ErrReferenceCycle is used when a metadata value references itself, directly or not.
+	ErrReferenceCycle = errors.New("metadata references form a cycle")
+)
+
+// Interpolate gets config with every reference in its metadata values replaced
+// by the value it points at, in the configs found through lookup.
+//
+// References are written as `${config:key}`, where key is in the format
+// MetadataValue takes, and they can fall back on a default value when what
+// they point at doesn't exist, as in `${config:key:-default}`. Referenced
+// values are interpolated as well, and `$${` is taken as a literal `${`.
+// Referenced numbers, in decimal notation, and booleans are written as text, and
+// strings nested in arrays are interpolated just like any other.
+//
+// The revision and update time of the interpolated config are the latest
+// ones among it and the configs it references, so that they change whenever
+// any of them does.
+func Interpolate(config Config, lookup ConfigLookup) (Config, error) {
+	m, err := unmarshalMetadata(config.Metadata)
+	if err != nil {
+		return Config{}, err
+	}
+
+	in := interpolator{lookup: lookup, values: make(map[string]string), revision: config.Revision, updatedAt: config.UpdatedAt}
+	if _, err := in.walk(config.Name, "", m); err != nil {
+		return Config{}, err
+	}
+
+	if config.Metadata, err = marshalMetadata(m); err != nil {
+		return Config{}, err
+	}
+	config.Revision, config.UpdatedAt = in.revision, in.updatedAt
+
+	return config, nil
+}
+
+// reference is a reference to the value of a metadata key of a config.
+type reference struct {
+	config      string
+	key         string
+	fallback    string
+	hasFallback bool
+}
+
+// String gets the `config:key` form of r, which identifies the value it points at.
+func (r reference) String() string {
+	return r.config + ":" + r.key
+}
+
+// parseReference parses the body of a reference, which is whatever is between `${` and `}`.
+func parseReference(body string) (reference, error) {
+	var r reference
+	var ok bool
+
+	r.config, r.key, ok = strings.Cut(body, ":")
+	if !ok || r.config == "" {
+		return reference{}, fmt.Errorf("%w: %q isn't in the ${config:key} format", ErrInvalidReference, body)
+	}
+	r.key, r.fallback, r.hasFallback = strings.Cut(r.key, ":-")
+	if _, err := SplitKey(r.key); err != nil {
+		return reference{}, fmt.Errorf("%w: %w", ErrInvalidReference, err)
+	}
+
+	return r, nil
+}
+
+// interpolator interpolates the metadata values of a config, where path holds
+// the values being interpolated down to the current one, and values the ones
+// already interpolated.
+type interpolator struct {
+	lookup    ConfigLookup
+	path      []string
+	values    map[string]string
+	revision  int64
+	updatedAt time.Time
+}
+
+// walk interpolates every string nested in v in place, where v is the value of
+// key in the config identified by name, and it gets v interpolated. Keys are
+// walked in order, so that the same error is reported every time.
+func (in *interpolator) walk(name, key string, v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(v) {
+			value, err := in.walk(name, joinKey(key, k), v[k])
+			if err != nil {
+				return nil, err
+			}
+			v[k] = value
+		}
+	case []any:
+		for n, item := range v {
+			value, err := in.walk(name, joinKey(key, strconv.Itoa(n)), item)
+			if err != nil {
+				return nil, err
+			}
+			v[n] = value
+		}
+	case string:
+		in.path = append(in.path, name+":"+key)
+		value, err := in.interpolate(v)
+		in.path = in.path[:len(in.path)-1]
+		return value, err
+	}
+
+	return v, nil
+}
+
+// interpolate replaces every reference in s by the value it points at.
+func (in *interpolator) interpolate(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		// `$${` escapes a reference, leaving a literal `${` behind.
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: %q isn't closed", ErrInvalidReference, s[start:])
+		}
+		r, err := parseReference(s[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+		value, err := in.resolve(r)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[:start] + value)
+		s = s[start+end+1:]
+	}
+}
+
+// resolve gets the interpolated value r points at.
+func (in *interpolator) resolve(r reference) (string, error) {
+	id := r.String()
+	if value, ok := in.values[id]; ok {
+		return value, nil
+	}
+	if slices.Contains(in.path, id) {
+		cycle := append(slices.Clone(in.path[slices.Index(in.path, id):]), id)
+		return "", fmt.Errorf("%w: %s", ErrReferenceCycle, strings.Join(cycle, " -> "))
+	}
+
+	config, ok := in.lookup(r.config)
+	if !ok {
+		if r.hasFallback {
+			return r.fallback, nil
+		}
+		return "", fmt.Errorf("%w: config %q", ErrReferenceNotFound, r.config)
+	}
+	raw, ok := config.LookupMetadataValue(r.key)
+	if !ok {
+		if r.hasFallback {
+			return r.fallback, nil
+		}
+		return "", fmt.Errorf("%w: key %q of config %q", ErrReferenceNotFound, r.key, r.config)
+	}
+	var s string
+	switch v := raw.(type) {
+	case string:
+		if IsSecret(v) {
+			return "", fmt.Errorf("%w: %q is a secret", ErrInvalidReference, id)
+		}
+		s = v
+	case json.Number:
+		s = v.String()
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return "", fmt.Errorf("%w: %q doesn't hold a string, a number or a boolean", ErrInvalidReference, id)
+	}
+
+	in.revision = max(in.revision, config.Revision)
+	if config.UpdatedAt.After(in.updatedAt) {
+		in.updatedAt = config.UpdatedAt
+	}
+
+	in.path = append(in.path, id)
+	value, err := in.interpolate(s)
+	in.path = in.path[:len(in.path)-1]
+	if err != nil {
+		return "", err
+	}
+	in.values[id] = value
+
+	return value, nil
+}
@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	now := time.Now().UTC()
+
+	infra := domain.Config{
+		Name:      "shared-infra",
+		Metadata:  []byte(`{"db": {"host": "db.internal", "url": "postgres://${shared-infra:db.host}:5432"}, "bucket": "assets", "port": 5432, "tls": true, "zones": ["eu-1", "eu-2"], "owner": null, "password": "encrypted:c2VjcmV0"}`),
+		Revision:  7,
+		UpdatedAt: now,
+	}
+	loopA := domain.Config{Name: "loop-a", Metadata: []byte(`{"value": "${loop-b:value}"}`)}
+	loopB := domain.Config{Name: "loop-b", Metadata: []byte(`{"value": "${loop-a:value}"}`)}
+	lookup := lookupOf(infra, loopA, loopB)
+
+	tests := []struct {
+		name     string
+		metadata string
+		expected string
+		wantErr  error
+	}{
+		{name: "without references", metadata: `{"a": "b"}`, expected: `{"a": "b"}`},
+		{name: "reference", metadata: `{"db": {"host": "${shared-infra:db.host}"}}`, expected: `{"db": {"host": "db.internal"}}`},
+		{name: "references within a value", metadata: `{"url": "s3://${shared-infra:bucket}/${shared-infra:db.host}"}`, expected: `{"url": "s3://assets/db.internal"}`},
+		{name: "references of references", metadata: `{"db": "${shared-infra:db.url}"}`, expected: `{"db": "postgres://db.internal:5432"}`},
+		{name: "default of a missing key", metadata: `{"a": "${shared-infra:nope:-fallback}"}`, expected: `{"a": "fallback"}`},
+		{name: "default of a missing config", metadata: `{"a": "${nope:a.b:-}"}`, expected: `{"a": ""}`},
+		{name: "default of an existing key", metadata: `{"a": "${shared-infra:bucket:-fallback}"}`, expected: `{"a": "assets"}`},
+		{name: "number", metadata: `{"a": "${shared-infra:port}"}`, expected: `{"a": "5432"}`},
+		{name: "boolean", metadata: `{"a": "tls=${shared-infra:tls}"}`, expected: `{"a": "tls=true"}`},
+		{name: "array element", metadata: `{"a": "${shared-infra:zones.1}"}`, expected: `{"a": "eu-2"}`},
+		{name: "within arrays", metadata: `{"a": [1, "${shared-infra:bucket}", ["${shared-infra:zones.0}"]]}`, expected: `{"a": [1, "assets", ["eu-1"]]}`},
+		{name: "escaped reference", metadata: `{"a": "$${shared-infra:bucket}"}`, expected: `{"a": "${shared-infra:bucket}"}`},
+		{name: "missing key", metadata: `{"a": "${shared-infra:nope}"}`, wantErr: domain.ErrReferenceNotFound},
+		{name: "missing config", metadata: `{"a": "${nope:a}"}`, wantErr: domain.ErrReferenceNotFound},
+		{name: "nested metadata", metadata: `{"a": "${shared-infra:db}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "array", metadata: `{"a": "${shared-infra:zones}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "null", metadata: `{"a": "${shared-infra:owner}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "secret", metadata: `{"a": "${shared-infra:password}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "unclosed reference", metadata: `{"a": "${shared-infra:bucket"}`, wantErr: domain.ErrInvalidReference},
+		{name: "reference without a key", metadata: `{"a": "${shared-infra}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "reference with an empty key node", metadata: `{"a": "${shared-infra:db..host}"}`, wantErr: domain.ErrInvalidReference},
+		{name: "cycle", metadata: `{"a": "${loop-a:value}"}`, wantErr: domain.ErrReferenceCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := domain.Config{Name: "app", Metadata: []byte(tt.metadata), Revision: 3}
+
+			interpolated, err := domain.Interpolate(config, lookup)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(interpolated.Metadata))
+		})
+	}
+
+	t.Run("reports the cycle", func(t *testing.T) {
+		_, err := domain.Interpolate(loopA, lookup)
+		assert.EqualError(t, err, "metadata references form a cycle: loop-a:value -> loop-b:value -> loop-a:value")
+	})
+
+	t.Run("references to itself", func(t *testing.T) {
+		app := domain.Config{Name: "app", Metadata: []byte(`{"a": "x", "b": "${app:a}"}`)}
+		interpolated, err := domain.Interpolate(app, lookupOf(app))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": "x", "b": "x"}`, string(interpolated.Metadata))
+
+		app.Metadata = []byte(`{"a": "${app:b}", "b": "${app:a}"}`)
+		_, err = domain.Interpolate(app, lookupOf(app))
+		assert.EqualError(t, err, "metadata references form a cycle: app:a -> app:b -> app:a")
+	})
+
+	t.Run("takes the latest revision", func(t *testing.T) {
+		config := domain.Config{Name: "app", Metadata: []byte(`{"a": "${shared-infra:bucket}"}`), Revision: 3}
+
+		interpolated, err := domain.Interpolate(config, lookup)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), interpolated.Revision)
+		assert.Equal(t, now, interpolated.UpdatedAt)
+		assert.Equal(t, `{"a": "${shared-infra:bucket}"}`, string(config.Metadata), "the raw config is left alone")
+	})
+}
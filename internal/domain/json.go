@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// numberPrecision is the precision in bits numbers are compared with, which
+// is plenty for any integer a 64-bit integer can hold, and then some.
+const numberPrecision = 256
+
+// DecodeJSON is like json.Unmarshal, but it keeps numbers as json.Number,
+// so that the ones a float64 can't hold exactly, such as integers past 2^53,
+// are written back as they were read.
+func DecodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	// just like json.Unmarshal, nothing but whitespace can follow the value.
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+
+	return nil
+}
+
+// jsonEqual tells if a and b, as decoded by DecodeJSON, are the same JSON value,
+// where numbers are the same when they're numerically equal, like 1 and 1.0.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okX := parseNumber(a)
+		y, okY := parseNumber(b)
+		return okX && okY && x.Cmp(y) == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for n := range a {
+			if !jsonEqual(a[n], b[n]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// parseNumber parses n, telling if it's a valid number.
+func parseNumber(n json.Number) (*big.Float, bool) {
+	f, _, err := big.ParseFloat(n.String(), 10, numberPrecision, big.ToNearestEven)
+	return f, err == nil
+}
@@ -0,0 +1,296 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidSchema is used when a schema isn't valid JSON Schema,
+	// or it uses keywords that aren't supported.
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrSchemaViolation is used when metadata doesn't satisfy a schema.
+	ErrSchemaViolation = errors.New("metadata violates schema")
+)
+
+// jsonTypes are the types the type keyword takes.
+var jsonTypes = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// annotations are the keywords that don't take part in validation.
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly"}
+
+// Violation is a way metadata doesn't satisfy a schema.
+type Violation struct {
+	// Path is the metadata key the violation is found at, in the format
+	// MetadataValue takes. It's empty for the whole metadata.
+	Path string `json:"path"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// String gets the violation as the key it's found at followed by the message.
+func (v Violation) String() string {
+	if v.Path == "" {
+		return "metadata " + v.Message
+	}
+	return v.Path + " " + v.Message
+}
+
+// JSONSchema is a compiled JSON Schema, supporting the type, required, enum,
+// pattern, properties, additionalProperties, minLength and maxLength keywords
+// of draft 2020-12. Patterns are RE2 regular expressions.
+type JSONSchema struct {
+	root *schemaNode
+}
+
+// schemaNode is a compiled schema, or subschema, where never is set for the
+// false schema, and additionalProperties is nil when any property is allowed.
+type schemaNode struct {
+	never                bool
+	types                []string
+	required             []string
+	enum                 []any
+	pattern              *regexp.Regexp
+	properties           map[string]*schemaNode
+	additionalProperties *schemaNode
+	minLength, maxLength int
+}
+
+// CompileSchema compiles the JSON Schema in definition, returning
+// ErrInvalidSchema when it can't be.
+func CompileSchema(definition []byte) (*JSONSchema, error) {
+	var v any
+	if err := DecodeJSON(definition, &v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	root, err := compileNode(v, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONSchema{root: root}, nil
+}
+
+// compileNode compiles v, the subschema at the JSON pointer ptr.
+func compileNode(v any, ptr string) (*schemaNode, error) {
+	node := &schemaNode{minLength: -1, maxLength: -1}
+
+	switch v := v.(type) {
+	case bool:
+		node.never = !v
+		return node, nil
+	case map[string]any:
+		for _, keyword := range sortedKeys(v) {
+			if err := node.compileKeyword(keyword, v[keyword], ptr+"/"+keyword); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%w: %s must be an object or a boolean", ErrInvalidSchema, ptr)
+	}
+}
+
+// compileKeyword compiles the keyword of the schema n with value, found at the JSON pointer ptr.
+func (n *schemaNode) compileKeyword(keyword string, value any, ptr string) error {
+	var err error
+	switch keyword {
+	case "type":
+		if s, ok := value.(string); ok {
+			value = []any{s}
+		}
+		if n.types, err = compileStrings(value, ptr); err != nil {
+			return err
+		}
+		for _, t := range n.types {
+			if !slices.Contains(jsonTypes, t) {
+				return fmt.Errorf("%w: %s has the unknown type %q", ErrInvalidSchema, ptr, t)
+			}
+		}
+	case "required":
+		n.required, err = compileStrings(value, ptr)
+	case "enum":
+		values, ok := value.([]any)
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("%w: %s must be a non-empty array", ErrInvalidSchema, ptr)
+		}
+		n.enum = values
+	case "pattern":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s must be a string", ErrInvalidSchema, ptr)
+		}
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidSchema, ptr, err)
+		}
+	case "properties":
+		properties, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s must be an object", ErrInvalidSchema, ptr)
+		}
+		n.properties = make(map[string]*schemaNode, len(properties))
+		for name, property := range properties {
+			if n.properties[name], err = compileNode(property, ptr+"/"+name); err != nil {
+				return err
+			}
+		}
+	case "additionalProperties":
+		n.additionalProperties, err = compileNode(value, ptr)
+	case "minLength":
+		n.minLength, err = compileLength(value, ptr)
+	case "maxLength":
+		n.maxLength, err = compileLength(value, ptr)
+	default:
+		if !slices.Contains(annotations, keyword) {
+			return fmt.Errorf("%w: %s isn't a supported keyword", ErrInvalidSchema, ptr)
+		}
+	}
+
+	return err
+}
+
+// compileStrings compiles value, found at the JSON pointer ptr, into an array of unique strings.
+func compileStrings(value any, ptr string) ([]string, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an array of strings", ErrInvalidSchema, ptr)
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be an array of strings", ErrInvalidSchema, ptr)
+		}
+		if slices.Contains(strs, s) {
+			return nil, fmt.Errorf("%w: %s has %q more than once", ErrInvalidSchema, ptr, s)
+		}
+		strs = append(strs, s)
+	}
+
+	return strs, nil
+}
+
+// compileLength compiles value, found at the JSON pointer ptr, into a length.
+func compileLength(value any, ptr string) (int, error) {
+	n, ok := value.(json.Number)
+	f, err := n.Float64()
+	if !ok || err != nil || f < 0 || f != float64(int(f)) {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer", ErrInvalidSchema, ptr)
+	}
+
+	return int(f), nil
+}
+
+// Validate gets every way metadata doesn't satisfy the schema, which is none
+// when it does. Violations are always reported in the same order.
+func (s *JSONSchema) Validate(metadata []byte) []Violation {
+	var v any
+	if err := DecodeJSON(metadata, &v); err != nil {
+		return []Violation{{Message: fmt.Sprintf("isn't valid JSON: %s", err)}}
+	}
+
+	var violations []Violation
+	s.root.validate(v, "", &violations)
+
+	return violations
+}
+
+// validate appends every way v, found at the metadata key path, doesn't satisfy n to violations.
+func (n *schemaNode) validate(v any, path string, violations *[]Violation) {
+	violate := func(format string, args ...any) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.never {
+		violate("isn't allowed")
+		return
+	}
+
+	if len(n.types) > 0 && !slices.ContainsFunc(n.types, func(t string) bool { return isJSONType(v, t) }) {
+		violate("must be of type %s", strings.Join(n.types, " or "))
+		return
+	}
+
+	if len(n.enum) > 0 && !slices.ContainsFunc(n.enum, func(e any) bool { return jsonEqual(e, v) }) {
+		values := make([]string, len(n.enum))
+		for i, e := range n.enum {
+			b, _ := json.Marshal(e)
+			values[i] = string(b)
+		}
+		violate("must be one of %s", strings.Join(values, ", "))
+	}
+
+	switch v := v.(type) {
+	case string:
+		if length := utf8.RuneCountInString(v); n.minLength >= 0 && length < n.minLength {
+			violate("must be at least %d characters long", n.minLength)
+		} else if n.maxLength >= 0 && length > n.maxLength {
+			violate("must be at most %d characters long", n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			violate("must match the pattern %q", n.pattern.String())
+		}
+	case map[string]any:
+		for _, key := range n.required {
+			if _, ok := v[key]; !ok {
+				*violations = append(*violations, Violation{Path: joinKey(path, key), Message: "is required"})
+			}
+		}
+		for _, key := range sortedKeys(v) {
+			property, ok := n.properties[key]
+			if !ok {
+				property = n.additionalProperties
+			}
+			if property != nil {
+				property.validate(v[key], joinKey(path, key), violations)
+			}
+		}
+	}
+}
+
+// isJSONType tells if v is of the JSON type t.
+func isJSONType(v any, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case json.Number:
+		f, ok := parseNumber(v)
+		return ok && (t == "number" || (t == "integer" && f.IsInt()))
+	case string:
+		return t == "string"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	default:
+		return false
+	}
+}
+
+// joinKey gets the metadata key of the key node nested under path.
+func joinKey(path, node string) string {
+	if path == "" {
+		return node
+	}
+	return path + "." + node
+}
+
+// sortedKeys gets the keys of m, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,122 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCompileSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		wantErr    bool
+	}{
+		{name: "empty", definition: `{}`},
+		{name: "boolean", definition: `false`},
+		{name: "every keyword", definition: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "flags", "type": ["object"], "required": ["a"], "properties": {"a": {"type": "string", "enum": ["x", "y"], "pattern": "^x", "minLength": 1, "maxLength": 2}}, "additionalProperties": false}`},
+		{name: "malformed JSON", definition: `{`, wantErr: true},
+		{name: "not an object", definition: `"string"`, wantErr: true},
+		{name: "unsupported keyword", definition: `{"properties": {"a": {"format": "email"}}}`, wantErr: true},
+		{name: "unknown type", definition: `{"type": "text"}`, wantErr: true},
+		{name: "required that isn't an array of strings", definition: `{"required": "a"}`, wantErr: true},
+		{name: "duplicated required", definition: `{"required": ["a", "a"]}`, wantErr: true},
+		{name: "empty enum", definition: `{"enum": []}`, wantErr: true},
+		{name: "malformed pattern", definition: `{"pattern": "("}`, wantErr: true},
+		{name: "negative length", definition: `{"minLength": -1}`, wantErr: true},
+		{name: "fractional length", definition: `{"maxLength": 1.5}`, wantErr: true},
+		{name: "property that isn't a schema", definition: `{"properties": {"a": 1}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.CompileSchema([]byte(tt.definition))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidSchema)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("points at the invalid keyword", func(t *testing.T) {
+		_, err := domain.CompileSchema([]byte(`{"properties": {"db": {"properties": {"port": {"minimum": 1}}}}}`))
+		assert.EqualError(t, err, "invalid schema: #/properties/db/properties/port/minimum isn't a supported keyword")
+	})
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := domain.CompileSchema([]byte(`{
+		"type": "object",
+		"required": ["enabled"],
+		"properties": {
+			"enabled": {"enum": ["true", "false"]},
+			"owner": {"type": "string", "minLength": 2, "maxLength": 4},
+			"db": {
+				"type": "object",
+				"required": ["host"],
+				"properties": {"port": {"type": "string", "pattern": "^[0-9]+$"}},
+				"additionalProperties": {"type": "string"}
+			}
+		},
+		"additionalProperties": false
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		metadata string
+		expected []string
+	}{
+		{name: "valid", metadata: `{"enabled": "true", "owner": "ops", "db": {"host": "db.internal", "port": "5432"}}`},
+		{name: "typo", metadata: `{"enabeld": "true"}`, expected: []string{"enabled is required", "enabeld isn't allowed"}},
+		{name: "not in enum", metadata: `{"enabled": "yes"}`, expected: []string{`enabled must be one of "true", "false"`}},
+		{name: "too short", metadata: `{"enabled": "true", "owner": "x"}`, expected: []string{"owner must be at least 2 characters long"}},
+		{name: "too long counting characters", metadata: `{"enabled": "true", "owner": "ñññññ"}`, expected: []string{"owner must be at most 4 characters long"}},
+		{name: "wrong type", metadata: `{"enabled": "true", "owner": {"name": "ops"}}`, expected: []string{"owner must be of type string"}},
+		{
+			name:     "nested",
+			metadata: `{"enabled": "true", "db": {"port": "54x", "pool": {"size": "1"}}}`,
+			expected: []string{"db.host is required", "db.pool must be of type string", `db.port must match the pattern "^[0-9]+$"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range schema.Validate([]byte(tt.metadata)) {
+				got = append(got, violation.String())
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("whole metadata", func(t *testing.T) {
+		schema, err := domain.CompileSchema([]byte(`{"type": "string"}`))
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Violation{{Message: "must be of type string"}}, schema.Validate([]byte(`{}`)))
+	})
+}
+
+func TestSchema_Matches(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    domain.Schema
+		namespace string
+		config    string
+		expected  bool
+	}{
+		{name: "name", schema: domain.Schema{Pattern: "payments"}, namespace: "team-a", config: "payments", expected: true},
+		{name: "other name", schema: domain.Schema{Pattern: "payments"}, namespace: "team-a", config: "payments-eu"},
+		{name: "pattern", schema: domain.Schema{Pattern: "payments-*"}, namespace: "team-a", config: "payments-eu", expected: true},
+		{name: "namespace", schema: domain.Schema{Namespace: "team-a", Pattern: "*"}, namespace: "team-a", config: "payments", expected: true},
+		{name: "other namespace", schema: domain.Schema{Namespace: "team-a", Pattern: "*"}, namespace: "team-b", config: "payments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.schema.Matches(tt.namespace, tt.config))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidKey is used when a metadata key isn't in the `aaa.bbb.ccc` format,
+	// such as when it has an empty key node.
+	ErrInvalidKey = errors.New("invalid metadata key")
+	// ErrKeyNotFound is used when a metadata key doesn't match any value.
+	ErrKeyNotFound = errors.New("metadata key not found")
+	// ErrKeyConflict is used when a metadata key can't be set, because
+	// one of its parent key nodes has a value other than nested keys.
+	ErrKeyConflict = errors.New("conflicting metadata key")
+)
+
+// SplitKey breaks down a metadata key in the format MetadataValue takes
+// into its key nodes, returning ErrInvalidKey when any of them is empty.
+func SplitKey(key string) ([]string, error) {
+	nodes := strings.Split(key, ".")
+	for _, node := range nodes {
+		if node == "" {
+			return nil, fmt.Errorf("%w: %q has an empty key node", ErrInvalidKey, key)
+		}
+	}
+
+	return nodes, nil
+}
+
+// SetMetadataValue sets the value of key in metadata, returning the changed
+// metadata. Parent key nodes that don't exist are created along the way,
+// and whatever key already held is replaced. Elements of arrays are set
+// by their index, as long as they already exist.
+func SetMetadataValue(metadata []byte, key string, value any) ([]byte, error) {
+	nodes, err := SplitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := unmarshalMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent any = m
+	for n, node := range nodes {
+		last := n == len(nodes)-1
+
+		switch p := parent.(type) {
+		case map[string]any:
+			if last {
+				p[node] = value
+				break
+			}
+			if p[node] == nil {
+				p[node] = make(map[string]any)
+			}
+			parent = p[node]
+		case []any:
+			i, ok := elementIndex(node, len(p))
+			if !ok {
+				return nil, fmt.Errorf("%w: %q has no element %q, so it can't hold %q", ErrKeyConflict, strings.Join(nodes[:n], "."), node, key)
+			}
+			if last {
+				p[i] = value
+				break
+			}
+			if p[i] == nil {
+				p[i] = make(map[string]any)
+			}
+			parent = p[i]
+		default:
+			return nil, fmt.Errorf("%w: %q has a value, so it can't hold %q", ErrKeyConflict, strings.Join(nodes[:n], "."), key)
+		}
+	}
+
+	return marshalMetadata(m)
+}
+
+// DeleteMetadataValue deletes key from metadata, returning the changed metadata.
+// Parent key nodes left without any key are deleted as well, while deleting
+// an element of an array shifts the ones after it.
+// It returns ErrKeyNotFound if key doesn't match any value.
+func DeleteMetadataValue(metadata []byte, key string) ([]byte, error) {
+	nodes, err := SplitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := unmarshalMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := deleteKey(m, nodes); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+
+	return marshalMetadata(m)
+}
+
+// deleteKey deletes the key made of nodes from value, along with the key nodes
+// it leaves empty, returning the changed value. It tells if the key was found.
+// Elements of arrays left empty are kept, so that the others keep their index.
+func deleteKey(value any, nodes []string) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[nodes[0]]
+		if !ok {
+			return value, false
+		}
+		if len(nodes) == 1 {
+			delete(v, nodes[0])
+			return v, true
+		}
+
+		if child, ok = deleteKey(child, nodes[1:]); !ok {
+			return value, false
+		}
+		if m, isMap := child.(map[string]any); isMap && len(m) == 0 {
+			delete(v, nodes[0])
+		} else {
+			v[nodes[0]] = child
+		}
+		return v, true
+	case []any:
+		n, ok := elementIndex(nodes[0], len(v))
+		if !ok {
+			return value, false
+		}
+		if len(nodes) == 1 {
+			return slices.Delete(v, n, n+1), true
+		}
+
+		if v[n], ok = deleteKey(v[n], nodes[1:]); !ok {
+			return value, false
+		}
+		return v, true
+	default:
+		return value, false
+	}
+}
+
+// elementIndex parses node as the index of an element in an array of length
+// elements, telling if it is one. Indexes are written without signs or leading zeros.
+func elementIndex(node string, length int) (int, bool) {
+	n, err := strconv.Atoi(node)
+	if err != nil || n < 0 || n >= length || strconv.Itoa(n) != node {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// unmarshalMetadata unmarshals metadata into its key value pairs.
+func unmarshalMetadata(metadata []byte) (map[string]any, error) {
+	var m map[string]any
+	if len(metadata) > 0 {
+		if err := DecodeJSON(metadata, &m); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+	if m == nil {
+		m = make(map[string]any)
+	}
+
+	return m, nil
+}
+
+// marshalMetadata marshals the key value pairs of m into metadata.
+func marshalMetadata(m map[string]any) ([]byte, error) {
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return bytes, nil
+}
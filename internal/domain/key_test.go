@@ -0,0 +1,165 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSetMetadataValue(t *testing.T) {
+	metadata := []byte(`{"foo": "bar", "obj": {"aaa": "bbb"}}`)
+
+	tests := []struct {
+		name    string
+		key     string
+		value   any
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "new key",
+			key:   "abc",
+			value: "123",
+			want:  `{"foo": "bar", "abc": "123", "obj": {"aaa": "bbb"}}`,
+		},
+		{
+			name:  "existing key",
+			key:   "obj.aaa",
+			value: "ccc",
+			want:  `{"foo": "bar", "obj": {"aaa": "ccc"}}`,
+		},
+		{
+			name:  "creates parent key nodes",
+			key:   "obj.ddd.eee.fff",
+			value: "ggg",
+			want:  `{"foo": "bar", "obj": {"aaa": "bbb", "ddd": {"eee": {"fff": "ggg"}}}}`,
+		},
+		{
+			name:  "replaces a subtree",
+			key:   "obj",
+			value: map[string]any{"hhh": "iii"},
+			want:  `{"foo": "bar", "obj": {"hhh": "iii"}}`,
+		},
+		{
+			name:    "under a value",
+			key:     "foo.baz",
+			value:   "qux",
+			wantErr: domain.ErrKeyConflict,
+		},
+		{
+			name:    "empty key node",
+			key:     "obj..aaa",
+			value:   "qux",
+			wantErr: domain.ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.SetMetadataValue(metadata, tt.key, tt.value)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("array elements", func(t *testing.T) {
+		metadata := []byte(`{"hosts": ["eu-1", {"name": "us-1"}]}`)
+
+		got, err := domain.SetMetadataValue(metadata, "hosts.0", 8080.0)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"hosts": [8080, {"name": "us-1"}]}`, string(got))
+
+		got, err = domain.SetMetadataValue(metadata, "hosts.1.port", true)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"hosts": ["eu-1", {"name": "us-1", "port": true}]}`, string(got))
+
+		t.Run("out of range", func(t *testing.T) {
+			_, err := domain.SetMetadataValue(metadata, "hosts.2", "ap-1")
+			assert.ErrorIs(t, err, domain.ErrKeyConflict)
+		})
+	})
+}
+
+func TestDeleteMetadataValue(t *testing.T) {
+	metadata := []byte(`{"foo": "bar", "obj": {"aaa": {"bbb": {"ccc": "ddd"}}, "eee": "fff"}}`)
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "key",
+			key:  "foo",
+			want: `{"obj": {"aaa": {"bbb": {"ccc": "ddd"}}, "eee": "fff"}}`,
+		},
+		{
+			name: "subtree",
+			key:  "obj.aaa",
+			want: `{"foo": "bar", "obj": {"eee": "fff"}}`,
+		},
+		{
+			name: "prunes the parent key nodes left empty",
+			key:  "obj.aaa.bbb.ccc",
+			want: `{"foo": "bar", "obj": {"eee": "fff"}}`,
+		},
+		{
+			name:    "missing key",
+			key:     "obj.zzz",
+			wantErr: domain.ErrKeyNotFound,
+		},
+		{
+			name:    "under a value",
+			key:     "foo.bar",
+			wantErr: domain.ErrKeyNotFound,
+		},
+		{
+			name:    "empty key node",
+			key:     "obj.",
+			wantErr: domain.ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.DeleteMetadataValue(metadata, tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("keeps the parent key nodes that have other keys", func(t *testing.T) {
+		got, err := domain.DeleteMetadataValue([]byte(`{"obj": {"aaa": "bbb", "ccc": "ddd"}}`), "obj.aaa")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"obj": {"ccc": "ddd"}}`, string(got))
+	})
+
+	t.Run("array elements", func(t *testing.T) {
+		metadata := []byte(`{"hosts": ["eu-1", {"name": "us-1"}]}`)
+
+		got, err := domain.DeleteMetadataValue(metadata, "hosts.0")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"hosts": [{"name": "us-1"}]}`, string(got))
+
+		t.Run("keeps the elements left empty", func(t *testing.T) {
+			got, err := domain.DeleteMetadataValue(metadata, "hosts.1.name")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"hosts": ["eu-1", {}]}`, string(got))
+		})
+
+		t.Run("out of range", func(t *testing.T) {
+			_, err := domain.DeleteMetadataValue(metadata, "hosts.2")
+			assert.ErrorIs(t, err, domain.ErrKeyNotFound)
+		})
+	})
+}
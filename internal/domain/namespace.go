@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// DefaultNamespace is the namespace of the configs that aren't
+// explicitly placed in any other namespace.
+const DefaultNamespace = "default"
+
+// Namespace isolates the configs it holds from the ones in other namespaces,
+// so that different teams can use the same config names without colliding.
+type Namespace struct {
+	// Name is the name of the namespace.
+	Name string `json:"name"`
+	// CreatedAt is the time when the namespace was created.
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,368 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch is used when a patch can't be applied to the metadata.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPatchTestFailed is used when a test operation of a JSON Patch
+	// doesn't hold, which aborts the whole patch.
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+)
+
+// MergePatch applies the JSON Merge Patch (RFC 7396) document in patch
+// to metadata, returning the patched metadata.
+//
+// Every key in patch replaces the corresponding key in metadata, nested objects
+// are merged recursively, and keys set to null are deleted.
+func MergePatch(metadata []byte, patch []byte) ([]byte, error) {
+	var target any
+	if len(metadata) > 0 {
+		if err := DecodeJSON(metadata, &target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	var patchDocument any
+	if err := DecodeJSON(patch, &patchDocument); err != nil {
+		return nil, errors.Join(ErrInvalidPatch, err)
+	}
+
+	// metadata is always a set of key/value pairs, so it can't be
+	// replaced by anything else as a whole.
+	patched, ok := mergePatch(target, patchDocument).(map[string]any)
+	if !ok {
+		return nil, errors.Join(ErrInvalidPatch, errors.New("merge patch must be an object"))
+	}
+
+	bytes, err := json.Marshal(patched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return bytes, nil
+}
+
+// mergePatch merges patch into target as described by RFC 7396.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		// anything other than an object replaces the target.
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for k, v := range patchObject {
+		if v == nil {
+			delete(targetObject, k)
+			continue
+		}
+		targetObject[k] = mergePatch(targetObject[k], v)
+	}
+
+	return targetObject
+}
+
+// patchOperation is a single operation of a JSON Patch document.
+type patchOperation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From string `json:"from"`
+	// Value is kept raw to tell a missing value apart from a null one.
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the JSON Patch (RFC 6902) document in patch to metadata,
+// returning the patched metadata.
+//
+// The operations are applied in order, and if any of them fails, including
+// a test operation that doesn't hold, the whole patch is aborted.
+func JSONPatch(metadata []byte, patch []byte) ([]byte, error) {
+	var operations []patchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, errors.Join(ErrInvalidPatch, err)
+	}
+
+	var doc any = make(map[string]any)
+	if len(metadata) > 0 {
+		if err := DecodeJSON(metadata, &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	for n, operation := range operations {
+		var err error
+		doc, err = operation.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", n, operation.Op, operation.Path, err)
+		}
+	}
+
+	// metadata is always a set of key/value pairs, so it can't be
+	// replaced by anything else as a whole.
+	if _, ok := doc.(map[string]any); !ok {
+		return nil, errors.Join(ErrInvalidPatch, errors.New("patched metadata must be an object"))
+	}
+
+	bytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return bytes, nil
+}
+
+// apply applies the operation to doc, returning the resulting document.
+func (o patchOperation) apply(doc any) (any, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return nil, errors.Join(ErrInvalidPatch, errors.New("value is required"))
+		}
+		var value any
+		if err := DecodeJSON(o.Value, &value); err != nil {
+			return nil, errors.Join(ErrInvalidPatch, err)
+		}
+
+		switch o.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			doc, _, err = removeValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		default:
+			current, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, ErrPatchTestFailed
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(o.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any
+		if o.Op == "move" {
+			// a value can't be moved into one of its own children.
+			if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+				return nil, errors.Join(ErrInvalidPatch, errors.New("can't move a value into itself"))
+			}
+			doc, value, err = removeValue(doc, from)
+		} else {
+			value, err = getValue(doc, from)
+			if err == nil {
+				value, err = deepCopy(value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return addValue(doc, path, value)
+	default:
+		return nil, errors.Join(ErrInvalidPatch, fmt.Errorf("unknown operation %q", o.Op))
+	}
+}
+
+// parsePointer breaks a JSON Pointer (RFC 6901) down into its reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.Join(ErrInvalidPatch, fmt.Errorf("path %q must start with a slash", pointer))
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for n, token := range tokens {
+		tokens[n] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// getValue gets the value in doc at path.
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch t := doc.(type) {
+		case map[string]any:
+			value, ok := t[token]
+			if !ok {
+				return nil, pathNotFoundError(token)
+			}
+			doc = value
+		case []any:
+			index, err := arrayIndex(token, len(t)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = t[index]
+		default:
+			return nil, pathNotFoundError(token)
+		}
+	}
+
+	return doc, nil
+}
+
+// addValue sets value in doc at path, inserting it when the parent is an array.
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch t := parent.(type) {
+		case map[string]any:
+			t[token] = value
+			return t, nil
+		case []any:
+			if token == "-" {
+				return append(t, value), nil
+			}
+			index, err := arrayIndex(token, len(t))
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, nil)
+			copy(t[index+1:], t[index:])
+			t[index] = value
+			return t, nil
+		default:
+			return nil, pathNotFoundError(token)
+		}
+	})
+}
+
+// removeValue removes the value in doc at path, returning it
+// along with the resulting document.
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.Join(ErrInvalidPatch, errors.New("can't remove the whole metadata"))
+	}
+
+	var removed any
+	doc, err := updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch t := parent.(type) {
+		case map[string]any:
+			value, ok := t[token]
+			if !ok {
+				return nil, pathNotFoundError(token)
+			}
+			removed = value
+			delete(t, token)
+			return t, nil
+		case []any:
+			index, err := arrayIndex(token, len(t)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = t[index]
+			return append(t[:index], t[index+1:]...), nil
+		default:
+			return nil, pathNotFoundError(token)
+		}
+	})
+
+	return doc, removed, err
+}
+
+// updateParent walks doc down to the parent of the value at path and replaces
+// it with the outcome of update, which gets the last token of path.
+func updateParent(doc any, path []string, update func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+
+	switch t := doc.(type) {
+	case map[string]any:
+		child, ok := t[path[0]]
+		if !ok {
+			return nil, pathNotFoundError(path[0])
+		}
+		updated, err := updateParent(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		t[path[0]] = updated
+		return t, nil
+	case []any:
+		index, err := arrayIndex(path[0], len(t)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateParent(t[index], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		t[index] = updated
+		return t, nil
+	default:
+		return nil, pathNotFoundError(path[0])
+	}
+}
+
+// arrayIndex parses token as an index of an array, up to max.
+func arrayIndex(token string, max int) (int, error) {
+	// leading zeros aren't allowed by RFC 6901.
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.Join(ErrInvalidPatch, fmt.Errorf("invalid array index %q", token))
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, errors.Join(ErrInvalidPatch, fmt.Errorf("array index %q is out of bounds", token))
+	}
+
+	return index, nil
+}
+
+// pathNotFoundError reports token as missing from the metadata.
+func pathNotFoundError(token string) error {
+	return errors.Join(ErrInvalidPatch, fmt.Errorf("path not found at %q", token))
+}
+
+// deepCopy copies value, so that it's not shared across the document.
+func deepCopy(value any) (any, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var c any
+	if err := DecodeJSON(bytes, &c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,218 @@
+package domain_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	metadata := []byte(`
+		{
+			"enabled": "true",
+			"abc": "123",
+			"obj": {
+				"aaa": {
+					"bbb": "ccc",
+					"ddd": "eee"
+				}
+			}
+		}`)
+
+	tests := []struct {
+		name         string
+		patch        string
+		wantMetadata string
+		wantErr      error
+	}{
+		{
+			name:         "key is replaced",
+			patch:        `{"abc": "456"}`,
+			wantMetadata: `{"enabled": "true", "abc": "456", "obj": {"aaa": {"bbb": "ccc", "ddd": "eee"}}}`,
+		},
+		{
+			name:         "key is added",
+			patch:        `{"new": "key"}`,
+			wantMetadata: `{"enabled": "true", "abc": "123", "new": "key", "obj": {"aaa": {"bbb": "ccc", "ddd": "eee"}}}`,
+		},
+		{
+			name:         "null deletes a key",
+			patch:        `{"enabled": null}`,
+			wantMetadata: `{"abc": "123", "obj": {"aaa": {"bbb": "ccc", "ddd": "eee"}}}`,
+		},
+		{
+			name:         "nested objects are merged",
+			patch:        `{"obj": {"aaa": {"bbb": "updated", "ddd": null}}}`,
+			wantMetadata: `{"enabled": "true", "abc": "123", "obj": {"aaa": {"bbb": "updated"}}}`,
+		},
+		{
+			name:         "object replaces a value",
+			patch:        `{"abc": {"nested": "value"}}`,
+			wantMetadata: `{"enabled": "true", "abc": {"nested": "value"}, "obj": {"aaa": {"bbb": "ccc", "ddd": "eee"}}}`,
+		},
+		{
+			name:    "non-object patch",
+			patch:   `"replace everything"`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "malformed patch",
+			patch:   `{"abc":`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.MergePatch(metadata, []byte(tt.patch))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantMetadata, string(got))
+		})
+	}
+
+	t.Run("large integers are kept", func(t *testing.T) {
+		got, err := domain.MergePatch([]byte(`{"id": 9007199254740993}`), []byte(`{"other": 9007199254740995}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":9007199254740993,"other":9007199254740995}`, string(got))
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	metadata := []byte(`
+		{
+			"enabled": "true",
+			"a/b": "slash",
+			"obj": {
+				"aaa": {
+					"bbb": "ccc"
+				}
+			},
+			"list": ["x", "y"]
+		}`)
+
+	tests := []struct {
+		name         string
+		patch        string
+		wantMetadata string
+		wantErr      error
+	}{
+		{
+			name:         "add a nested key",
+			patch:        `[{"op": "add", "path": "/obj/aaa/ddd", "value": "eee"}]`,
+			wantMetadata: `{"enabled": "true", "a/b": "slash", "obj": {"aaa": {"bbb": "ccc", "ddd": "eee"}}, "list": ["x", "y"]}`,
+		},
+		{
+			name:         "add to an array",
+			patch:        `[{"op": "add", "path": "/list/1", "value": "z"}, {"op": "add", "path": "/list/-", "value": "w"}]`,
+			wantMetadata: `{"enabled": "true", "a/b": "slash", "obj": {"aaa": {"bbb": "ccc"}}, "list": ["x", "z", "y", "w"]}`,
+		},
+		{
+			name:         "remove an escaped key",
+			patch:        `[{"op": "remove", "path": "/a~1b"}]`,
+			wantMetadata: `{"enabled": "true", "obj": {"aaa": {"bbb": "ccc"}}, "list": ["x", "y"]}`,
+		},
+		{
+			name:         "replace a value",
+			patch:        `[{"op": "replace", "path": "/enabled", "value": "false"}]`,
+			wantMetadata: `{"enabled": "false", "a/b": "slash", "obj": {"aaa": {"bbb": "ccc"}}, "list": ["x", "y"]}`,
+		},
+		{
+			name:         "move a subtree",
+			patch:        `[{"op": "move", "from": "/obj/aaa", "path": "/moved"}]`,
+			wantMetadata: `{"enabled": "true", "a/b": "slash", "obj": {}, "moved": {"bbb": "ccc"}, "list": ["x", "y"]}`,
+		},
+		{
+			name:         "copy a subtree",
+			patch:        `[{"op": "copy", "from": "/obj", "path": "/copied"}]`,
+			wantMetadata: `{"enabled": "true", "a/b": "slash", "obj": {"aaa": {"bbb": "ccc"}}, "copied": {"aaa": {"bbb": "ccc"}}, "list": ["x", "y"]}`,
+		},
+		{
+			name:         "passing test lets the patch through",
+			patch:        `[{"op": "test", "path": "/obj/aaa/bbb", "value": "ccc"}, {"op": "remove", "path": "/list"}]`,
+			wantMetadata: `{"enabled": "true", "a/b": "slash", "obj": {"aaa": {"bbb": "ccc"}}}`,
+		},
+		{
+			name:    "failing test aborts the patch",
+			patch:   `[{"op": "remove", "path": "/list"}, {"op": "test", "path": "/enabled", "value": "false"}]`,
+			wantErr: domain.ErrPatchTestFailed,
+		},
+		{
+			name:    "removing a missing path",
+			patch:   `[{"op": "remove", "path": "/nope"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "adding under a missing parent",
+			patch:   `[{"op": "add", "path": "/nope/key", "value": "value"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "moving a value into itself",
+			patch:   `[{"op": "move", "from": "/obj", "path": "/obj/aaa/inner"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "missing value",
+			patch:   `[{"op": "add", "path": "/key"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "unknown operation",
+			patch:   `[{"op": "upsert", "path": "/key", "value": "value"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "path without a leading slash",
+			patch:   `[{"op": "remove", "path": "enabled"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "replacing the whole metadata with a non-object",
+			patch:   `[{"op": "replace", "path": "", "value": "nope"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "patch isn't an array of operations",
+			patch:   `{"op": "remove", "path": "/enabled"}`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.JSONPatch(metadata, []byte(tt.patch))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantMetadata, string(got))
+		})
+	}
+
+	t.Run("null value is set", func(t *testing.T) {
+		got, err := domain.JSONPatch([]byte(`{}`), []byte(`[{"op": "add", "path": "/key", "value": null}]`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"key": null}`, string(got))
+	})
+	t.Run("large integers are kept", func(t *testing.T) {
+		got, err := domain.JSONPatch([]byte(`{"id": 9007199254740993}`), []byte(`[{"op": "copy", "from": "/id", "path": "/copied"}]`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"copied":9007199254740993,"id":9007199254740993}`, string(got))
+	})
+
+	t.Run("numbers are tested by their value", func(t *testing.T) {
+		_, err := domain.JSONPatch([]byte(`{"port": 8080}`), []byte(`[{"op": "test", "path": "/port", "value": 8080.0}]`))
+		require.NoError(t, err)
+
+		_, err = domain.JSONPatch([]byte(`{"id": 9007199254740993}`), []byte(`[{"op": "test", "path": "/id", "value": 9007199254740992}]`))
+		assert.ErrorIs(t, err, domain.ErrPatchTestFailed)
+	})
+}
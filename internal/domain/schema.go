@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"path"
+	"time"
+)
+
+// Schema is a JSON Schema the metadata of the configs it's attached to must satisfy.
+type Schema struct {
+	// Name identifies the schema.
+	Name string `json:"name"`
+	// Namespace is the namespace of the configs the schema is attached to,
+	// where an empty one matches every namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Pattern is the pattern the names of the configs the schema is attached
+	// to match, as path.Match takes it, so that a plain name only matches
+	// the config with that name.
+	Pattern string `json:"pattern"`
+	// Definition is the JSON Schema, as CompileSchema takes it.
+	Definition []byte `json:"definition"`
+	// CreatedAt is the time when the schema was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time when the schema was last changed.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Matches tells if the schema is attached to the config identified by name in namespace.
+func (s Schema) Matches(namespace, name string) bool {
+	if s.Namespace != "" && s.Namespace != namespace {
+		return false
+	}
+	matched, _ := path.Match(s.Pattern, name)
+
+	return matched
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// SecretPrefix marks a metadata value as secret, as it's written by clients
+	// and as it's revealed to them.
+	SecretPrefix = "secret:"
+	// EncryptedPrefix marks a secret metadata value as it's kept at rest,
+	// followed by its ciphertext.
+	EncryptedPrefix = "encrypted:"
+	// RedactedSecret is what secret metadata values are replaced by
+	// when they aren't revealed.
+	RedactedSecret = SecretPrefix + "******"
+)
+
+var (
+	// ErrInvalidSecret is used when a redacted secret value is written back,
+	// which would replace the secret by the placeholder standing for it, or when
+	// a value is written as if it were sealed already.
+	ErrInvalidSecret = errors.New("invalid secret value")
+	// ErrSecretsDisabled is used when a secret value is written or revealed
+	// without an encryption key to do it with.
+	ErrSecretsDisabled = errors.New("secrets aren't enabled")
+)
+
+// IsSecret tells if value is a secret metadata value, as it's kept at rest.
+func IsSecret(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, EncryptedPrefix)
+}
+
+// SealSecrets gets metadata with every value marked with SecretPrefix replaced by
+// its ciphertext, as encrypt gets it, marked with EncryptedPrefix. Values marked
+// with EncryptedPrefix are only taken as sealed already when sealed tells so of
+// their ciphertext, since clients don't ever get to write ciphertexts of their own.
+func SealSecrets(metadata []byte, encrypt func(plaintext string) (string, error), sealed func(ciphertext string) bool) ([]byte, error) {
+	return replaceSecrets(metadata, []string{SecretPrefix, EncryptedPrefix}, func(value string) (string, error) {
+		if ciphertext, found := strings.CutPrefix(value, EncryptedPrefix); found {
+			if sealed == nil || !sealed(ciphertext) {
+				return "", fmt.Errorf("%w: values starting with %q can't be written", ErrInvalidSecret, EncryptedPrefix)
+			}
+			return value, nil
+		}
+
+		plaintext, found := strings.CutPrefix(value, SecretPrefix)
+		if !found {
+			return value, nil
+		}
+		if value == RedactedSecret {
+			return "", fmt.Errorf("%w: %q stands for a redacted secret", ErrInvalidSecret, RedactedSecret)
+		}
+
+		ciphertext, err := encrypt(plaintext)
+		if err != nil {
+			return "", err
+		}
+
+		return EncryptedPrefix + ciphertext, nil
+	})
+}
+
+// RevealSecrets gets metadata with every secret value replaced by its
+// plaintext, as decrypt gets it, marked with SecretPrefix, so that it's
+// sealed again when it's written back.
+func RevealSecrets(metadata []byte, decrypt func(ciphertext string) (string, error)) ([]byte, error) {
+	return replaceSecrets(metadata, []string{EncryptedPrefix}, func(value string) (string, error) {
+		ciphertext, found := strings.CutPrefix(value, EncryptedPrefix)
+		if !found {
+			return value, nil
+		}
+
+		plaintext, err := decrypt(ciphertext)
+		if err != nil {
+			return "", err
+		}
+
+		return SecretPrefix + plaintext, nil
+	})
+}
+
+// PlainSecrets gets metadata with every secret value replaced by its bare plaintext,
+// whether it's marked with SecretPrefix or sealed, as decrypt gets it, so that
+// it's validated as what it stands for.
+func PlainSecrets(metadata []byte, decrypt func(ciphertext string) (string, error)) ([]byte, error) {
+	return replaceSecrets(metadata, []string{SecretPrefix, EncryptedPrefix}, func(value string) (string, error) {
+		if ciphertext, found := strings.CutPrefix(value, EncryptedPrefix); found {
+			return decrypt(ciphertext)
+		}
+		if plaintext, found := strings.CutPrefix(value, SecretPrefix); found {
+			return plaintext, nil
+		}
+
+		return value, nil
+	})
+}
+
+// RedactSecrets gets metadata with every secret value replaced by RedactedSecret.
+func RedactSecrets(metadata []byte) ([]byte, error) {
+	return replaceSecrets(metadata, []string{EncryptedPrefix}, func(value string) (string, error) {
+		if !IsSecret(value) {
+			return value, nil
+		}
+
+		return RedactedSecret, nil
+	})
+}
+
+// Ciphertexts gets the ciphertexts of the secret values in metadata.
+func Ciphertexts(metadata []byte) []string {
+	var ciphertexts []string
+	_, _ = replaceSecrets(metadata, []string{EncryptedPrefix}, func(value string) (string, error) {
+		if ciphertext, found := strings.CutPrefix(value, EncryptedPrefix); found {
+			ciphertexts = append(ciphertexts, ciphertext)
+		}
+		return value, nil
+	})
+
+	return ciphertexts
+}
+
+// WithoutSecrets gets metadata without its secret values, which are left out of
+// whatever matches metadata values, such as searches. Secret elements of arrays
+// are left out of them as well.
+func WithoutSecrets(metadata []byte) []byte {
+	if !holdsPrefixed(metadata, EncryptedPrefix) {
+		return metadata
+	}
+
+	m, err := unmarshalMetadata(metadata)
+	if err != nil {
+		return metadata
+	}
+	stripped, err := marshalMetadata(dropSecrets(m).(map[string]any))
+	if err != nil {
+		return metadata
+	}
+
+	return stripped
+}
+
+// replaceSecrets gets metadata with every string value replaced by what replace
+// gets out of it. Metadata without any string starting with one of prefixes
+// is returned as it is.
+func replaceSecrets(metadata []byte, prefixes []string, replace func(string) (string, error)) ([]byte, error) {
+	if !slices.ContainsFunc(prefixes, func(prefix string) bool { return holdsPrefixed(metadata, prefix) }) {
+		return metadata, nil
+	}
+
+	m, err := unmarshalMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := replaceStrings(m, replace); err != nil {
+		return nil, err
+	}
+
+	return marshalMetadata(m)
+}
+
+// replaceStrings gets v with every string nested in it replaced,
+// in place, by what replace gets out of it.
+func replaceStrings(v any, replace func(string) (string, error)) (any, error) {
+	var err error
+	switch t := v.(type) {
+	case string:
+		return replace(t)
+	case map[string]any:
+		for k, value := range t {
+			if t[k], err = replaceStrings(value, replace); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for n, value := range t {
+			if t[n], err = replaceStrings(value, replace); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// dropSecrets gets v without the secret values nested in it.
+func dropSecrets(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, value := range t {
+			if IsSecret(value) {
+				delete(t, k)
+				continue
+			}
+			t[k] = dropSecrets(value)
+		}
+	case []any:
+		t = slices.DeleteFunc(t, IsSecret)
+		for n, value := range t {
+			t[n] = dropSecrets(value)
+		}
+		return t
+	}
+
+	return v
+}
+
+// holdsPrefixed tells if metadata may hold a string starting with prefix,
+// so that metadata without any doesn't need to be parsed.
+func holdsPrefixed(metadata []byte, prefix string) bool {
+	return bytes.Contains(metadata, []byte(`"`+prefix))
+}
@@ -0,0 +1,112 @@
+package domain_test
+
+import (
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// reverse stands in for encryption, reversing plaintext.
+func reverse(s string) (string, error) {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes), nil
+}
+
+func TestSealSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		expected string
+		wantErr  error
+	}{
+		{name: "without secrets", metadata: `{"a": "b"}`, expected: `{"a": "b"}`},
+		{name: "secret", metadata: `{"a": "secret:abc"}`, expected: `{"a":"encrypted:cba"}`},
+		{name: "nested secrets", metadata: `{"a": {"b": ["secret:abc", 1]}}`, expected: `{"a":{"b":["encrypted:cba",1]}}`},
+		{name: "sealed secret", metadata: `{"a": "encrypted:cba"}`, expected: `{"a":"encrypted:cba"}`},
+		{name: "unknown sealed secret", metadata: `{"a": "encrypted:fed"}`, wantErr: domain.ErrInvalidSecret},
+		{name: "prefix within a value", metadata: `{"a": "not a secret:abc"}`, expected: `{"a": "not a secret:abc"}`},
+		{name: "redacted secret", metadata: `{"a": "secret:******"}`, wantErr: domain.ErrInvalidSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := domain.SealSecrets([]byte(tt.metadata), reverse, func(ciphertext string) bool {
+				return ciphertext == "cba"
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(sealed))
+		})
+	}
+
+	t.Run("encryption failure", func(t *testing.T) {
+		_, err := domain.SealSecrets([]byte(`{"a": "secret:abc"}`), func(string) (string, error) {
+			return "", domain.ErrSecretsDisabled
+		}, nil)
+		assert.ErrorIs(t, err, domain.ErrSecretsDisabled)
+	})
+
+	t.Run("ciphertexts aren't taken without sealed", func(t *testing.T) {
+		_, err := domain.SealSecrets([]byte(`{"a": "encrypted:cba"}`), reverse, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+	})
+}
+
+func TestCiphertexts(t *testing.T) {
+	ciphertexts := domain.Ciphertexts([]byte(`{"a": "encrypted:cba", "b": {"c": ["encrypted:fed", "x"]}, "d": "secret:abc"}`))
+	assert.ElementsMatch(t, []string{"cba", "fed"}, ciphertexts)
+	assert.Empty(t, domain.Ciphertexts(nil))
+}
+
+func TestRevealSecrets(t *testing.T) {
+	revealed, err := domain.RevealSecrets([]byte(`{"a": "encrypted:cba", "b": ["encrypted:fed"], "c": "secret"}`), reverse)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": "secret:abc", "b": ["secret:def"], "c": "secret"}`, string(revealed))
+
+	t.Run("decryption failure", func(t *testing.T) {
+		_, err := domain.RevealSecrets([]byte(`{"a": "encrypted:cba"}`), func(string) (string, error) {
+			return "", errors.New("boom")
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestPlainSecrets(t *testing.T) {
+	plain, err := domain.PlainSecrets([]byte(`{"a": "encrypted:cba", "b": ["secret:def"], "c": "secret"}`), reverse)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": "abc", "b": ["def"], "c": "secret"}`, string(plain))
+}
+
+func TestRedactSecrets(t *testing.T) {
+	redacted, err := domain.RedactSecrets([]byte(`{"a": "encrypted:cba", "b": {"c": ["encrypted:fed", "x"]}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": "secret:******", "b": {"c": ["secret:******", "x"]}}`, string(redacted))
+	assert.NotContains(t, string(redacted), "cba")
+}
+
+func TestWithoutSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		expected string
+	}{
+		{name: "without secrets", metadata: `{"a": "b"}`, expected: `{"a": "b"}`},
+		{name: "secret", metadata: `{"a": "encrypted:cba", "b": "c"}`, expected: `{"b": "c"}`},
+		{name: "nested secrets", metadata: `{"a": {"b": "encrypted:cba", "c": ["x", "encrypted:fed", "y"]}}`, expected: `{"a": {"c": ["x", "y"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, string(domain.WithoutSecrets([]byte(tt.metadata))))
+		})
+	}
+}
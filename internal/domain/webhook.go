@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// Webhook is a subscription to be told over HTTP about the changes made to configs.
+type Webhook struct {
+	// ID identifies the webhook.
+	ID string `json:"id"`
+	// URL is where the events are posted to.
+	URL string `json:"url"`
+	// Namespace filters the events by the namespace of the config,
+	// where an empty one matches every namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Prefix filters the events by the prefix of the config name.
+	Prefix string `json:"prefix,omitempty"`
+	// Query filters the events by an expression of the query language
+	// the metadata of the config must match.
+	Query string `json:"query,omitempty"`
+	// Secret is the shared secret the events are signed with.
+	Secret string `json:"secret"`
+	// CreatedAt is the time when the webhook was created.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DeliveryStatus is the state a delivery is in.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is the status of the deliveries still being attempted.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded is the status of the deliveries the receiver accepted.
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed is the status of the deliveries that ran out of attempts.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is an event being posted to a webhook.
+type Delivery struct {
+	// ID identifies the delivery.
+	ID string `json:"id"`
+	// WebhookID identifies the webhook the event is posted to.
+	WebhookID string `json:"webhookId"`
+	// Event is the event being posted.
+	Event Event `json:"event"`
+	// Status is the state the delivery is in.
+	Status DeliveryStatus `json:"status"`
+	// Attempts is the number of times the event has been posted.
+	Attempts int `json:"attempts"`
+	// ResponseStatus is the HTTP status of the response to the last attempt,
+	// which is 0 when there was no response at all.
+	ResponseStatus int `json:"responseStatus,omitempty"`
+	// Error describes why the last attempt failed.
+	Error string `json:"error,omitempty"`
+	// CreatedAt is the time when the delivery was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time of the last attempt.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a token of a query expression.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token is a lexical token of a query expression.
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the byte offset of the token in the input.
+	pos int
+}
+
+// String describes the token for error messages.
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// symbolChars are the characters symbols are made of.
+const symbolChars = "=!<>~&|"
+
+// lexer breaks a query expression down into tokens.
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next scans the next token.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	switch c := l.input[l.pos]; {
+	case c == '(':
+		l.pos++
+		return token{kind: tokenLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokenRParen, text: ")", pos: start}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokenComma, text: ",", pos: start}, nil
+	case c == '"':
+		return l.scanString()
+	case strings.IndexByte(symbolChars, c) >= 0:
+		for l.pos < len(l.input) && strings.IndexByte(symbolChars, l.input[l.pos]) >= 0 {
+			l.pos++
+		}
+		return token{kind: tokenSymbol, text: l.input[start:l.pos], pos: start}, nil
+	default:
+		for l.pos < len(l.input) && isWordChar(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenWord, text: l.input[start:l.pos], pos: start}, nil
+	}
+}
+
+// scanString scans a double-quoted string, where a backslash
+// escapes a quote or another backslash following it.
+func (l *lexer) scanString() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		switch r {
+		case '"':
+			l.pos += size
+			return token{kind: tokenString, text: b.String(), pos: start}, nil
+		case '\\':
+			// only quotes and backslashes are escaped, so that
+			// regular expressions can be written as they are.
+			if l.pos+size < len(l.input) && (l.input[l.pos+size] == '"' || l.input[l.pos+size] == '\\') {
+				l.pos += size
+				r, size = utf8.DecodeRuneInString(l.input[l.pos:])
+			}
+		}
+		b.WriteRune(r)
+		l.pos += size
+	}
+
+	return token{}, &SyntaxError{Input: l.input, Pos: start + 1, Msg: "unterminated string"}
+}
+
+// isSpace tells if c separates tokens.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isWordChar tells if c can be part of a word, which is either
+// a path, an operator name, a keyword or an unquoted value.
+func isWordChar(c byte) bool {
+	return !isSpace(c) && c != '(' && c != ')' && c != ',' && c != '"' && strings.IndexByte(symbolChars, c) < 0
+}
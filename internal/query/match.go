@@ -0,0 +1,238 @@
+package query
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Match tells if metadata, as a JSON object, matches expr.
+// A nil expr matches any metadata.
+func Match(expr Expr, metadata []byte) bool {
+	if expr == nil {
+		return true
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(metadata, &m); err != nil {
+		return false
+	}
+
+	return match(expr, m)
+}
+
+// match tells if metadata matches expr.
+func match(expr Expr, metadata map[string]any) bool {
+	switch e := expr.(type) {
+	case And:
+		for _, expr := range e.Exprs {
+			if !match(expr, metadata) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, expr := range e.Exprs {
+			if match(expr, metadata) {
+				return true
+			}
+		}
+		return false
+	case *Condition:
+		return matchCondition(e, metadata)
+	default:
+		return false
+	}
+}
+
+// matchCondition tells if the value in metadata at the path of c satisfies it.
+func matchCondition(c *Condition, metadata map[string]any) bool {
+	value, found := lookupPath(metadata, c.Path)
+
+	switch c.Op {
+	case Exists:
+		return found
+	case Missing:
+		return !found
+	case Ne:
+		return !found || !matchValue(c, Eq, value)
+	default:
+		return found && matchValue(c, c.Op, value)
+	}
+}
+
+// matchValue compares value with the operands of c using op,
+// where an array matches as long as any of its elements does.
+func matchValue(c *Condition, op Operator, value any) bool {
+	if items, ok := value.([]any); ok {
+		return slices.ContainsFunc(items, func(item any) bool {
+			return matchValue(c, op, item)
+		})
+	}
+
+	switch op {
+	case Gt, Lt:
+		number, ok := toNumber(value)
+		if !ok {
+			return false
+		}
+		if op == Gt {
+			return number > c.Number
+		}
+		return number < c.Number
+	case Eq, In:
+		if _, ok := value.(string); ok {
+			return matchString(c, op, value)
+		}
+		return matchScalar(c, value)
+	default:
+		return matchString(c, op, value)
+	}
+}
+
+// matchString compares value with the operands of c using op,
+// as long as it's a string.
+func matchString(c *Condition, op Operator, value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	if op == Regex {
+		return c.Pattern.MatchString(s)
+	}
+
+	operands := c.Values
+	if c.CaseInsensitive {
+		s = strings.ToLower(s)
+		operands = make([]string, len(c.Values))
+		for n, v := range c.Values {
+			operands[n] = strings.ToLower(v)
+		}
+	}
+
+	switch op {
+	case Eq:
+		return s == operands[0]
+	case In:
+		return slices.Contains(operands, s)
+	case Prefix:
+		return strings.HasPrefix(s, operands[0])
+	case Suffix:
+		return strings.HasSuffix(s, operands[0])
+	case Contains:
+		return strings.Contains(s, operands[0])
+	default:
+		return false
+	}
+}
+
+// matchScalar tells if value, a boolean, a number or null,
+// is equal to any of the operands of c.
+func matchScalar(c *Condition, value any) bool {
+	key, ok := ValueKey(value)
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(c.Values, func(operand string) bool {
+		if c.CaseInsensitive {
+			operand = strings.ToLower(operand)
+		}
+		return slices.Contains(OperandKeys(operand), key)
+	})
+}
+
+// lookupPath gets the value in metadata at path, where each dot
+// represents each key node in a different nest level, or the index
+// of an element when it's nested in an array.
+func lookupPath(metadata map[string]any, path string) (any, bool) {
+	var value any = metadata
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			n, ok := arrayIndex(key, len(v))
+			if !ok {
+				return nil, false
+			}
+			value = v[n]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// arrayIndex parses key as the index of an element in an array of length elements,
+// telling if it is one. Indexes are written without signs or leading zeros.
+func arrayIndex(key string, length int) (int, bool) {
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 0 || n >= length || strconv.Itoa(n) != key {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// toNumber converts value into a number, as long as it's either
+// a number or a string holding one.
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// jsonNumber matches the text of a JSON number.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// ValueKey gets the key value is compared by for equality, as long as it's
+// a scalar. Values of different types get different keys, and numbers
+// get the same key however they're written, so that `1` and `1.0` are equal.
+func ValueKey(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return "s:" + v, true
+	case bool:
+		return "b:" + strconv.FormatBool(v), true
+	case float64:
+		return "n:" + strconv.FormatFloat(v, 'g', -1, 64), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
+
+// OperandKeys gets the keys, as ValueKey gets them, of the values equal to
+// operand: the string itself, along with the boolean, the number or null
+// it stands for, if any.
+func OperandKeys(operand string) []string {
+	keys := []string{"s:" + operand}
+	switch {
+	case operand == "true" || operand == "false":
+		keys = append(keys, "b:"+operand)
+	case operand == "null":
+		keys = append(keys, "null")
+	case jsonNumber.MatchString(operand):
+		if number, err := strconv.ParseFloat(operand, 64); err == nil {
+			key, _ := ValueKey(number)
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,61 @@
+package query_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	metadata := []byte(`{"region": "eu-west", "replicas": 3, "canary": true, "zones": ["a", "b"], "owner": {"team": "checkout", "lead": null}}`)
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "nested path", input: `owner.team = checkout`, want: true},
+		{name: "case-insensitive prefix", input: `region prefix:i EU`, want: true},
+		{name: "numeric comparison", input: `replicas > 2`, want: true},
+		{name: "missing path", input: `tier exists`, want: false},
+		{name: "or group", input: `region = us or owner.team = checkout`, want: true},
+		{name: "and group", input: `region = us and owner.team = checkout`, want: false},
+		{name: "number", input: `replicas = 3.0`, want: true},
+		{name: "number in a string", input: `region = 3`, want: false},
+		{name: "boolean", input: `canary = true`, want: true},
+		{name: "case-insensitive boolean", input: `canary eq:i TRUE`, want: true},
+		{name: "boolean isn't a string", input: `canary prefix tr`, want: false},
+		{name: "null", input: `owner.lead = null`, want: true},
+		{name: "null isn't missing", input: `owner.deputy = null`, want: false},
+		{name: "missing isn't null", input: `owner.deputy != null`, want: true},
+		{name: "any array element", input: `zones in (c, b)`, want: true},
+		{name: "array element by index", input: `zones.1 = b`, want: true},
+		{name: "array index with a leading zero", input: `zones.01 exists`, want: false},
+		{name: "not equal to any array element", input: `zones != b`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, query.Match(mustParse(t, tt.input), metadata))
+		})
+	}
+
+	t.Run("nil expression matches anything", func(t *testing.T) {
+		assert.True(t, query.Match(nil, metadata))
+	})
+
+	t.Run("malformed metadata matches nothing", func(t *testing.T) {
+		assert.False(t, query.Match(mustParse(t, `region exists`), []byte(`[`)))
+	})
+}
+
+// mustParse parses input, failing the test if it isn't a valid expression.
+func mustParse(t *testing.T, input string) query.Expr {
+	t.Helper()
+
+	expr, err := query.Parse(input)
+	require.NoError(t, err)
+
+	return expr
+}
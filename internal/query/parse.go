@@ -0,0 +1,356 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExpressionParam is the URL query param holding a query expression.
+const ExpressionParam = "q"
+
+// metadataPrefix is accepted in front of every path, because it's redundant
+// given that queries are always about metadata.
+const metadataPrefix = "metadata."
+
+// ParseValues parses the URL query params in values into a query, where every
+// condition and the expression in ExpressionParam must match.
+// Every value of a repeated param is a condition of its own.
+// It returns nil when there's nothing to match.
+func ParseValues(values url.Values) (Expr, error) {
+	// go through the params in a stable order,
+	// so that errors are reported consistently.
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var exprs []Expr
+	for _, k := range keys {
+		for _, v := range values[k] {
+			var (
+				expr Expr
+				err  error
+			)
+			if k == ExpressionParam {
+				expr, err = Parse(v)
+			} else {
+				expr, err = parseParam(k, v)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if expr != nil {
+				exprs = append(exprs, expr)
+			}
+		}
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return exprs[0], nil
+	default:
+		return And{Exprs: exprs}, nil
+	}
+}
+
+// parseParam parses a single URL query param, such as `metadata.region[in:i]=eu,us`.
+// Without an operator, the condition is an equality.
+func parseParam(key, value string) (Expr, error) {
+	path, op := key, string(Eq)
+	if open := strings.IndexByte(key, '['); open >= 0 {
+		if !strings.HasSuffix(key, "]") {
+			return nil, &SyntaxError{Input: key, Pos: len(key) + 1, Msg: `expected "]"`}
+		}
+		path, op = key[:open], key[open+1:len(key)-1]
+	}
+
+	if path == "" {
+		return nil, &SyntaxError{Input: key, Pos: 1, Msg: "expected a path"}
+	}
+
+	var operands []string
+	switch operator, _ := strings.CutSuffix(op, caseInsensitiveSuffix); Operator(operator) {
+	case In:
+		operands = strings.Split(value, ",")
+	case Exists, Missing:
+		// the operand can only flip the condition, as in `[exists]=false`.
+		if value != "" {
+			exists, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, &SyntaxError{Input: value, Pos: 1, Msg: "expected true or false"}
+			}
+			if !exists {
+				op = string(Missing)
+				if Operator(operator) == Missing {
+					op = string(Exists)
+				}
+			}
+		}
+	default:
+		operands = []string{value}
+	}
+
+	return newCondition(path, op, operands, key, len(path)+2)
+}
+
+// newCondition builds the condition comparing the value at path with operands
+// using op, which is found at pos in input.
+func newCondition(path, op string, operands []string, input string, pos int) (*Condition, error) {
+	condition := &Condition{Path: strings.TrimPrefix(path, metadataPrefix)}
+
+	name, caseInsensitive := strings.CutSuffix(op, caseInsensitiveSuffix)
+	condition.Op, condition.CaseInsensitive = Operator(name), caseInsensitive
+
+	arity, ok := operators[condition.Op]
+	if !ok {
+		return nil, &SyntaxError{Input: input, Pos: pos, Msg: fmt.Sprintf("unknown operator %q", op)}
+	}
+
+	switch {
+	case arity == -1 && len(operands) == 0, arity >= 0 && len(operands) != arity:
+		return nil, &SyntaxError{Input: input, Pos: pos, Msg: fmt.Sprintf("wrong number of operands for %q", op)}
+	case caseInsensitive && (arity == 0 || condition.Op == Gt || condition.Op == Lt):
+		return nil, &SyntaxError{Input: input, Pos: pos, Msg: fmt.Sprintf("%q doesn't compare strings", name)}
+	}
+	condition.Values = operands
+
+	switch condition.Op {
+	case Regex:
+		pattern := operands[0]
+		if caseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &SyntaxError{Input: input, Pos: pos, Msg: err.Error()}
+		}
+		condition.Pattern = re
+	case Gt, Lt:
+		number, err := strconv.ParseFloat(operands[0], 64)
+		if err != nil {
+			return nil, &SyntaxError{Input: input, Pos: pos, Msg: fmt.Sprintf("%q isn't a number", operands[0])}
+		}
+		condition.Number = number
+	}
+
+	return condition, nil
+}
+
+// symbols are the operators that can be written as symbols in expressions.
+var symbols = map[string]Operator{
+	"=":  Eq,
+	"!=": Ne,
+	">":  Gt,
+	"<":  Lt,
+	"~":  Regex,
+}
+
+// Parse parses a query expression.
+//
+//	expr      = and { ("or" | "||") and }
+//	and       = primary { ("and" | "&&") primary }
+//	primary   = "(" expr ")" | condition
+//	condition = path operator [ value | "(" value { "," value } ")" ]
+//	value     = word | quoted string
+//
+// Operators are either their names, optionally followed by ":i" to ignore
+// the case, or one of the symbols =, !=, >, < and ~.
+func Parse(input string) (Expr, error) {
+	p := &parser{lexer: lexer{input: input}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.kind != tokenEOF {
+		return nil, p.errorf("unexpected %s", p.token)
+	}
+
+	return expr, nil
+}
+
+// parser is a recursive descent parser of query expressions.
+type parser struct {
+	lexer lexer
+	// token is the current token.
+	token token
+}
+
+// next moves on to the next token.
+func (p *parser) next() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+
+	return nil
+}
+
+// errorf reports a syntax error at the current token.
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Input: p.lexer.input, Pos: p.token.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword tells if the current token is one of keywords.
+func (p *parser) isKeyword(keywords ...string) bool {
+	if p.token.kind != tokenWord && p.token.kind != tokenSymbol {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(p.token.text, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{expr}
+	for p.isKeyword("or", "||") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return Or{Exprs: exprs}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{expr}
+	for p.isKeyword("and", "&&") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return And{Exprs: exprs}, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.token.kind == tokenLParen {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenRParen {
+			return nil, p.errorf(`expected ")", got %s`, p.token)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	if p.token.kind != tokenWord {
+		return nil, p.errorf("expected a path, got %s", p.token)
+	}
+	path := p.token.text
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.token.kind != tokenWord && p.token.kind != tokenSymbol {
+		return nil, p.errorf("expected an operator, got %s", p.token)
+	}
+	op, opPos := p.token.text, p.token.pos+1
+	if symbol, ok := symbols[op]; ok {
+		op = string(symbol)
+	} else if p.token.kind == tokenSymbol {
+		return nil, p.errorf("expected an operator, got %s", p.token)
+	}
+	op = strings.ToLower(op)
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var operands []string
+	name, _ := strings.CutSuffix(op, caseInsensitiveSuffix)
+	switch arity, known := operators[Operator(name)]; {
+	case !known || arity == 0:
+		// no operands, or an unknown operator reported along with the condition.
+	case p.token.kind == tokenLParen:
+		for {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, value)
+			if p.token.kind == tokenRParen {
+				break
+			}
+			if p.token.kind != tokenComma {
+				return nil, p.errorf(`expected "," or ")", got %s`, p.token)
+			}
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	default:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		operands = []string{value}
+	}
+
+	return newCondition(path, op, operands, p.lexer.input, opPos)
+}
+
+func (p *parser) parseValue() (string, error) {
+	if p.token.kind != tokenWord && p.token.kind != tokenString {
+		return "", p.errorf("expected a value, got %s", p.token)
+	}
+	value := p.token.text
+
+	return value, p.next()
+}
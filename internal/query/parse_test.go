@@ -0,0 +1,223 @@
+package query_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  query.Expr
+	}{
+		{
+			name:  "equality",
+			input: `metadata.region = eu`,
+			want:  &query.Condition{Path: "region", Op: query.Eq, Values: []string{"eu"}},
+		},
+		{
+			name:  "quoted value",
+			input: `name eq "hello \"world\""`,
+			want:  &query.Condition{Path: "name", Op: query.Eq, Values: []string{`hello "world"`}},
+		},
+		{
+			name:  "case-insensitive operator",
+			input: `region PREFIX:I Eu`,
+			want:  &query.Condition{Path: "region", Op: query.Prefix, Values: []string{"Eu"}, CaseInsensitive: true},
+		},
+		{
+			name:  "list of values",
+			input: `region in (eu, "us")`,
+			want:  &query.Condition{Path: "region", Op: query.In, Values: []string{"eu", "us"}},
+		},
+		{
+			name:  "operator without operands",
+			input: `region exists`,
+			want:  &query.Condition{Path: "region", Op: query.Exists},
+		},
+		{
+			name:  "and binds tighter than or",
+			input: `a = 1 or b = 2 && c = 3`,
+			want: query.Or{Exprs: []query.Expr{
+				&query.Condition{Path: "a", Op: query.Eq, Values: []string{"1"}},
+				query.And{Exprs: []query.Expr{
+					&query.Condition{Path: "b", Op: query.Eq, Values: []string{"2"}},
+					&query.Condition{Path: "c", Op: query.Eq, Values: []string{"3"}},
+				}},
+			}},
+		},
+		{
+			name:  "parentheses group",
+			input: `(a != 1 || b missing) and c > 3`,
+			want: query.And{Exprs: []query.Expr{
+				query.Or{Exprs: []query.Expr{
+					&query.Condition{Path: "a", Op: query.Ne, Values: []string{"1"}},
+					&query.Condition{Path: "b", Op: query.Missing},
+				}},
+				&query.Condition{Path: "c", Op: query.Gt, Values: []string{"3"}, Number: 3},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := query.Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("regex is compiled", func(t *testing.T) {
+		got, err := query.Parse(`name regex:i "^web-\d+$"`)
+		require.NoError(t, err)
+
+		condition, ok := got.(*query.Condition)
+		require.True(t, ok)
+		assert.True(t, condition.Pattern.MatchString("WEB-42"))
+	})
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantPos int
+		wantMsg string
+	}{
+		{
+			name:    "empty query",
+			input:   ``,
+			wantPos: 1,
+			wantMsg: "expected a path, got end of query",
+		},
+		{
+			name:    "missing operator",
+			input:   `region`,
+			wantPos: 7,
+			wantMsg: "expected an operator, got end of query",
+		},
+		{
+			name:    "unknown operator",
+			input:   `region like eu`,
+			wantPos: 8,
+			wantMsg: `unknown operator "like"`,
+		},
+		{
+			name:    "missing value",
+			input:   `region =`,
+			wantPos: 9,
+			wantMsg: "expected a value, got end of query",
+		},
+		{
+			name:    "unbalanced parentheses",
+			input:   `(a = 1 or b = 2`,
+			wantPos: 16,
+			wantMsg: `expected ")", got end of query`,
+		},
+		{
+			name:    "trailing tokens",
+			input:   `a = 1 b = 2`,
+			wantPos: 7,
+			wantMsg: `unexpected "b"`,
+		},
+		{
+			name:    "unterminated string",
+			input:   `a = "open`,
+			wantPos: 5,
+			wantMsg: "unterminated string",
+		},
+		{
+			name:    "invalid regex",
+			input:   `a ~ "("`,
+			wantPos: 3,
+			wantMsg: "error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:    "non-numeric operand",
+			input:   `a gt ten`,
+			wantPos: 3,
+			wantMsg: `"ten" isn't a number`,
+		},
+		{
+			name:    "case-insensitive numeric comparison",
+			input:   `a lt:i 10`,
+			wantPos: 3,
+			wantMsg: `"lt" doesn't compare strings`,
+		},
+		{
+			name:    "bad separator in a list",
+			input:   `a in (1; 2)`,
+			wantPos: 10,
+			wantMsg: `expected "," or ")", got "2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := query.Parse(tt.input)
+			require.ErrorIs(t, err, query.ErrInvalidQuery)
+
+			var syntaxErr *query.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.wantPos, syntaxErr.Pos)
+			assert.Equal(t, tt.wantMsg, syntaxErr.Msg)
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	t.Run("conditions are combined", func(t *testing.T) {
+		values := url.Values{
+			"metadata.region[in:i]": {"eu,us"},
+			"tier":                  {"gold"},
+			"owner[exists]":         {"false"},
+			"q":                     {`a = 1 or b = 2`},
+		}
+
+		got, err := query.ParseValues(values)
+		require.NoError(t, err)
+
+		assert.Equal(t, query.And{Exprs: []query.Expr{
+			&query.Condition{Path: "region", Op: query.In, Values: []string{"eu", "us"}, CaseInsensitive: true},
+			&query.Condition{Path: "owner", Op: query.Missing},
+			query.Or{Exprs: []query.Expr{
+				&query.Condition{Path: "a", Op: query.Eq, Values: []string{"1"}},
+				&query.Condition{Path: "b", Op: query.Eq, Values: []string{"2"}},
+			}},
+			&query.Condition{Path: "tier", Op: query.Eq, Values: []string{"gold"}},
+		}}, got)
+	})
+
+	t.Run("every value of a repeated param is kept", func(t *testing.T) {
+		got, err := query.ParseValues(url.Values{"region[ne]": {"eu", "us"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, query.And{Exprs: []query.Expr{
+			&query.Condition{Path: "region", Op: query.Ne, Values: []string{"eu"}},
+			&query.Condition{Path: "region", Op: query.Ne, Values: []string{"us"}},
+		}}, got)
+	})
+
+	t.Run("no params", func(t *testing.T) {
+		got, err := query.ParseValues(url.Values{})
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("unknown operator", func(t *testing.T) {
+		_, err := query.ParseValues(url.Values{"region[like]": {"eu"}})
+
+		var syntaxErr *query.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 8, syntaxErr.Pos)
+	})
+
+	t.Run("unclosed operator", func(t *testing.T) {
+		_, err := query.ParseValues(url.Values{"region[in": {"eu"}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
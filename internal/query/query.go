@@ -0,0 +1,127 @@
+// Package query defines the language used to search configs by their metadata.
+//
+// A query is either made of URL query params, where each of them is a condition
+// on the value at a metadata path, such as `metadata.region[in]=eu,us`, or it's
+// an expression in the `q` param, combining conditions with `and` and `or`,
+// such as `region in (eu, us) and (tier = gold or tier prefix:i "plat")`.
+//
+// Both are parsed into the same AST, which repositories evaluate.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrInvalidQuery is used when a query can't be parsed.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// SyntaxError reports where a query can't be parsed.
+type SyntaxError struct {
+	// Input is the text being parsed.
+	Input string
+	// Pos is the position of the offending character in Input, starting at 1.
+	Pos int
+	// Msg describes what's wrong.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s at position %d of %q", ErrInvalidQuery, e.Msg, e.Pos, e.Input)
+}
+
+// Is makes every SyntaxError match ErrInvalidQuery.
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// Operator is the comparison a Condition makes.
+type Operator string
+
+const (
+	// Eq matches values equal to the operand, which stands for the string
+	// it's made of, as well as for the boolean, number or null it's written as.
+	Eq Operator = "eq"
+	// Ne matches anything but values equal to the operand,
+	// including missing values.
+	Ne Operator = "ne"
+	// In matches values equal to any of the operands.
+	In Operator = "in"
+	// Prefix matches string values starting with the operand.
+	Prefix Operator = "prefix"
+	// Suffix matches string values ending with the operand.
+	Suffix Operator = "suffix"
+	// Contains matches string values containing the operand.
+	Contains Operator = "contains"
+	// Regex matches string values matching the regular expression in the operand.
+	Regex Operator = "regex"
+	// Exists matches any value, as long as there's one.
+	Exists Operator = "exists"
+	// Missing matches when there's no value.
+	Missing Operator = "missing"
+	// Gt matches numeric values greater than the operand.
+	Gt Operator = "gt"
+	// Lt matches numeric values lower than the operand.
+	Lt Operator = "lt"
+)
+
+// operators holds the number of operands each operator takes,
+// where -1 stands for one or more.
+var operators = map[Operator]int{
+	Eq:       1,
+	Ne:       1,
+	In:       -1,
+	Prefix:   1,
+	Suffix:   1,
+	Contains: 1,
+	Regex:    1,
+	Exists:   0,
+	Missing:  0,
+	Gt:       1,
+	Lt:       1,
+}
+
+// caseInsensitiveSuffix is appended to the operators comparing strings
+// to ignore the case, such as `eq:i`.
+const caseInsensitiveSuffix = ":i"
+
+// Expr is a node of the AST of a query.
+type Expr interface {
+	expr()
+}
+
+// And matches when every one of Exprs matches.
+type And struct {
+	Exprs []Expr
+}
+
+// Or matches when any of Exprs matches.
+type Or struct {
+	Exprs []Expr
+}
+
+// Condition matches when the value at Path in metadata satisfies
+// Op against Values. An array satisfies it as long as any of its
+// elements does, except for Exists and Missing.
+type Condition struct {
+	// Path is the path of the value in metadata, where each dot
+	// represents each key node in a different nest level, or the
+	// index of an element when it's nested in an array.
+	Path string
+	// Op is the comparison to make.
+	Op Operator
+	// Values are the operands of Op.
+	Values []string
+	// CaseInsensitive ignores the case when comparing strings.
+	CaseInsensitive bool
+	// Pattern is the compiled operand of Regex.
+	Pattern *regexp.Regexp
+	// Number is the parsed operand of Gt and Lt.
+	Number float64
+}
+
+func (And) expr()        {}
+func (Or) expr()         {}
+func (*Condition) expr() {}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownBackend is used when there's no storage backend registered with a given name.
+	ErrUnknownBackend = errors.New("unknown storage backend")
+	// ErrInvalidBackendOptions is used when the options don't suit the selected storage backend.
+	ErrInvalidBackendOptions = errors.New("invalid storage backend options")
+)
+
+// Names of the storage backends registered out of the box.
+const (
+	// MemoryBackend keeps the configs in memory only.
+	MemoryBackend = "memory"
+	// FileBackend keeps the configs on local disk, see FileConfig,
+	// along with their schemas and webhooks, see FileSchema and FileWebhook.
+	FileBackend = "file"
+)
+
+// BackendOptions are the settings a storage backend is built with.
+// Each backend only accepts the options that make sense to it.
+type BackendOptions struct {
+	// DataDir is the directory where the data is persisted.
+	DataDir string
+}
+
+// Backend holds the repositories a storage backend keeps its data in,
+// so that the schemas and webhooks attached to configs are kept just like them.
+//
+// If any of the repositories holds resources that need to be released,
+// it's expected to implement io.Closer.
+type Backend struct {
+	Configs  Config
+	Schemas  Schema
+	Webhooks Webhook
+}
+
+// Close releases the resources held by the repositories of b.
+func (b Backend) Close() error {
+	var errs []error
+	for _, repo := range []any{b.Configs, b.Schemas, b.Webhooks} {
+		if closer, ok := repo.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// BackendFactory builds a Backend out of opts. It returns
+// ErrInvalidBackendOptions when opts don't suit the backend.
+type BackendFactory func(opts BackendOptions) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{
+		MemoryBackend: newMemoryBackend,
+		FileBackend:   newFileBackend,
+	}
+)
+
+// RegisterBackend makes a storage backend available under name,
+// replacing any backend previously registered with the same name.
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	backends[name] = factory
+}
+
+// Backends gets the names of the registered storage backends, sorted.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewBackend builds a Backend using the storage backend registered
+// under name. If there's no such backend, it returns ErrUnknownBackend.
+func NewBackend(name string, opts BackendOptions) (Backend, error) {
+	backendsMu.RLock()
+	factory, ok := backends[name]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return Backend{}, fmt.Errorf("%w %q, use one of %v", ErrUnknownBackend, name, Backends())
+	}
+
+	return factory(opts)
+}
+
+// newMemoryBackend builds a Backend out of in-memory repositories, which takes no options.
+func newMemoryBackend(opts BackendOptions) (Backend, error) {
+	if opts.DataDir != "" {
+		return Backend{}, fmt.Errorf("%w: %s backend doesn't take a data directory", ErrInvalidBackendOptions, MemoryBackend)
+	}
+
+	return Backend{Configs: NewInMemoryConfig(), Schemas: NewInMemorySchema(), Webhooks: NewInMemoryWebhook()}, nil
+}
+
+// newFileBackend builds a Backend storing its data in the data directory.
+func newFileBackend(opts BackendOptions) (Backend, error) {
+	if opts.DataDir == "" {
+		return Backend{}, fmt.Errorf("%w: %s backend requires a data directory", ErrInvalidBackendOptions, FileBackend)
+	}
+
+	configs, err := NewFileConfig(opts.DataDir)
+	if err != nil {
+		return Backend{}, err
+	}
+	schemas, err := NewFileSchema(opts.DataDir)
+	if err != nil {
+		configs.Close()
+		return Backend{}, err
+	}
+	webhooks, err := NewFileWebhook(opts.DataDir)
+	if err != nil {
+		configs.Close()
+		return Backend{}, err
+	}
+
+	return Backend{Configs: configs, Schemas: schemas, Webhooks: webhooks}, nil
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		opts    repository.BackendOptions
+		wantErr error
+	}{
+		{
+			name:    "memory backend",
+			backend: repository.MemoryBackend,
+		},
+		{
+			name:    "memory backend with a data directory",
+			backend: repository.MemoryBackend,
+			opts:    repository.BackendOptions{DataDir: "/tmp"},
+			wantErr: repository.ErrInvalidBackendOptions,
+		},
+		{
+			name:    "file backend",
+			backend: repository.FileBackend,
+			opts:    repository.BackendOptions{DataDir: t.TempDir()},
+		},
+		{
+			name:    "file backend without a data directory",
+			backend: repository.FileBackend,
+			wantErr: repository.ErrInvalidBackendOptions,
+		},
+		{
+			name:    "unknown backend",
+			backend: "nope",
+			wantErr: repository.ErrUnknownBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := repository.NewBackend(tt.backend, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Zero(t, backend)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, backend.Configs)
+			assert.NotNil(t, backend.Schemas)
+			assert.NotNil(t, backend.Webhooks)
+			assert.NoError(t, backend.Close())
+		})
+	}
+
+	t.Run("file backend keeps schemas and webhooks", func(t *testing.T) {
+		dir := t.TempDir()
+		backend, err := repository.NewBackend(repository.FileBackend, repository.BackendOptions{DataDir: dir})
+		require.NoError(t, err)
+		_, err = backend.Schemas.Put(domain.Schema{Name: "flags", Pattern: "flags-*", Definition: []byte(`{}`)})
+		require.NoError(t, err)
+		require.NoError(t, backend.Webhooks.Save(domain.Webhook{ID: "wh-1", URL: "http://localhost/hook"}))
+		require.NoError(t, backend.Close())
+
+		reopened, err := repository.NewBackend(repository.FileBackend, repository.BackendOptions{DataDir: dir})
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		_, err = reopened.Schemas.Get("flags")
+		assert.NoError(t, err)
+		_, err = reopened.Webhooks.Get("wh-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("custom backend is registered", func(t *testing.T) {
+		var gotOpts repository.BackendOptions
+		repository.RegisterBackend("custom", func(opts repository.BackendOptions) (repository.Backend, error) {
+			gotOpts = opts
+			return repository.Backend{
+				Configs:  repository.NewInMemoryConfig(),
+				Schemas:  repository.NewInMemorySchema(),
+				Webhooks: repository.NewInMemoryWebhook(),
+			}, nil
+		})
+
+		assert.Contains(t, repository.Backends(), "custom")
+
+		_, err := repository.NewBackend("custom", repository.BackendOptions{DataDir: "/var/lib/custom"})
+		require.NoError(t, err)
+		assert.Equal(t, "/var/lib/custom", gotOpts.DataDir)
+	})
+}
+
+func TestNewInMemoryConfig(t *testing.T) {
+	t.Run("instances don't share data", func(t *testing.T) {
+		first := repository.NewInMemoryConfig()
+		second := repository.NewInMemoryConfig()
+
+		_, err := first.Save(domain.Config{
+			Namespace: domain.DefaultNamespace,
+			Name:      "config 1",
+			Metadata:  []byte(`{}`),
+		})
+		require.NoError(t, err)
+
+		_, err = second.Get(domain.DefaultNamespace, "config 1")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
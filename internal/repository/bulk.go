@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+)
+
+// ErrInvalidOp is used when an operation can't be run as it's described.
+var ErrInvalidOp = errors.New("invalid operation")
+
+// OpType is the kind of change an operation makes to a config.
+type OpType string
+
+const (
+	// OpCreate creates a new config, failing with ErrConfigExists if there's one already.
+	OpCreate OpType = "create"
+	// OpUpsert creates a config, or updates it if it exists already.
+	OpUpsert OpType = "upsert"
+	// OpUpdate updates an existing config, failing with ErrConfigNotFound otherwise.
+	OpUpdate OpType = "update"
+	// OpDelete deletes an existing config, failing with ErrConfigNotFound otherwise.
+	OpDelete OpType = "delete"
+)
+
+// Op is an operation changing a config.
+type Op struct {
+	// Type is the kind of change made to the config.
+	Type OpType
+	// Namespace is the name of the namespace holding the config.
+	Namespace string
+	// Name is the name of the config.
+	Name string
+	// Metadata is the metadata the config is left with. It's ignored when deleting.
+	Metadata []byte
+	// Parents are the configs the config inherits from. When updating,
+	// nil keeps the ones it has. It's ignored when deleting.
+	Parents []string
+	// Revision makes the operation conditional when it's set, like CompareAndSwap
+	// and CompareAndDelete, so that it's only applied to a config still at revision.
+	// It's ignored when creating.
+	Revision int64
+}
+
+// OpResult is the outcome of an operation.
+type OpResult struct {
+	// Config is the config as it was left by the operation,
+	// or right before it when it was deleted.
+	Config domain.Config
+	// Created tells if the operation created the config.
+	Created bool
+	// Err is the reason why the operation failed, if it did.
+	Err error
+}
+
+// Bulk runs every operation in ops in the in-memory datastore in order, under
+// a single lock acquisition. Operations are independent from one another, so
+// one failing doesn't stop the rest from being run.
+// When journaled, the whole batch is made durable at once, so if that fails,
+// every operation that went through fails along.
+func (i *InMemoryConfig) Bulk(ops []Op) []OpResult {
+	i.db.lock()
+	defer i.db.unlock()
+
+	results := make([]OpResult, len(ops))
+	err := i.db.batch(func() error {
+		for n, op := range ops {
+			results[n] = i.db.exec(op)
+		}
+		return nil
+	})
+	if err != nil {
+		for n := range results {
+			if results[n].Err == nil {
+				results[n] = OpResult{Err: err}
+			}
+		}
+	}
+
+	return results
+}
+
+// exec runs op against the state. Callers must hold the lock.
+func (i *inMemoryDBState) exec(op Op) OpResult {
+	key := configKey{op.Namespace, op.Name}
+	existing, exists := i.configs[key]
+
+	if exists && op.Revision != 0 && op.Type != OpCreate && existing.Revision != op.Revision {
+		return OpResult{Err: ErrRevisionMismatch}
+	}
+
+	switch op.Type {
+	case OpCreate, OpUpsert:
+		if _, ok := i.namespaces[op.Namespace]; !ok {
+			return OpResult{Err: ErrNamespaceNotFound}
+		}
+		if exists && op.Type == OpCreate {
+			return OpResult{Err: ErrConfigExists}
+		}
+		// a conditional upsert is only meant to update the config at revision.
+		if !exists && op.Revision != 0 {
+			return OpResult{Err: ErrConfigNotFound}
+		}
+	case OpUpdate, OpDelete:
+		if !exists {
+			return OpResult{Err: ErrConfigNotFound}
+		}
+	default:
+		return OpResult{Err: fmt.Errorf("%w: unknown type %q", ErrInvalidOp, op.Type)}
+	}
+
+	if op.Type == OpDelete {
+		if err := i.remove(existing); err != nil {
+			return OpResult{Err: err}
+		}
+		return OpResult{Config: existing}
+	}
+
+	cfg := domain.Config{Namespace: op.Namespace, Name: op.Name, Metadata: op.Metadata, Parents: op.Parents}
+	if cfg.Parents == nil {
+		cfg.Parents = existing.Parents
+	}
+	cfg, err := i.put(cfg)
+	if err != nil {
+		return OpResult{Err: err}
+	}
+
+	return OpResult{Config: cfg, Created: !exists}
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInMemoryConfig_Bulk(t *testing.T) {
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	metadata := []byte(`{"foo": "bulk"}`)
+	tests := []struct {
+		name        string
+		op          repository.Op
+		wantErr     error
+		wantCreated bool
+	}{
+		{
+			name:        "create",
+			op:          repository.Op{Type: repository.OpCreate, Name: "new", Metadata: metadata},
+			wantCreated: true,
+		},
+		{
+			name:    "create existing config",
+			op:      repository.Op{Type: repository.OpCreate, Name: "new", Metadata: metadata},
+			wantErr: repository.ErrConfigExists,
+		},
+		{
+			name:    "create in a missing namespace",
+			op:      repository.Op{Type: repository.OpCreate, Namespace: "nope", Name: "new", Metadata: metadata},
+			wantErr: repository.ErrNamespaceNotFound,
+		},
+		{
+			name:        "upsert missing config",
+			op:          repository.Op{Type: repository.OpUpsert, Name: "upserted", Metadata: metadata},
+			wantCreated: true,
+		},
+		{
+			name: "upsert existing config",
+			op:   repository.Op{Type: repository.OpUpsert, Name: "upserted", Metadata: metadata},
+		},
+		{
+			name: "conditional update",
+			op:   repository.Op{Type: repository.OpUpdate, Name: test.ConfigName1, Metadata: metadata, Revision: current.Revision},
+		},
+		{
+			name:    "conditional update at a stale revision",
+			op:      repository.Op{Type: repository.OpUpdate, Name: test.ConfigName1, Metadata: metadata, Revision: current.Revision},
+			wantErr: repository.ErrRevisionMismatch,
+		},
+		{
+			name:    "update missing config",
+			op:      repository.Op{Type: repository.OpUpdate, Name: "nope", Metadata: metadata},
+			wantErr: repository.ErrConfigNotFound,
+		},
+		{
+			name: "delete",
+			op:   repository.Op{Type: repository.OpDelete, Name: test.ConfigName2},
+		},
+		{
+			name:    "delete missing config",
+			op:      repository.Op{Type: repository.OpDelete, Name: test.ConfigName2},
+			wantErr: repository.ErrConfigNotFound,
+		},
+		{
+			name:    "unknown operation",
+			op:      repository.Op{Type: "rename", Name: test.ConfigName3},
+			wantErr: repository.ErrInvalidOp,
+		},
+	}
+
+	ops := make([]repository.Op, len(tests))
+	for n, tt := range tests {
+		if tt.op.Namespace == "" {
+			tt.op.Namespace = domain.DefaultNamespace
+		}
+		ops[n] = tt.op
+	}
+
+	results := repo.Bulk(ops)
+	require.Len(t, results, len(tests))
+
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := results[n]
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, result.Err, tt.wantErr)
+				return
+			}
+			require.NoError(t, result.Err)
+			assert.Equal(t, tt.wantCreated, result.Created)
+			assert.Equal(t, tt.op.Name, result.Config.Name)
+		})
+	}
+
+	t.Run("changes are stored", func(t *testing.T) {
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Equal(t, metadata, config.Metadata)
+
+		_, err = repo.Get(domain.DefaultNamespace, test.ConfigName2)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
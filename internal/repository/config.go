@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -12,40 +17,112 @@ var (
 	ErrConfigNotFound = errors.New("config not found")
 	// ErrConfigExists is used when there's already an existing resource with the same name.
 	ErrConfigExists = errors.New("config already exists")
+	// ErrRevisionNotFound is used when a config doesn't have a given revision.
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrRevisionMismatch is used when a conditional change is attempted against
+	// a config that isn't at the expected revision anymore.
+	ErrRevisionMismatch = errors.New("config revision doesn't match")
+	// ErrNamespaceNotFound is used when a given namespace doesn't exist.
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrNamespaceExists is used when there's already a namespace with the same name.
+	ErrNamespaceExists = errors.New("namespace already exists")
+	// ErrNamespaceNotEmpty is used when deleting a namespace that still holds configs.
+	ErrNamespaceNotEmpty = errors.New("namespace is not empty")
+	// ErrDefaultNamespace is used when trying to delete the default namespace.
+	ErrDefaultNamespace = errors.New("default namespace can't be deleted")
+	// ErrConfigHasChildren is used when deleting a config other configs inherit from.
+	ErrConfigHasChildren = errors.New("config has children inheriting from it")
 )
 
-var (
-	db         *inMemoryDBState
-	initDBOnce sync.Once
-)
+// AllNamespaces makes Search look for configs across every namespace.
+const AllNamespaces = ""
 
 // Config is the port defining the I/O operations
 // for the domain.Config resource.
 //
+// Configs are identified by their name within a namespace.
+//
 //go:generate mockery --name Config
 type Config interface {
-	// List gets a list of configs.
-	List() ([]domain.Config, error)
-	// Save persists a new config.
-	Save(cfg domain.Config) error
+	// List gets the page of the configs in namespace described by opts.
+	List(namespace string, opts ListOptions) (Page, error)
+	// Save persists a new config in the namespace set in cfg,
+	// returning it as it's stored.
+	Save(cfg domain.Config) (domain.Config, error)
 	// Get gets a config identified by its name.
-	Get(name string) (domain.Config, error)
+	Get(namespace, name string) (domain.Config, error)
+	// Resolve gets the config identified by its name with the metadata
+	// it inherits from its parents resolved, as domain.Resolve does.
+	Resolve(namespace, name string) (domain.Config, error)
+	// Descendants gets every config inheriting from the config identified
+	// by its name, directly or not, sorted by name.
+	Descendants(namespace, name string) ([]domain.Config, error)
+	// SetParents sets the configs the config identified by its name inherits
+	// from, which must exist without inheriting from it, failing with
+	// domain.ErrParentNotFound or domain.ErrParentCycle otherwise.
+	// A non-zero revision makes it conditional, just like CompareAndSwap.
+	// It returns the config as it's stored.
+	SetParents(namespace, name string, revision int64, parents []string) (domain.Config, error)
 	// Update updates a given config, applying what's in
 	// metadata to the corresponding config identified by its name.
-	Update(name string, metadata []byte) error
-	// Delete deletes a given config by its name.
-	Delete(name string) error
-	// Search fetches all configs that match the key/value combination in query.
+	// It returns the config as it's stored.
+	Update(namespace, name string, metadata []byte) (domain.Config, error)
+	// CompareAndSwap is like Update, but it only applies metadata if the config
+	// is still at revision, so that concurrent changes aren't silently overwritten.
+	CompareAndSwap(namespace, name string, revision int64, metadata []byte) (domain.Config, error)
+	// Patch atomically replaces the metadata of the config identified by its name
+	// with the outcome of patch applied to its current metadata, so that concurrent
+	// patches don't lose updates. A non-zero revision makes it conditional,
+	// just like CompareAndSwap. It returns the config as it's stored.
+	Patch(namespace, name string, revision int64, patch PatchFunc) (domain.Config, error)
+	// Delete deletes a given config by its name. Configs other configs
+	// inherit from can't be deleted, failing with ErrConfigHasChildren.
+	// It returns the config as it was right before it was deleted.
+	Delete(namespace, name string) (domain.Config, error)
+	// CompareAndDelete is like Delete, but it only deletes the config if it's
+	// still at revision.
+	CompareAndDelete(namespace, name string, revision int64) (domain.Config, error)
+	// Bulk runs every operation in ops in order, reporting the outcome of each
+	// of them, without letting any other change get in between.
+	Bulk(ops []Op) []OpResult
+	// Txn runs either the success or the failure operations of txn, depending
+	// on whether its predicates hold, without letting any other change get in
+	// between. The operations either all go through or none does.
+	Txn(txn Txn) (TxnResult, error)
+	// Search fetches the page described by opts of the configs in namespace whose
+	// metadata matches expr, where a nil expr matches every config.
+	// Use AllNamespaces to search across every namespace.
 	//
-	// repository.Search(map[string]string{"metadata.monitoring", "true"})
-	Search(query map[string]string) ([]domain.Config, error)
+	// expr, _ := query.Parse(`monitoring = true or tier in (gold, silver)`)
+	// repository.Search("default", expr, repository.ListOptions{Limit: 10})
+	Search(namespace string, expr query.Expr, opts ListOptions) (Page, error)
+	// Revisions gets every revision of the config identified by its name,
+	// from the oldest to the current one.
+	Revisions(namespace, name string) ([]domain.Config, error)
+	// Revision gets the config identified by its name as it was at revision.
+	Revision(namespace, name string, revision int64) (domain.Config, error)
+	// Watch blocks until the config identified by its name is past sinceRevision,
+	// returning it as it is by then, or ErrConfigNotFound if it's deleted instead.
+	// It returns the error of ctx as soon as it's done.
+	Watch(ctx context.Context, namespace, name string, sinceRevision int64) (domain.Config, error)
+	// ListNamespaces gets a list of namespaces.
+	ListNamespaces() ([]domain.Namespace, error)
+	// CreateNamespace creates a new empty namespace.
+	CreateNamespace(name string) error
+	// DeleteNamespace deletes an empty namespace by its name.
+	DeleteNamespace(name string) error
 }
 
+// PatchFunc computes the new metadata of a config out of its current metadata.
+// Any error it returns aborts the patch.
+type PatchFunc func(metadata []byte) ([]byte, error)
+
 // NewInMemoryConfig returns a InMemoryConfig repository instance.
+// Every instance holds its own data, which isn't shared with any other.
 // Use InMemoryOption options to use custom settings.
 func NewInMemoryConfig(opts ...InMemoryOption) Config {
 	c := &InMemoryConfig{
-		db: getInMemoryDBState(),
+		db: newInMemoryDBState(),
 	}
 
 	// apply options sent by the user if there's any.
@@ -62,9 +139,40 @@ type InMemoryOption func(c *InMemoryConfig)
 
 // WithCustomData allows one to set custom data to initialize
 // the InMemoryConfig repository.
+// Configs without a namespace are placed in the default namespace,
+// and the namespaces of the others are created along.
 func WithCustomData(configs map[string]domain.Config) InMemoryOption {
 	return func(c *InMemoryConfig) {
-		c.db.configs = configs
+		state := newInMemoryDBState()
+
+		// the custom data starts the history of every config, where revisions
+		// are given in the order of the keys of configs, so that they're the
+		// same every time.
+		names := make([]string, 0, len(configs))
+		for name := range configs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			config := configs[name]
+			if config.Namespace == "" {
+				config.Namespace = domain.DefaultNamespace
+			}
+			if _, ok := state.namespaces[config.Namespace]; !ok {
+				state.namespaces[config.Namespace] = domain.Namespace{Name: config.Namespace}
+			}
+
+			state.revision++
+			config.Revision = state.revision
+
+			key := keyOf(config)
+			state.configs[key] = config
+			state.history[key] = []domain.Config{config}
+			state.index.add(config)
+		}
+
+		c.db = state
 	}
 }
 
@@ -73,45 +181,62 @@ type InMemoryConfig struct {
 	db *inMemoryDBState
 }
 
-// List fetches all available configs from an in-memory datastore.
-func (i *InMemoryConfig) List() ([]domain.Config, error) {
+// List fetches a page of the available configs in namespace from an in-memory datastore.
+// If the namespace is not found, it returns ErrNamespaceNotFound.
+func (i *InMemoryConfig) List(namespace string, opts ListOptions) (Page, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
+	if _, ok := i.db.namespaces[namespace]; !ok {
+		return Page{}, ErrNamespaceNotFound
+	}
+
 	var configs []domain.Config
 
 	for _, c := range i.db.configs {
-		configs = append(configs, c)
+		if c.Namespace == namespace {
+			configs = append(configs, c)
+		}
+	}
+
+	if opts.Resolved {
+		var err error
+		if configs, err = i.db.resolveAll(configs); err != nil {
+			return Page{}, err
+		}
 	}
 
-	return configs, nil
+	return paginate(configs, opts)
 }
 
 // Save persists a config into an in-memory datastore.
-// If there's a config with the same name, it won't be allowed
-// to be created returning ErrConfigExists.
-func (i *InMemoryConfig) Save(cfg domain.Config) error {
+// If there's a config with the same name in the namespace, it won't be allowed
+// to be created returning ErrConfigExists, and if the namespace doesn't exist,
+// it returns ErrNamespaceNotFound.
+func (i *InMemoryConfig) Save(cfg domain.Config) (domain.Config, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
+	if _, ok := i.db.namespaces[cfg.Namespace]; !ok {
+		return domain.Config{}, ErrNamespaceNotFound
+	}
+
 	// make sure there's no existing resource with the same name.
-	_, ok := i.db.configs[cfg.Name]
+	_, ok := i.db.configs[keyOf(cfg)]
 	if ok {
-		return ErrConfigExists
+		return domain.Config{}, ErrConfigExists
 	}
 
-	i.db.configs[cfg.Name] = cfg
-
-	return nil
+	return i.db.put(cfg)
 }
 
 // Get fetches a config from the in-memory datastore.
 // If the resource is not found, it returns ErrConfigNotFound.
-func (i *InMemoryConfig) Get(name string) (domain.Config, error) {
+func (i *InMemoryConfig) Get(namespace, name string) (domain.Config, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
-	config, ok := i.db.configs[name]
+	config, ok := i.db.configs[configKey{namespace, name}]
 	if !ok {
 		return domain.Config{}, ErrConfigNotFound
 	}
@@ -122,85 +247,484 @@ func (i *InMemoryConfig) Get(name string) (domain.Config, error) {
 // Update updates a config in the in-memory datastore, based on its name,
 // applying what's defined in metadata.
 // If the resource is not found, it returns ErrConfigNotFound.
-func (i *InMemoryConfig) Update(name string, metadata []byte) error {
+func (i *InMemoryConfig) Update(namespace, name string, metadata []byte) (domain.Config, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
 	// make sure the resource exists in the first place.
-	existingConfig, ok := i.db.configs[name]
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
 	if !ok {
-		return ErrConfigNotFound
+		return domain.Config{}, ErrConfigNotFound
 	}
 
 	// preserve existing config name
 	// because it's the only identifier at this point.
 	existingConfig.Metadata = metadata
-	i.db.configs[name] = existingConfig
 
-	return nil
+	return i.db.put(existingConfig)
+}
+
+// CompareAndSwap updates a config in the in-memory datastore like Update does,
+// as long as the config is still at revision.
+// If the resource is not found, it returns ErrConfigNotFound, and if it has
+// changed since revision, it returns ErrRevisionMismatch.
+func (i *InMemoryConfig) CompareAndSwap(namespace, name string, revision int64, metadata []byte) (domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
+	if !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
+
+	if existingConfig.Revision != revision {
+		return domain.Config{}, ErrRevisionMismatch
+	}
+
+	existingConfig.Metadata = metadata
+
+	return i.db.put(existingConfig)
+}
+
+// Patch updates a config in the in-memory datastore with the metadata produced
+// by patch, which runs while holding the lock, so no other change can get
+// in between reading and writing the metadata.
+// If the resource is not found, it returns ErrConfigNotFound, and if revision
+// is set, but the config has changed since then, it returns ErrRevisionMismatch.
+func (i *InMemoryConfig) Patch(namespace, name string, revision int64, patch PatchFunc) (domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
+	if !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
+
+	if revision != 0 && existingConfig.Revision != revision {
+		return domain.Config{}, ErrRevisionMismatch
+	}
+
+	metadata, err := patch(existingConfig.Metadata)
+	if err != nil {
+		return domain.Config{}, err
+	}
+	existingConfig.Metadata = metadata
+
+	return i.db.put(existingConfig)
 }
 
 // Delete removes a given config from the in-memory datastore, based on its name.
-func (i *InMemoryConfig) Delete(name string) error {
+func (i *InMemoryConfig) Delete(namespace, name string) (domain.Config, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
 	// make sure the resource exists in the first place.
-	_, ok := i.db.configs[name]
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
 	if !ok {
-		return ErrConfigNotFound
+		return domain.Config{}, ErrConfigNotFound
 	}
 
-	delete(i.db.configs, name)
+	if err := i.db.remove(existingConfig); err != nil {
+		return domain.Config{}, err
+	}
 
-	return nil
+	return existingConfig, nil
 }
 
-// Search gets all configs from the in-memory datastore that match the key/value pairs
-// in query.
-func (i *InMemoryConfig) Search(query map[string]string) ([]domain.Config, error) {
+// CompareAndDelete removes a given config from the in-memory datastore like
+// Delete does, as long as the config is still at revision.
+// If the resource is not found, it returns ErrConfigNotFound, and if it has
+// changed since revision, it returns ErrRevisionMismatch.
+func (i *InMemoryConfig) CompareAndDelete(namespace, name string, revision int64) (domain.Config, error) {
 	i.db.lock()
 	defer i.db.unlock()
 
-	var configs []domain.Config
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
+	if !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
 
-	// loop through all the stored configs
-	// and for every key/value pair in query
-	// check if the corresponding value for the given key
-	// in metadata matches the expected value.
-out:
-	for _, c := range i.db.configs {
-		for k, v := range query {
-			// remove the metadata prefix because it's redundant
-			// because the method already expects the search to be made
-			// in metadata.
-			k = strings.TrimPrefix(k, "metadata.")
-			foundValue := c.MetadataValue(k)
-			// if any of the key/value combinations
-			// doesn't find a match, skip adding the corresponding config.
-			if foundValue == nil {
-				continue out
-			}
+	if existingConfig.Revision != revision {
+		return domain.Config{}, ErrRevisionMismatch
+	}
 
-			stringFoundValue, ok := foundValue.(string)
-			if !ok || stringFoundValue != v {
-				continue out
-			}
+	if err := i.db.remove(existingConfig); err != nil {
+		return domain.Config{}, err
+	}
+
+	return existingConfig, nil
+}
+
+// Search gets a page of the configs in namespace from the in-memory datastore
+// matching expr, where a nil expr matches every config. AllNamespaces searches across
+// every namespace.
+// Instead of going through the metadata of every config, the equality conditions
+// are looked up in the inverted index of the metadata, so that only the configs
+// found there need to be evaluated against the rest of expr. Resolved configs
+// are evaluated against expr one by one instead.
+func (i *InMemoryConfig) Search(namespace string, expr query.Expr, opts ListOptions) (Page, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	if _, ok := i.db.namespaces[namespace]; namespace != AllNamespaces && !ok {
+		return Page{}, ErrNamespaceNotFound
+	}
+
+	terms, exact := indexTerms(expr)
+	keys, narrowed := i.db.index.search(terms)
+	// the index only holds the metadata of the configs themselves,
+	// without what they inherit.
+	if opts.Resolved {
+		exact, narrowed = false, false
+	}
+
+	var candidates []domain.Config
+	if narrowed {
+		for key := range keys {
+			candidates = append(candidates, i.db.configs[key])
+		}
+	} else {
+		for _, c := range i.db.configs {
+			candidates = append(candidates, c)
+		}
+	}
+
+	if opts.Resolved {
+		var err error
+		if candidates, err = i.db.resolveAll(candidates); err != nil {
+			return Page{}, err
+		}
+	}
+
+	var configs []domain.Config
+	for _, c := range candidates {
+		if namespace != AllNamespaces && c.Namespace != namespace {
+			continue
+		}
+		if !exact && !query.Match(expr, domain.WithoutSecrets(c.Metadata)) {
+			continue
 		}
-		// if the current config passes all query validations
-		// added to the list.
 		configs = append(configs, c)
 	}
 
-	return configs, nil
+	return paginate(configs, opts)
+}
+
+// Revisions fetches the history of a config from the in-memory datastore.
+// If the resource is not found, it returns ErrConfigNotFound.
+func (i *InMemoryConfig) Revisions(namespace, name string) ([]domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	key := configKey{namespace, name}
+	if _, ok := i.db.configs[key]; !ok {
+		return nil, ErrConfigNotFound
+	}
+
+	// copy the history so that it's not changed by the caller.
+	return append([]domain.Config(nil), i.db.history[key]...), nil
+}
+
+// Revision fetches a config from the in-memory datastore as it was at revision.
+// If the resource is not found, it returns ErrConfigNotFound, and if it doesn't
+// have such a revision, it returns ErrRevisionNotFound.
+func (i *InMemoryConfig) Revision(namespace, name string, revision int64) (domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	key := configKey{namespace, name}
+	if _, ok := i.db.configs[key]; !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
+
+	for _, config := range i.db.history[key] {
+		if config.Revision == revision {
+			return config, nil
+		}
+	}
+
+	return domain.Config{}, ErrRevisionNotFound
+}
+
+// Watch waits on the in-memory datastore for the config identified by name to
+// get past sinceRevision. If it's already past it, it returns right away.
+// When the config doesn't exist, and sinceRevision is set, it's taken as
+// deleted since then, returning ErrConfigNotFound, otherwise it waits
+// for the config to be created. If the namespace is not found, it
+// returns ErrNamespaceNotFound.
+func (i *InMemoryConfig) Watch(ctx context.Context, namespace, name string, sinceRevision int64) (domain.Config, error) {
+	key := configKey{namespace, name}
+
+	for {
+		i.db.lock()
+		if _, ok := i.db.namespaces[namespace]; !ok {
+			i.db.unlock()
+			return domain.Config{}, ErrNamespaceNotFound
+		}
+		config, ok := i.db.configs[key]
+		if ok && config.Revision > sinceRevision {
+			i.db.unlock()
+			return config, nil
+		}
+		if !ok && sinceRevision > 0 {
+			i.db.unlock()
+			return domain.Config{}, ErrConfigNotFound
+		}
+		changed := i.db.watch(key)
+		i.db.unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			i.db.lock()
+			i.db.unwatch(key, changed)
+			i.db.unlock()
+			return domain.Config{}, ctx.Err()
+		}
+	}
+}
+
+// ListNamespaces fetches all available namespaces from the in-memory datastore,
+// sorted by name.
+func (i *InMemoryConfig) ListNamespaces() ([]domain.Namespace, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	namespaces := make([]domain.Namespace, 0, len(i.db.namespaces))
+	for _, ns := range i.db.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	slices.SortFunc(namespaces, func(a, b domain.Namespace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return namespaces, nil
+}
+
+// CreateNamespace persists a new namespace into the in-memory datastore.
+// If there's a namespace with the same name, it returns ErrNamespaceExists.
+func (i *InMemoryConfig) CreateNamespace(name string) error {
+	i.db.lock()
+	defer i.db.unlock()
+
+	if _, ok := i.db.namespaces[name]; ok {
+		return ErrNamespaceExists
+	}
+
+	return i.db.commit(record{
+		Op: opPutNamespace,
+		Namespace: &domain.Namespace{
+			Name:      name,
+			CreatedAt: time.Now().UTC(),
+		},
+	})
+}
+
+// DeleteNamespace removes a namespace from the in-memory datastore.
+// If the namespace is not found, it returns ErrNamespaceNotFound, and if it
+// still holds any config, it returns ErrNamespaceNotEmpty.
+// The default namespace can't be deleted.
+func (i *InMemoryConfig) DeleteNamespace(name string) error {
+	i.db.lock()
+	defer i.db.unlock()
+
+	if name == domain.DefaultNamespace {
+		return ErrDefaultNamespace
+	}
+
+	ns, ok := i.db.namespaces[name]
+	if !ok {
+		return ErrNamespaceNotFound
+	}
+
+	for key := range i.db.configs {
+		if key.namespace == name {
+			return ErrNamespaceNotEmpty
+		}
+	}
+
+	return i.db.commit(record{Op: opDeleteNamespace, Namespace: &ns})
+}
+
+// configKey identifies a config within the inMemoryDBState.
+type configKey struct {
+	namespace string
+	name      string
+}
+
+// keyOf gets the key identifying cfg.
+func keyOf(cfg domain.Config) configKey {
+	return configKey{cfg.Namespace, cfg.Name}
 }
 
 // inMemoryDBState holds the in-memory DB state for the lifecycle
-// of the application.
+// of a repository instance.
 type inMemoryDBState struct {
 	// used to protect the map from race conditions.
-	mu      sync.Mutex
-	configs map[string]domain.Config
+	mu         sync.Mutex
+	namespaces map[string]domain.Namespace
+	configs    map[configKey]domain.Config
+	// history holds every revision of each config, from the oldest to the current one.
+	history map[configKey][]domain.Config
+	// index is the inverted index of the metadata of the configs.
+	index *metadataIndex
+	// watchers holds a channel for every config being watched,
+	// which is closed as soon as the config changes.
+	watchers map[configKey]*watcher
+	// revision is the store-wide revision of the last change.
+	revision int64
+	// journal is optional, and when set, every change is recorded
+	// in it before being applied to the state.
+	journal journal
+}
+
+// newInMemoryDBState creates an empty state, holding only the default namespace.
+func newInMemoryDBState() *inMemoryDBState {
+	return &inMemoryDBState{
+		namespaces: map[string]domain.Namespace{
+			domain.DefaultNamespace: {Name: domain.DefaultNamespace},
+		},
+		configs:  make(map[configKey]domain.Config),
+		history:  make(map[configKey][]domain.Config),
+		index:    newMetadataIndex(),
+		watchers: make(map[configKey]*watcher),
+	}
+}
+
+// put stores cfg in the state as a new revision, replacing any config with
+// the same name in its namespace, as long as its parents can be inherited from.
+// It returns cfg as it's stored. Callers must hold the lock.
+func (i *inMemoryDBState) put(cfg domain.Config) (domain.Config, error) {
+	if err := domain.CheckParents(cfg, i.lookup(cfg.Namespace)); err != nil {
+		return domain.Config{}, err
+	}
+
+	cfg.Revision = i.revision + 1
+	cfg.UpdatedAt = time.Now().UTC()
+
+	if err := i.commit(record{Op: opPut, Config: cfg}); err != nil {
+		return domain.Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// remove deletes cfg, along with its history, from the state, as long as
+// no other config inherits from it. Callers must hold the lock.
+func (i *inMemoryDBState) remove(cfg domain.Config) error {
+	if children := i.children(cfg.Namespace, cfg.Name); len(children) > 0 {
+		return fmt.Errorf("%w: %s", ErrConfigHasChildren, strings.Join(children, ", "))
+	}
+
+	return i.commit(record{
+		Op: opDelete,
+		Config: domain.Config{
+			Namespace: cfg.Namespace,
+			Name:      cfg.Name,
+			Revision:  i.revision + 1,
+			UpdatedAt: time.Now().UTC(),
+		},
+	})
+}
+
+// commit records rec in the journal, if there's any, and only then
+// applies it to the state, so that no change is ever visible before
+// it's durable.
+func (i *inMemoryDBState) commit(rec record) error {
+	if i.journal == nil {
+		i.apply(rec)
+		return nil
+	}
+
+	if err := i.journal.write(&rec); err != nil {
+		return err
+	}
+	i.apply(rec)
+	i.journal.applied(i)
+
+	return nil
+}
+
+// batch runs fn, which makes any number of changes, making them all durable
+// at once afterward, unless fn fails. Callers must hold the lock.
+func (i *inMemoryDBState) batch(fn func() error) error {
+	if i.journal == nil {
+		return fn()
+	}
+
+	return i.journal.batch(i, fn)
+}
+
+// apply mutates the state according to rec.
+func (i *inMemoryDBState) apply(rec record) {
+	// configs stored before namespaces existed belong to the default one.
+	if rec.Config.Namespace == "" {
+		rec.Config.Namespace = domain.DefaultNamespace
+	}
+	key := keyOf(rec.Config)
+
+	switch rec.Op {
+	case opPut:
+		i.configs[key] = rec.Config
+		i.history[key] = append(i.history[key], rec.Config)
+		i.index.add(rec.Config)
+	case opDelete:
+		delete(i.configs, key)
+		delete(i.history, key)
+		i.index.remove(key)
+	}
+
+	// let the watchers of the config know it has changed.
+	if w, ok := i.watchers[key]; ok && (rec.Op == opPut || rec.Op == opDelete) {
+		close(w.ch)
+		delete(i.watchers, key)
+	}
+
+	switch rec.Op {
+	case opPutNamespace:
+		i.namespaces[rec.Namespace.Name] = *rec.Namespace
+	case opDeleteNamespace:
+		delete(i.namespaces, rec.Namespace.Name)
+	}
+
+	if rec.Config.Revision > i.revision {
+		i.revision = rec.Config.Revision
+	}
+}
+
+// watcher is the channel shared by everyone watching a config.
+type watcher struct {
+	ch chan struct{}
+	// n is how many are still waiting on ch.
+	n int
+}
+
+// watch gets a channel that's closed as soon as the config identified by key
+// changes. Callers must hold the lock, and call unwatch if they give up on it.
+func (i *inMemoryDBState) watch(key configKey) <-chan struct{} {
+	w, ok := i.watchers[key]
+	if !ok {
+		w = &watcher{ch: make(chan struct{})}
+		i.watchers[key] = w
+	}
+	w.n++
+
+	return w.ch
+}
+
+// unwatch gives up on the channel ch got from watch, removing it once no one
+// is waiting on it anymore. Callers must hold the lock.
+func (i *inMemoryDBState) unwatch(key configKey, ch <-chan struct{}) {
+	w, ok := i.watchers[key]
+	if !ok || w.ch != ch {
+		// the config has changed in the meantime, which removed the channel already.
+		return
+	}
+
+	w.n--
+	if w.n == 0 {
+		delete(i.watchers, key)
+	}
 }
 
 // lock the operation on the db until the token is released.
@@ -213,16 +737,3 @@ func (i *inMemoryDBState) lock() {
 func (i *inMemoryDBState) unlock() {
 	i.mu.Unlock()
 }
-
-// getInMemoryDBState is a singleton to get the same
-// in-memory DB state.
-func getInMemoryDBState() *inMemoryDBState {
-	// ensures that the db is initialized only once.
-	initDBOnce.Do(func() {
-		db = &inMemoryDBState{
-			configs: make(map[string]domain.Config),
-		}
-	})
-
-	return db
-}
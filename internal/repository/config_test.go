@@ -1,24 +1,31 @@
 package repository_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestInMemoryConfig_List(t *testing.T) {
 	customData := test.GenerateInMemoryTestData(t)
 	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
 
-	configs, err := repo.List()
+	page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
 	require.NoError(t, err)
 
 	t.Run("it returns the expected number of configs", func(t *testing.T) {
 		wantLen := len(customData)
-		require.Equal(t, wantLen, len(configs))
+		require.Equal(t, wantLen, len(page.Configs))
+		assert.Equal(t, wantLen, page.Total)
 	})
 }
 
@@ -27,15 +34,25 @@ func TestInMemoryConfig_Save(t *testing.T) {
 		repo := repository.NewInMemoryConfig(repository.WithCustomData(make(map[string]domain.Config)))
 
 		toCreateConfig := domain.Config{
-			Name:     "config 1",
-			Metadata: []byte(`{"foo": "bar"}`),
+			Namespace: domain.DefaultNamespace,
+			Name:      "config 1",
+			Metadata:  []byte(`{"foo": "bar"}`),
 		}
-		require.NoError(t, repo.Save(toCreateConfig))
+		_, err := repo.Save(toCreateConfig)
+		require.NoError(t, err)
 
 		t.Run("created config is the expected config", func(t *testing.T) {
-			config, err := repo.Get(toCreateConfig.Name)
+			config, err := repo.Get(domain.DefaultNamespace, toCreateConfig.Name)
 			require.NoError(t, err)
-			assert.Equal(t, toCreateConfig, config)
+			assert.Equal(t, toCreateConfig.Name, config.Name)
+			assert.Equal(t, toCreateConfig.Metadata, config.Metadata)
+		})
+
+		t.Run("created config has a revision", func(t *testing.T) {
+			config, err := repo.Get(domain.DefaultNamespace, toCreateConfig.Name)
+			require.NoError(t, err)
+			assert.NotZero(t, config.Revision)
+			assert.NotZero(t, config.UpdatedAt)
 		})
 	})
 
@@ -51,13 +68,15 @@ func TestInMemoryConfig_Save(t *testing.T) {
 
 		// try saving another config with an existing name
 		toCreateConfig := domain.Config{
-			Name:     "config 1",
-			Metadata: []byte(`{"another": "metadata"}`),
+			Namespace: domain.DefaultNamespace,
+			Name:      "config 1",
+			Metadata:  []byte(`{"another": "metadata"}`),
 		}
-		require.Error(t, repo.Save(toCreateConfig))
+		_, err := repo.Save(toCreateConfig)
+		require.Error(t, err)
 
 		t.Run("existing config is not replaced", func(t *testing.T) {
-			config, err := repo.Get(toCreateConfig.Name)
+			config, err := repo.Get(domain.DefaultNamespace, toCreateConfig.Name)
 			require.NoError(t, err)
 			assert.NotContains(t, string(config.Metadata), "another")
 		})
@@ -71,7 +90,7 @@ func TestInMemoryConfig_Get(t *testing.T) {
 	t.Run("config is found", func(t *testing.T) {
 		wantName := test.ConfigName1
 
-		config, err := repo.Get(wantName)
+		config, err := repo.Get(domain.DefaultNamespace, wantName)
 		require.NoError(t, err)
 
 		t.Run("it returns the expected config", func(t *testing.T) {
@@ -80,7 +99,7 @@ func TestInMemoryConfig_Get(t *testing.T) {
 	})
 
 	t.Run("config is not found", func(t *testing.T) {
-		config, err := repo.Get("invalid")
+		config, err := repo.Get(domain.DefaultNamespace, "invalid")
 
 		t.Run("it's the expected error type", func(t *testing.T) {
 			assert.ErrorIs(t, err, repository.ErrConfigNotFound)
@@ -105,19 +124,24 @@ func TestInMemoryConfig_Update(t *testing.T) {
 		config1 := customData[wantName]
 		metadataBeforeUpdate := config1.Metadata
 
-		require.NoError(t, repo.Update(wantName, wantMetadata))
+		updated, err := repo.Update(domain.DefaultNamespace, wantName, wantMetadata)
+		require.NoError(t, err)
 
-		gotConfig, err := repo.Get(wantName)
+		gotConfig, err := repo.Get(domain.DefaultNamespace, wantName)
 		require.NoError(t, err)
 
 		t.Run("metadata is updated", func(t *testing.T) {
 			assert.Equal(t, wantMetadata, gotConfig.Metadata)
 			assert.NotEqual(t, metadataBeforeUpdate, gotConfig.Metadata)
 		})
+
+		t.Run("it returns the config as it's stored", func(t *testing.T) {
+			assert.Equal(t, gotConfig, updated)
+		})
 	})
 
 	t.Run("config not found", func(t *testing.T) {
-		err := repo.Update("nope", nil)
+		_, err := repo.Update(domain.DefaultNamespace, "nope", nil)
 
 		t.Run("not found error", func(t *testing.T) {
 			assert.ErrorIs(t, err, repository.ErrConfigNotFound)
@@ -130,25 +154,33 @@ func TestInMemoryConfig_Delete(t *testing.T) {
 	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
 
 	t.Run("config is deleted", func(t *testing.T) {
-		configs, err := repo.List()
+		page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
 		require.NoError(t, err)
 
 		// the expected number of configs available
 		// should drop by 1.
-		wantLen := len(configs) - 1
+		wantLen := len(page.Configs) - 1
 
-		require.NoError(t, repo.Delete(test.ConfigName1))
+		existing, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+
+		deleted, err := repo.Delete(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+
+		t.Run("it returns the config as it was", func(t *testing.T) {
+			assert.Equal(t, existing, deleted)
+		})
 
 		t.Run("it returns the expected number of configs", func(t *testing.T) {
-			updatedConfigList, err := repo.List()
+			updatedPage, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
 			require.NoError(t, err)
 
-			assert.Len(t, updatedConfigList, wantLen)
+			assert.Len(t, updatedPage.Configs, wantLen)
 		})
 	})
 
 	t.Run("config not found", func(t *testing.T) {
-		err := repo.Delete("nope")
+		_, err := repo.Delete(domain.DefaultNamespace, "nope")
 
 		t.Run("not found error", func(t *testing.T) {
 			assert.ErrorIs(t, err, repository.ErrConfigNotFound)
@@ -170,60 +202,702 @@ func TestInMemoryConfig_Search(t *testing.T) {
 
 	tests := []struct {
 		name           string
-		query          map[string]string
+		query          string
 		wantConfigsLen int
 	}{
 		{
 			name:           "matching configs are found",
-			query:          map[string]string{"abc": "123"},
+			query:          `abc = 123`,
 			wantConfigsLen: 2,
 		},
 		{
 			name:           "only one matching config is found",
-			query:          map[string]string{"enabled": "true"},
+			query:          `enabled = true`,
 			wantConfigsLen: 1,
 		},
 		{
 			name:           "no matching config is found",
-			query:          map[string]string{"enabled": "false"},
+			query:          `enabled = false`,
 			wantConfigsLen: 0,
 		},
 		{
 			name:           "only one matching config with nested keys is found",
-			query:          map[string]string{"allergens.eggs": "true"},
+			query:          `allergens.eggs = true`,
 			wantConfigsLen: 1,
 		},
 		{
-			name: "only 1 config matching multiple key/value pairs",
-			query: map[string]string{
-				"abc":         "123",
-				"obj.aaa.bbb": "ccc",
-			},
+			name:           "only 1 config matching multiple key/value pairs",
+			query:          `abc = 123 and obj.aaa.bbb = ccc`,
 			wantConfigsLen: 1,
 		},
 		{
-			name: "not corresponding match multiple key/value pairs",
-			query: map[string]string{
-				"abc":         "123",
-				"obj.aaa.bbb": "ccc",
-				"enabled":     "false",
-			},
+			name:           "not corresponding match multiple key/value pairs",
+			query:          `abc = 123 and obj.aaa.bbb = ccc and enabled = false`,
 			wantConfigsLen: 0,
 		},
 		{
-			name: "non-string value metadata doesn't cause panic",
-			query: map[string]string{
-				"dont-panic": "8",
-			},
+			name:           "numbers are compared numerically",
+			query:          `dont-panic = 8.0`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "not equal matches missing values too",
+			query:          `enabled != true`,
+			wantConfigsLen: 3,
+		},
+		{
+			name:           "in matches any of the values",
+			query:          `abc in (123, 456)`,
+			wantConfigsLen: 2,
+		},
+		{
+			name:           "prefix",
+			query:          `foo prefix ba`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "case-insensitive suffix",
+			query:          `foo suffix:i AR`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "case-insensitive equality",
+			query:          `foo eq:i BAR`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "contains",
+			query:          `fats.trans-fat contains g`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "regex",
+			query:          `obj.aaa ~ "^b+$"`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "exists",
+			query:          `obj exists`,
+			wantConfigsLen: 2,
+		},
+		{
+			name:           "missing",
+			query:          `obj missing`,
+			wantConfigsLen: 2,
+		},
+		{
+			name:           "greater than a number",
+			query:          `dont-panic gt 7`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "greater than a numeric string",
+			query:          `calories > 200.5`,
+			wantConfigsLen: 1,
+		},
+		{
+			name:           "lower than",
+			query:          `calories lt 200`,
+			wantConfigsLen: 0,
+		},
+		{
+			name:           "or group",
+			query:          `foo = bar or enabled = true`,
+			wantConfigsLen: 2,
+		},
+		{
+			name:           "or group along with other conditions",
+			query:          `(foo = bar or enabled = true or calories exists) and abc = 123`,
+			wantConfigsLen: 2,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			foundConfigs, err := repo.Search(tt.query)
+			expr, err := query.Parse(tt.query)
+			require.NoError(t, err)
+
+			page, err := repo.Search(domain.DefaultNamespace, expr, repository.ListOptions{})
 			require.NoError(t, err)
 
-			assert.Len(t, foundConfigs, tt.wantConfigsLen)
+			assert.Len(t, page.Configs, tt.wantConfigsLen)
 		})
 	}
+
+	t.Run("nil query matches every config", func(t *testing.T) {
+		page, err := repo.Search(domain.DefaultNamespace, nil, repository.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, page.Configs, len(customData))
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(map[string]domain.Config{
+			"typed": {
+				Name:     "typed",
+				Metadata: []byte(`{"debug": true, "replicas": 3, "owner": null, "hosts": ["eu-1", "us-1"], "ports": [[80, 443], 8080], "shards": [{"id": "a"}]}`),
+			},
+			"strings": {
+				Name:     "strings",
+				Metadata: []byte(`{"debug": "true", "replicas": "3.0", "owner": "null", "hosts": "eu-1"}`),
+			},
+		}))
+
+		tests := []struct {
+			name      string
+			query     string
+			wantNames []string
+		}{
+			{name: "booleans", query: `debug = true`, wantNames: []string{"strings", "typed"}},
+			{name: "numbers", query: `replicas = 3`, wantNames: []string{"typed"}},
+			{name: "numbers however they're written", query: `replicas = 3.0`, wantNames: []string{"strings", "typed"}},
+			{name: "null", query: `owner = null`, wantNames: []string{"strings", "typed"}},
+			{name: "null among other values", query: `owner in (nobody, null)`, wantNames: []string{"strings", "typed"}},
+			{name: "any array element", query: `hosts = us-1`, wantNames: []string{"typed"}},
+			{name: "array element by index", query: `hosts.0 = eu-1`, wantNames: []string{"typed"}},
+			{name: "array element out of range", query: `hosts.2 exists`, wantNames: []string{}},
+			{name: "nested array element", query: `ports = 443`, wantNames: []string{"typed"}},
+			{name: "nested array element by index", query: `ports.0.1 = 443 and ports.1 = 8080`, wantNames: []string{"typed"}},
+			{name: "nested array element at the wrong index", query: `ports.1 = 443`, wantNames: []string{}},
+			{name: "key in an array element", query: `shards.0.id = a`, wantNames: []string{"typed"}},
+			{name: "numeric comparison of any array element", query: `ports > 1000`, wantNames: []string{"typed"}},
+			{name: "string comparison of any array element", query: `hosts prefix us`, wantNames: []string{"typed"}},
+			{name: "not equal to any array element", query: `hosts != us-1`, wantNames: []string{"strings"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := repo.Search(domain.DefaultNamespace, mustParse(t, tt.query), repository.ListOptions{})
+				require.NoError(t, err)
+
+				names := make([]string, 0, len(page.Configs))
+				for _, c := range page.Configs {
+					names = append(names, c.Name)
+				}
+				assert.Equal(t, tt.wantNames, names)
+			})
+		}
+	})
+
+	t.Run("secret values aren't searchable", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(map[string]domain.Config{
+			"payments": {
+				Name:     "payments",
+				Metadata: []byte(`{"apiKey": "encrypted:c2VjcmV0", "keys": ["public", "encrypted:c2VjcmV0"], "region": "eu"}`),
+			},
+		}))
+
+		for _, q := range []string{`apiKey exists`, `apiKey = "encrypted:c2VjcmV0"`, `apiKey prefix encrypted`, `keys = "encrypted:c2VjcmV0"`, `keys.1 exists`} {
+			page, err := repo.Search(domain.DefaultNamespace, mustParse(t, q), repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Configs, q)
+		}
+
+		page, err := repo.Search(domain.DefaultNamespace, mustParse(t, `region = eu and keys = public`), repository.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, page.Configs, 1)
+	})
+
+	t.Run("updated metadata is searchable", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+		_, err := repo.Update(domain.DefaultNamespace, test.ConfigName2, []byte(`{"enabled": "false"}`))
+		require.NoError(t, err)
+
+		page, err := repo.Search(domain.DefaultNamespace, mustParse(t, `enabled = false`), repository.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, page.Configs, 1)
+		assert.Equal(t, test.ConfigName2, page.Configs[0].Name)
+
+		t.Run("previous metadata isn't found anymore", func(t *testing.T) {
+			page, err := repo.Search(domain.DefaultNamespace, mustParse(t, `enabled = true`), repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Configs)
+		})
+	})
+
+	t.Run("deleted config isn't found", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+		_, err := repo.Delete(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+
+		page, err := repo.Search(domain.DefaultNamespace, mustParse(t, `abc = 123`), repository.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, page.Configs, 1)
+		assert.Equal(t, test.ConfigName2, page.Configs[0].Name)
+	})
+}
+
+// mustParse parses the query expression in input.
+func mustParse(t testing.TB, input string) query.Expr {
+	t.Helper()
+
+	expr, err := query.Parse(input)
+	require.NoError(t, err)
+
+	return expr
+}
+
+// searchByScanning searches repo the way it was done before the inverted
+// index, by going through the metadata of every config.
+func searchByScanning(repo repository.Config, query map[string]string) []domain.Config {
+	page, _ := repo.List(domain.DefaultNamespace, repository.ListOptions{})
+
+	var found []domain.Config
+out:
+	for _, c := range page.Configs {
+		for k, v := range query {
+			value, ok := c.MetadataValue(k).(string)
+			if !ok || value != v {
+				continue out
+			}
+		}
+		found = append(found, c)
+	}
+
+	return found
+}
+
+func BenchmarkInMemoryConfig_Search(b *testing.B) {
+	terms := map[string]string{
+		"team":            "team-7",
+		"feature.enabled": "true",
+	}
+	expr := mustParse(b, `team = team-7 and feature.enabled = true`)
+
+	for _, size := range []int{10_000, 100_000} {
+		configs := make(map[string]domain.Config, size)
+		for n := range size {
+			name := fmt.Sprintf("config-%d", n)
+			configs[name] = domain.Config{
+				Name: name,
+				Metadata: []byte(fmt.Sprintf(
+					`{"team": "team-%d", "feature": {"enabled": "%t", "rollout": "%d"}}`,
+					n%100, n%2 == 0, n%10,
+				)),
+			}
+		}
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(configs))
+
+		b.Run(fmt.Sprintf("index/%d", size), func(b *testing.B) {
+			for range b.N {
+				if _, err := repo.Search(domain.DefaultNamespace, expr, repository.ListOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan/%d", size), func(b *testing.B) {
+			for range b.N {
+				searchByScanning(repo, terms)
+			}
+		})
+	}
+}
+
+func TestInMemoryConfig_Revisions(t *testing.T) {
+	customData := test.GenerateInMemoryTestData(t)
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+	_, err := repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "first"}`))
+	require.NoError(t, err)
+	_, err = repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "second"}`))
+	require.NoError(t, err)
+
+	t.Run("every revision is kept", func(t *testing.T) {
+		revisions, err := repo.Revisions(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		require.Len(t, revisions, 3)
+
+		t.Run("revisions are ordered from the oldest", func(t *testing.T) {
+			assert.Less(t, revisions[0].Revision, revisions[1].Revision)
+			assert.Less(t, revisions[1].Revision, revisions[2].Revision)
+		})
+
+		t.Run("the last revision is the current config", func(t *testing.T) {
+			current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+			require.NoError(t, err)
+			assert.Equal(t, current, revisions[2])
+		})
+	})
+
+	t.Run("config not found", func(t *testing.T) {
+		_, err := repo.Revisions(domain.DefaultNamespace, "nope")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+
+	t.Run("history is dropped along with the config", func(t *testing.T) {
+		_, err := repo.Delete(domain.DefaultNamespace, test.ConfigName2)
+		require.NoError(t, err)
+		_, err = repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: test.ConfigName2, Metadata: []byte(`{}`)})
+		require.NoError(t, err)
+
+		revisions, err := repo.Revisions(domain.DefaultNamespace, test.ConfigName2)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+	})
+}
+
+func TestInMemoryConfig_Revision(t *testing.T) {
+	customData := test.GenerateInMemoryTestData(t)
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+	original, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+	_, err = repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "updated"}`))
+	require.NoError(t, err)
+
+	t.Run("revision is found", func(t *testing.T) {
+		config, err := repo.Revision(domain.DefaultNamespace, test.ConfigName1, original.Revision)
+		require.NoError(t, err)
+		assert.Equal(t, original.Metadata, config.Metadata)
+	})
+
+	t.Run("revision is not found", func(t *testing.T) {
+		_, err := repo.Revision(domain.DefaultNamespace, test.ConfigName1, 9999)
+		assert.ErrorIs(t, err, repository.ErrRevisionNotFound)
+	})
+
+	t.Run("config is not found", func(t *testing.T) {
+		_, err := repo.Revision(domain.DefaultNamespace, "nope", original.Revision)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
+
+func TestInMemoryConfig_CompareAndSwap(t *testing.T) {
+	customData := test.GenerateInMemoryTestData(t)
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	t.Run("config is updated at the expected revision", func(t *testing.T) {
+		wantMetadata := []byte(`{"got": "swapped!"}`)
+		_, err := repo.CompareAndSwap(domain.DefaultNamespace, test.ConfigName1, current.Revision, wantMetadata)
+		require.NoError(t, err)
+
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Equal(t, wantMetadata, config.Metadata)
+	})
+
+	t.Run("stale revision is rejected", func(t *testing.T) {
+		_, err := repo.CompareAndSwap(domain.DefaultNamespace, test.ConfigName1, current.Revision, []byte(`{"got": "clobbered!"}`))
+		assert.ErrorIs(t, err, repository.ErrRevisionMismatch)
+
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.NotContains(t, string(config.Metadata), "clobbered")
+	})
+
+	t.Run("config not found", func(t *testing.T) {
+		_, err := repo.CompareAndSwap(domain.DefaultNamespace, "nope", current.Revision, nil)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
+
+func TestInMemoryConfig_CompareAndDelete(t *testing.T) {
+	customData := test.GenerateInMemoryTestData(t)
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	t.Run("stale revision is rejected", func(t *testing.T) {
+		_, err := repo.CompareAndDelete(domain.DefaultNamespace, test.ConfigName1, current.Revision-1)
+		assert.ErrorIs(t, err, repository.ErrRevisionMismatch)
+
+		_, err = repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("config is deleted at the expected revision", func(t *testing.T) {
+		_, err := repo.CompareAndDelete(domain.DefaultNamespace, test.ConfigName1, current.Revision)
+		require.NoError(t, err)
+
+		_, err = repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+
+	t.Run("config not found", func(t *testing.T) {
+		_, err := repo.CompareAndDelete(domain.DefaultNamespace, "nope", current.Revision)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
+
+func TestInMemoryConfig_Watch(t *testing.T) {
+	customData := test.GenerateInMemoryTestData(t)
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+	current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+	require.NoError(t, err)
+
+	t.Run("config already past the revision is returned right away", func(t *testing.T) {
+		config, err := repo.Watch(context.Background(), domain.DefaultNamespace, test.ConfigName1, current.Revision-1)
+		require.NoError(t, err)
+		assert.Equal(t, current, config)
+	})
+
+	t.Run("watch times out without changes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := repo.Watch(ctx, domain.DefaultNamespace, test.ConfigName1, current.Revision)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("namespace not found", func(t *testing.T) {
+		_, err := repo.Watch(context.Background(), "nope", test.ConfigName1, 0)
+		assert.ErrorIs(t, err, repository.ErrNamespaceNotFound)
+	})
+
+	t.Run("watch giving up leaves the others waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelled := make(chan error)
+		go func() {
+			_, err := repo.Watch(ctx, domain.DefaultNamespace, test.ConfigName1, current.Revision)
+			cancelled <- err
+		}()
+		done := make(chan domain.Config)
+		go func() {
+			config, err := repo.Watch(context.Background(), domain.DefaultNamespace, test.ConfigName1, current.Revision)
+			assert.NoError(t, err)
+			done <- config
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-cancelled, context.Canceled)
+
+		_, err := repo.Update(domain.DefaultNamespace, test.ConfigName1, []byte(`{"foo": "still watched"}`))
+		require.NoError(t, err)
+
+		current = <-done
+		assert.Equal(t, []byte(`{"foo": "still watched"}`), current.Metadata)
+	})
+
+	t.Run("watch is woken up by an update", func(t *testing.T) {
+		done := make(chan domain.Config)
+		go func() {
+			config, err := repo.Watch(context.Background(), domain.DefaultNamespace, test.ConfigName1, current.Revision)
+			assert.NoError(t, err)
+			done <- config
+		}()
+
+		wantMetadata := []byte(`{"foo": "watched"}`)
+		_, err := repo.Update(domain.DefaultNamespace, test.ConfigName1, wantMetadata)
+		require.NoError(t, err)
+
+		config := <-done
+		assert.Equal(t, wantMetadata, config.Metadata)
+		assert.Greater(t, config.Revision, current.Revision)
+		current = config
+	})
+
+	t.Run("watch is woken up by a creation", func(t *testing.T) {
+		done := make(chan domain.Config)
+		go func() {
+			config, err := repo.Watch(context.Background(), domain.DefaultNamespace, "new-config", 0)
+			assert.NoError(t, err)
+			done <- config
+		}()
+
+		_, err := repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: "new-config", Metadata: []byte(`{}`)})
+		require.NoError(t, err)
+
+		config := <-done
+		assert.Equal(t, "new-config", config.Name)
+	})
+
+	t.Run("watch is woken up by a deletion", func(t *testing.T) {
+		done := make(chan error)
+		go func() {
+			_, err := repo.Watch(context.Background(), domain.DefaultNamespace, test.ConfigName1, current.Revision)
+			done <- err
+		}()
+
+		_, err := repo.Delete(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.ErrorIs(t, <-done, repository.ErrConfigNotFound)
+	})
+}
+
+func TestInMemoryConfig_Patch(t *testing.T) {
+	t.Run("config is patched", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+		_, err := repo.Patch(domain.DefaultNamespace, test.ConfigName1, 0, func(metadata []byte) ([]byte, error) {
+			return domain.MergePatch(metadata, []byte(`{"foo": "patched"}`))
+		})
+		require.NoError(t, err)
+
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Equal(t, "patched", config.MetadataValue("foo"))
+		assert.Equal(t, "bbb", config.MetadataValue("obj.aaa"))
+	})
+
+	t.Run("concurrent patches don't lose updates", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(map[string]domain.Config{
+			test.ConfigName1: {Name: test.ConfigName1, Metadata: []byte(`{}`)},
+		}))
+
+		patches := 50
+
+		var wg sync.WaitGroup
+		for n := 0; n < patches; n++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				patch := []byte(fmt.Sprintf(`{"key-%d": "value"}`, n))
+				_, err := repo.Patch(domain.DefaultNamespace, test.ConfigName1, 0, func(metadata []byte) ([]byte, error) {
+					return domain.MergePatch(metadata, patch)
+				})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		for n := 0; n < patches; n++ {
+			assert.Equal(t, "value", config.MetadataValue(fmt.Sprintf("key-%d", n)))
+		}
+	})
+
+	t.Run("failing patch leaves the config untouched", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+		before, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+
+		wantErr := errors.New("oops")
+		_, err = repo.Patch(domain.DefaultNamespace, test.ConfigName1, 0, func(metadata []byte) ([]byte, error) {
+			return nil, wantErr
+		})
+		assert.ErrorIs(t, err, wantErr)
+
+		after, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("stale revision is rejected", func(t *testing.T) {
+		customData := test.GenerateInMemoryTestData(t)
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(customData))
+
+		_, err := repo.Patch(domain.DefaultNamespace, test.ConfigName1, 9999, func(metadata []byte) ([]byte, error) {
+			return metadata, nil
+		})
+		assert.ErrorIs(t, err, repository.ErrRevisionMismatch)
+	})
+
+	t.Run("config not found", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(make(map[string]domain.Config)))
+
+		_, err := repo.Patch(domain.DefaultNamespace, "nope", 0, func(metadata []byte) ([]byte, error) {
+			return metadata, nil
+		})
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+}
+
+func TestInMemoryConfig_Namespaces(t *testing.T) {
+	const team = "team-a"
+
+	repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+
+	t.Run("default namespace always exists", func(t *testing.T) {
+		namespaces, err := repo.ListNamespaces()
+		require.NoError(t, err)
+		require.Len(t, namespaces, 1)
+		assert.Equal(t, domain.DefaultNamespace, namespaces[0].Name)
+	})
+
+	t.Run("namespace is created", func(t *testing.T) {
+		require.NoError(t, repo.CreateNamespace(team))
+
+		namespaces, err := repo.ListNamespaces()
+		require.NoError(t, err)
+		assert.Len(t, namespaces, 2)
+
+		t.Run("it can't be created twice", func(t *testing.T) {
+			assert.ErrorIs(t, repo.CreateNamespace(team), repository.ErrNamespaceExists)
+		})
+	})
+
+	t.Run("namespaces are listed by name", func(t *testing.T) {
+		repo := repository.NewInMemoryConfig()
+		for _, name := range []string{"zeta", "alpha", "mu"} {
+			require.NoError(t, repo.CreateNamespace(name))
+		}
+
+		for range 5 {
+			namespaces, err := repo.ListNamespaces()
+			require.NoError(t, err)
+
+			var names []string
+			for _, ns := range namespaces {
+				names = append(names, ns.Name)
+			}
+			assert.Equal(t, []string{"alpha", domain.DefaultNamespace, "mu", "zeta"}, names)
+		}
+	})
+
+	t.Run("configs are isolated by namespace", func(t *testing.T) {
+		_, err := repo.Save(domain.Config{
+			Namespace: team,
+			Name:      test.ConfigName1,
+			Metadata:  []byte(`{"owner": "team-a"}`),
+		})
+		require.NoError(t, err)
+
+		teamConfig, err := repo.Get(team, test.ConfigName1)
+		require.NoError(t, err)
+		defaultConfig, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.NotEqual(t, teamConfig.Metadata, defaultConfig.Metadata)
+
+		page, err := repo.List(team, repository.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, page.Configs, 1)
+
+		t.Run("search is scoped to the namespace", func(t *testing.T) {
+			page, err := repo.Search(team, mustParse(t, `owner = team-a`), repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Len(t, page.Configs, 1)
+
+			page, err = repo.Search(domain.DefaultNamespace, mustParse(t, `owner = team-a`), repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Configs)
+		})
+
+		t.Run("search goes across every namespace", func(t *testing.T) {
+			page, err := repo.Search(repository.AllNamespaces, mustParse(t, `owner = team-a`), repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Len(t, page.Configs, 1)
+		})
+	})
+
+	t.Run("non-empty namespace can't be deleted", func(t *testing.T) {
+		assert.ErrorIs(t, repo.DeleteNamespace(team), repository.ErrNamespaceNotEmpty)
+	})
+
+	t.Run("empty namespace is deleted", func(t *testing.T) {
+		_, err := repo.Delete(team, test.ConfigName1)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteNamespace(team))
+
+		_, err = repo.List(team, repository.ListOptions{})
+		assert.ErrorIs(t, err, repository.ErrNamespaceNotFound)
+	})
+
+	t.Run("default namespace can't be deleted", func(t *testing.T) {
+		assert.ErrorIs(t, repo.DeleteNamespace(domain.DefaultNamespace), repository.ErrDefaultNamespace)
+	})
+
+	t.Run("config can't be saved in a missing namespace", func(t *testing.T) {
+		_, err := repo.Save(domain.Config{Namespace: "nope", Name: "config", Metadata: []byte(`{}`)})
+		assert.ErrorIs(t, err, repository.ErrNamespaceNotFound)
+	})
 }
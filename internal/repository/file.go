@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	// schemasFileName is the name of the file holding the schemas.
+	schemasFileName = "schemas.json"
+	// webhooksFileName is the name of the file holding the webhooks.
+	webhooksFileName = "webhooks.json"
+)
+
+// defaultCompactionThreshold is the number of records the write-ahead log
+// holds before being compacted into a snapshot.
+const defaultCompactionThreshold = 1000
+
+// NewFileConfig returns a FileConfig repository instance storing its data in dir.
+// The existing data in dir, if there's any, is loaded before returning.
+// Use FileOption options to use custom settings.
+func NewFileConfig(dir string, opts ...FileOption) (*FileConfig, error) {
+	c := &FileConfig{
+		compactionThreshold: defaultCompactionThreshold,
+	}
+
+	// apply options sent by the user if there's any.
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	state := newInMemoryDBState()
+
+	w, err := openWAL(dir, c.compactionThreshold, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data from %s: %w", dir, err)
+	}
+	state.journal = w
+
+	c.InMemoryConfig = &InMemoryConfig{db: state}
+	c.wal = w
+
+	return c, nil
+}
+
+// FileOption defines the optional params for the
+// NewFileConfig constructor.
+type FileOption func(c *FileConfig)
+
+// WithCompactionThreshold sets the number of changes the write-ahead log
+// accumulates before being compacted into a snapshot.
+// A threshold of 0 disables compaction.
+func WithCompactionThreshold(threshold int) FileOption {
+	return func(c *FileConfig) {
+		c.compactionThreshold = threshold
+	}
+}
+
+// FileConfig defines the durable implementation of Config.
+//
+// It serves everything from memory just like InMemoryConfig, but every
+// change is first appended to a write-ahead log on local disk, which is
+// periodically compacted into a snapshot, so that the data survives restarts.
+type FileConfig struct {
+	*InMemoryConfig
+	wal                 *wal
+	compactionThreshold int
+}
+
+// Close releases the files held by the repository.
+// It must not be used after being closed.
+func (f *FileConfig) Close() error {
+	f.db.lock()
+	defer f.db.unlock()
+
+	return f.wal.close()
+}
+
+// NewFileSchema returns a FileSchema repository instance storing the schemas
+// in dir, loading the ones stored there already, if there's any.
+func NewFileSchema(dir string) (*FileSchema, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	var schemas []domain.Schema
+	if err := readFileJSON(dir, schemasFileName, &schemas); err != nil {
+		return nil, err
+	}
+
+	f := &FileSchema{InMemorySchema: NewInMemorySchema(), dir: dir}
+	for _, schema := range schemas {
+		f.schemas[schema.Name] = schema
+	}
+
+	return f, nil
+}
+
+// FileSchema defines the durable implementation of Schema.
+//
+// It serves everything from memory just like InMemorySchema, but every change
+// rewrites the whole set of schemas on local disk before being applied, since
+// schemas are few and seldom changed.
+type FileSchema struct {
+	*InMemorySchema
+	dir string
+	// mu serializes the changes, so that they're written in the order they're applied.
+	mu sync.Mutex
+}
+
+// Put persists schema on disk, then in memory, as InMemorySchema.Put does.
+func (f *FileSchema) Put(schema domain.Schema) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, err := f.InMemorySchema.Get(schema.Name)
+	if err == nil {
+		schema.CreatedAt = existing.CreatedAt
+	}
+	if err := f.write(func(schemas map[string]domain.Schema) { schemas[schema.Name] = schema }); err != nil {
+		return false, err
+	}
+
+	return f.InMemorySchema.Put(schema)
+}
+
+// Delete removes the schema identified by name from disk, then from memory.
+func (f *FileSchema) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.InMemorySchema.Get(name); err != nil {
+		return err
+	}
+	if err := f.write(func(schemas map[string]domain.Schema) { delete(schemas, name) }); err != nil {
+		return err
+	}
+
+	return f.InMemorySchema.Delete(name)
+}
+
+// write writes the schemas to disk as change leaves them.
+func (f *FileSchema) write(change func(schemas map[string]domain.Schema)) error {
+	f.InMemorySchema.mu.RLock()
+	schemas := make(map[string]domain.Schema, len(f.schemas)+1)
+	for name, schema := range f.schemas {
+		schemas[name] = schema
+	}
+	f.InMemorySchema.mu.RUnlock()
+
+	change(schemas)
+
+	sorted := make([]domain.Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		sorted = append(sorted, schema)
+	}
+	slices.SortFunc(sorted, func(a, b domain.Schema) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return writeFileJSON(f.dir, schemasFileName, sorted)
+}
+
+// NewFileWebhook returns a FileWebhook repository instance storing the webhooks
+// in dir, loading the ones stored there already, if there's any.
+func NewFileWebhook(dir string) (*FileWebhook, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	var webhooks []domain.Webhook
+	if err := readFileJSON(dir, webhooksFileName, &webhooks); err != nil {
+		return nil, err
+	}
+
+	f := &FileWebhook{InMemoryWebhook: NewInMemoryWebhook(), dir: dir}
+	for _, webhook := range webhooks {
+		f.webhooks[webhook.ID] = webhook
+	}
+
+	return f, nil
+}
+
+// FileWebhook defines the durable implementation of Webhook.
+//
+// Just like FileSchema, every change to the webhooks rewrites them all on
+// local disk before being applied. Their deliveries are only kept in memory,
+// as they're just a history of what's been posted.
+type FileWebhook struct {
+	*InMemoryWebhook
+	dir string
+	// mu serializes the changes, so that they're written in the order they're applied.
+	mu sync.Mutex
+}
+
+// Save persists a new webhook on disk, then in memory.
+func (f *FileWebhook) Save(webhook domain.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.InMemoryWebhook.Get(webhook.ID); err == nil {
+		return ErrWebhookExists
+	}
+	if err := f.write(func(webhooks map[string]domain.Webhook) { webhooks[webhook.ID] = webhook }); err != nil {
+		return err
+	}
+
+	return f.InMemoryWebhook.Save(webhook)
+}
+
+// Update replaces a webhook on disk, then in memory.
+func (f *FileWebhook) Update(webhook domain.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.InMemoryWebhook.Get(webhook.ID); err != nil {
+		return err
+	}
+	if err := f.write(func(webhooks map[string]domain.Webhook) { webhooks[webhook.ID] = webhook }); err != nil {
+		return err
+	}
+
+	return f.InMemoryWebhook.Update(webhook)
+}
+
+// Delete removes a webhook from disk, then from memory along with its deliveries.
+func (f *FileWebhook) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.InMemoryWebhook.Get(id); err != nil {
+		return err
+	}
+	if err := f.write(func(webhooks map[string]domain.Webhook) { delete(webhooks, id) }); err != nil {
+		return err
+	}
+
+	return f.InMemoryWebhook.Delete(id)
+}
+
+// write writes the webhooks to disk as change leaves them.
+func (f *FileWebhook) write(change func(webhooks map[string]domain.Webhook)) error {
+	f.InMemoryWebhook.mu.RLock()
+	webhooks := make(map[string]domain.Webhook, len(f.webhooks)+1)
+	for id, webhook := range f.webhooks {
+		webhooks[id] = webhook
+	}
+	f.InMemoryWebhook.mu.RUnlock()
+
+	change(webhooks)
+
+	sorted := make([]domain.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		sorted = append(sorted, webhook)
+	}
+	slices.SortFunc(sorted, func(a, b domain.Webhook) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return writeFileJSON(f.dir, webhooksFileName, sorted)
+}
+
+// readFileJSON reads the JSON file named name in dir into v.
+// If there's no such file, v is left as it is.
+func readFileJSON(dir, name string, v any) error {
+	bytes, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(bytes, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// writeFileJSON replaces the file named name in dir with v as JSON.
+func writeFileJSON(dir, name string, v any) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	return replaceFile(dir, name, bytes)
+}
@@ -0,0 +1,383 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileConfig(t *testing.T) {
+	config1 := domain.Config{
+		Namespace: domain.DefaultNamespace,
+		Name:      "config 1",
+		Metadata:  []byte(`{"foo":"bar"}`),
+	}
+	config2 := domain.Config{
+		Namespace: domain.DefaultNamespace,
+		Name:      "config 2",
+		Metadata:  []byte(`{"abc":"123"}`),
+	}
+
+	// writeChanges applies a set of changes that should survive a restart.
+	writeChanges := func(t *testing.T, repo repository.Config) {
+		t.Helper()
+
+		_, err := repo.Save(config1)
+		require.NoError(t, err)
+		_, err = repo.Save(config2)
+		require.NoError(t, err)
+		_, err = repo.Update(domain.DefaultNamespace, config1.Name, []byte(`{"foo":"updated"}`))
+		require.NoError(t, err)
+		_, err = repo.Delete(domain.DefaultNamespace, config2.Name)
+		require.NoError(t, err)
+	}
+
+	// assertChanges checks the outcome of writeChanges.
+	assertChanges := func(t *testing.T, repo repository.Config) {
+		t.Helper()
+
+		page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, page.Configs, 1)
+
+		config, err := repo.Get(domain.DefaultNamespace, config1.Name)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"foo":"updated"}`), config.Metadata)
+
+		_, err = repo.Get(domain.DefaultNamespace, config2.Name)
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+
+		revisions, err := repo.Revisions(domain.DefaultNamespace, config1.Name)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, config1.Metadata, revisions[0].Metadata)
+	}
+
+	t.Run("changes survive a restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		writeChanges(t, repo)
+		require.NoError(t, repo.Close())
+
+		reopened, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		assertChanges(t, reopened)
+	})
+
+	t.Run("changes survive a restart after compaction", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir, repository.WithCompactionThreshold(3))
+		require.NoError(t, err)
+		writeChanges(t, repo)
+		require.NoError(t, repo.Close())
+
+		t.Run("snapshot is written", func(t *testing.T) {
+			assert.FileExists(t, filepath.Join(dir, "snapshot.json"))
+		})
+
+		reopened, err := repository.NewFileConfig(dir, repository.WithCompactionThreshold(3))
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		assertChanges(t, reopened)
+	})
+
+	t.Run("torn final record is truncated", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		writeChanges(t, repo)
+		require.NoError(t, repo.Close())
+
+		// simulate a crash in the middle of appending a record.
+		walPath := filepath.Join(dir, "wal.log")
+		intactInfo, err := os.Stat(walPath)
+		require.NoError(t, err)
+
+		f, err := os.OpenFile(walPath, os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = f.Write([]byte{0, 0, 1, 0, 42, 42})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reopened, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		assertChanges(t, reopened)
+
+		t.Run("log is back to its intact size", func(t *testing.T) {
+			info, err := os.Stat(walPath)
+			require.NoError(t, err)
+			assert.Equal(t, intactInfo.Size(), info.Size())
+		})
+
+		t.Run("new changes are appended after the intact records", func(t *testing.T) {
+			_, err := reopened.Save(config2)
+			require.NoError(t, err)
+			require.NoError(t, reopened.Close())
+
+			again, err := repository.NewFileConfig(dir)
+			require.NoError(t, err)
+			defer again.Close()
+
+			_, err = again.Get(domain.DefaultNamespace, config2.Name)
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("corruption before the final record is reported", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		writeChanges(t, repo)
+		require.NoError(t, repo.Close())
+
+		// flip a byte in the payload of the first record.
+		walPath := filepath.Join(dir, "wal.log")
+		bytes, err := os.ReadFile(walPath)
+		require.NoError(t, err)
+		bytes[10] ^= 0xff
+		require.NoError(t, os.WriteFile(walPath, bytes, 0o644))
+
+		_, err = repository.NewFileConfig(dir)
+		assert.ErrorIs(t, err, repository.ErrCorruptedLog)
+	})
+
+	t.Run("bulk changes survive a restart", func(t *testing.T) {
+		for _, threshold := range []int{0, 3} {
+			t.Run(fmt.Sprintf("compaction threshold %d", threshold), func(t *testing.T) {
+				dir := t.TempDir()
+
+				repo, err := repository.NewFileConfig(dir, repository.WithCompactionThreshold(threshold))
+				require.NoError(t, err)
+
+				results := repo.Bulk([]repository.Op{
+					{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: config1.Name, Metadata: config1.Metadata},
+					{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: config2.Name, Metadata: config2.Metadata},
+					{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: config1.Name, Metadata: []byte(`{"foo":"updated"}`)},
+					{Type: repository.OpDelete, Namespace: domain.DefaultNamespace, Name: config2.Name},
+				})
+				for _, result := range results {
+					require.NoError(t, result.Err)
+				}
+				require.NoError(t, repo.Close())
+
+				reopened, err := repository.NewFileConfig(dir, repository.WithCompactionThreshold(threshold))
+				require.NoError(t, err)
+				defer reopened.Close()
+
+				assertChanges(t, reopened)
+			})
+		}
+	})
+
+	t.Run("failed transactions leave nothing behind", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		_, err = repo.Save(config1)
+		require.NoError(t, err)
+
+		_, err = repo.Txn(repository.Txn{Success: []repository.Op{
+			{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: config1.Name, Metadata: []byte(`{"foo":"txn"}`)},
+			{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: config1.Name, Metadata: config1.Metadata},
+		}})
+		require.ErrorIs(t, err, repository.ErrConfigExists)
+		_, err = repo.Save(config2)
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		reopened, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		revisions, err := reopened.Revisions(domain.DefaultNamespace, config1.Name)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, config1.Metadata, revisions[0].Metadata)
+
+		_, err = reopened.Get(domain.DefaultNamespace, config2.Name)
+		assert.NoError(t, err)
+	})
+
+	t.Run("namespaces survive a restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateNamespace("team-a"))
+		_, err = repo.Save(domain.Config{Namespace: "team-a", Name: config1.Name, Metadata: config1.Metadata})
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateNamespace("team-b"))
+		require.NoError(t, repo.DeleteNamespace("team-b"))
+		require.NoError(t, repo.Close())
+
+		reopened, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		namespaces, err := reopened.ListNamespaces()
+		require.NoError(t, err)
+		assert.Len(t, namespaces, 2)
+
+		config, err := reopened.Get("team-a", config1.Name)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(config1.Metadata), string(config.Metadata))
+	})
+
+	t.Run("parents survive a restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		repo, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		_, err = repo.Save(config1)
+		require.NoError(t, err)
+		_, err = repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: config2.Name, Metadata: config2.Metadata, Parents: []string{config1.Name}})
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		reopened, err := repository.NewFileConfig(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		config, err := reopened.Resolve(domain.DefaultNamespace, config2.Name)
+		require.NoError(t, err)
+		assert.Equal(t, []string{config1.Name}, config.Parents)
+		assert.JSONEq(t, `{"foo":"bar","abc":"123"}`, string(config.Metadata))
+	})
+}
+
+func BenchmarkFileConfig_Bulk(b *testing.B) {
+	const size = 100
+
+	ops := make([]repository.Op, size)
+	for n := range ops {
+		ops[n] = repository.Op{
+			Type:      repository.OpUpsert,
+			Namespace: domain.DefaultNamespace,
+			Name:      fmt.Sprintf("config-%d", n),
+			Metadata:  []byte(`{"foo": "bar"}`),
+		}
+	}
+
+	b.Run("bulk", func(b *testing.B) {
+		repo, err := repository.NewFileConfig(b.TempDir())
+		require.NoError(b, err)
+		defer repo.Close()
+
+		for range b.N {
+			repo.Bulk(ops)
+		}
+	})
+
+	b.Run("one by one", func(b *testing.B) {
+		repo, err := repository.NewFileConfig(b.TempDir())
+		require.NoError(b, err)
+		defer repo.Close()
+
+		for range b.N {
+			for _, op := range ops {
+				repo.Bulk([]repository.Op{op})
+			}
+		}
+	})
+}
+
+func TestFileSchema(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	repo, err := repository.NewFileSchema(dir)
+	require.NoError(t, err)
+
+	for _, schema := range []domain.Schema{
+		{Name: "flags", Pattern: "flags-*", Definition: []byte(`{"type": "object"}`), CreatedAt: createdAt, UpdatedAt: createdAt},
+		{Name: "limits", Pattern: "limits-*", Definition: []byte(`{}`), CreatedAt: createdAt, UpdatedAt: createdAt},
+		{Name: "flags", Pattern: "flags-*", Definition: []byte(`{"required": ["enabled"]}`), UpdatedAt: createdAt.Add(time.Hour)},
+	} {
+		_, err := repo.Put(schema)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Delete("limits"))
+
+	t.Run("changes survive a restart", func(t *testing.T) {
+		reopened, err := repository.NewFileSchema(dir)
+		require.NoError(t, err)
+
+		schemas, err := reopened.List()
+		require.NoError(t, err)
+		require.Len(t, schemas, 1)
+		assert.Equal(t, "flags", schemas[0].Name)
+		assert.Equal(t, []byte(`{"required": ["enabled"]}`), schemas[0].Definition)
+		assert.True(t, createdAt.Equal(schemas[0].CreatedAt))
+	})
+
+	t.Run("deleting a missing schema fails", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Delete("nope"), repository.ErrSchemaNotFound)
+	})
+
+	t.Run("unreadable schemas fail to load", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas.json"), []byte(`{`), 0o644))
+
+		_, err := repository.NewFileSchema(dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestFileWebhook(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	repo, err := repository.NewFileWebhook(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Save(domain.Webhook{ID: "wh-1", URL: "http://localhost/a", Secret: "s3cr3t", CreatedAt: createdAt}))
+	require.NoError(t, repo.Save(domain.Webhook{ID: "wh-2", URL: "http://localhost/b", CreatedAt: createdAt.Add(time.Second)}))
+	require.NoError(t, repo.Update(domain.Webhook{ID: "wh-1", URL: "http://localhost/c", Prefix: "web-", Secret: "s3cr3t", CreatedAt: createdAt}))
+	require.NoError(t, repo.Delete("wh-2"))
+	require.NoError(t, repo.SaveDelivery(domain.Delivery{ID: "d-1", WebhookID: "wh-1"}))
+
+	t.Run("changes survive a restart", func(t *testing.T) {
+		reopened, err := repository.NewFileWebhook(dir)
+		require.NoError(t, err)
+
+		webhooks, err := reopened.List()
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, "http://localhost/c", webhooks[0].URL)
+		assert.Equal(t, "web-", webhooks[0].Prefix)
+		assert.Equal(t, "s3cr3t", webhooks[0].Secret)
+
+		deliveries, err := reopened.Deliveries("wh-1")
+		require.NoError(t, err)
+		assert.Empty(t, deliveries, "deliveries are only kept in memory")
+	})
+
+	t.Run("failed changes aren't written", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(domain.Webhook{ID: "wh-1"}), repository.ErrWebhookExists)
+		assert.ErrorIs(t, repo.Update(domain.Webhook{ID: "nope"}), repository.ErrWebhookNotFound)
+		assert.ErrorIs(t, repo.Delete("nope"), repository.ErrWebhookNotFound)
+
+		reopened, err := repository.NewFileWebhook(dir)
+		require.NoError(t, err)
+		webhooks, err := reopened.List()
+		require.NoError(t, err)
+		assert.Len(t, webhooks, 1)
+	})
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"encoding/json"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"strconv"
+)
+
+// leaf is a scalar value in the metadata of a config, keyed as query.ValueKey
+// keys it, along with its path, where each dot represents each key node in
+// a different nest level, or the index of an element nested in an array.
+type leaf struct {
+	path  string
+	value string
+}
+
+// term is an equality condition on the value at path.
+type term struct {
+	path    string
+	operand string
+}
+
+// metadataIndex is an inverted index of the metadata of the configs,
+// going from the path of a leaf to its value to the configs holding it,
+// so that searching doesn't require parsing the metadata of every config.
+//
+// Every scalar leaf is indexed, and so is every element of an array under
+// the path of the array, since a search matches any of them. Secret values
+// are left out, since they're never matched.
+type metadataIndex struct {
+	postings map[string]map[string]map[configKey]struct{}
+	// leaves keeps the indexed leaves of each config, so that it can
+	// be dropped from the index without parsing its metadata again.
+	leaves map[configKey][]leaf
+}
+
+// newMetadataIndex creates an empty index.
+func newMetadataIndex() *metadataIndex {
+	return &metadataIndex{
+		postings: make(map[string]map[string]map[configKey]struct{}),
+		leaves:   make(map[configKey][]leaf),
+	}
+}
+
+// add indexes the metadata of cfg, replacing whatever was indexed for it before.
+func (x *metadataIndex) add(cfg domain.Config) {
+	key := keyOf(cfg)
+	x.remove(key)
+
+	var m map[string]any
+	if err := json.Unmarshal(domain.WithoutSecrets(cfg.Metadata), &m); err != nil {
+		return
+	}
+
+	var leaves []leaf
+	collectLeaves("", m, &leaves)
+	for _, l := range leaves {
+		values, ok := x.postings[l.path]
+		if !ok {
+			values = make(map[string]map[configKey]struct{})
+			x.postings[l.path] = values
+		}
+		keys, ok := values[l.value]
+		if !ok {
+			keys = make(map[configKey]struct{})
+			values[l.value] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	x.leaves[key] = leaves
+}
+
+// remove drops the config identified by key from the index.
+func (x *metadataIndex) remove(key configKey) {
+	for _, l := range x.leaves[key] {
+		keys := x.postings[l.path][l.value]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(x.postings[l.path], l.value)
+		}
+		if len(x.postings[l.path]) == 0 {
+			delete(x.postings, l.path)
+		}
+	}
+	delete(x.leaves, key)
+}
+
+// lookup gets the configs whose metadata holds a value equal to operand at path.
+func (x *metadataIndex) lookup(path, operand string) map[configKey]struct{} {
+	var sets []map[configKey]struct{}
+	for _, value := range query.OperandKeys(operand) {
+		if keys := x.postings[path][value]; len(keys) > 0 {
+			sets = append(sets, keys)
+		}
+	}
+	if len(sets) <= 1 {
+		if len(sets) == 0 {
+			return nil
+		}
+		return sets[0]
+	}
+
+	union := make(map[configKey]struct{})
+	for _, keys := range sets {
+		for key := range keys {
+			union[key] = struct{}{}
+		}
+	}
+
+	return union
+}
+
+// collectLeaves appends every scalar leaf in data, nested under prefix, to leaves.
+func collectLeaves(prefix string, data any, leaves *[]leaf) {
+	switch t := data.(type) {
+	case map[string]any:
+		for k, v := range t {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			collectLeaves(path, v, leaves)
+		}
+	case []any:
+		for n, v := range t {
+			collectLeaves(prefix+"."+strconv.Itoa(n), v, leaves)
+		}
+		collectElements(prefix, t, leaves)
+	default:
+		if value, ok := query.ValueKey(t); ok {
+			*leaves = append(*leaves, leaf{path: prefix, value: value})
+		}
+	}
+}
+
+// collectElements appends every scalar element of items, and of the arrays
+// nested in it, to leaves under path, the path of items.
+func collectElements(path string, items []any, leaves *[]leaf) {
+	for _, item := range items {
+		if nested, ok := item.([]any); ok {
+			collectElements(path, nested, leaves)
+		} else if value, ok := query.ValueKey(item); ok {
+			*leaves = append(*leaves, leaf{path: path, value: value})
+		}
+	}
+}
+
+// search gets the keys of the configs holding every one of terms, by
+// intersecting the configs holding each of them. No terms don't narrow
+// anything down, which is reported by returning false.
+func (x *metadataIndex) search(terms []term) (map[configKey]struct{}, bool) {
+	if len(terms) == 0 {
+		return nil, false
+	}
+
+	sets := make([]map[configKey]struct{}, 0, len(terms))
+	for _, t := range terms {
+		keys := x.lookup(t.path, t.operand)
+		if len(keys) == 0 {
+			return nil, true
+		}
+		sets = append(sets, keys)
+	}
+
+	// start off the smallest set, so that the intersection
+	// takes as few lookups as possible.
+	smallest := 0
+	for n, set := range sets {
+		if len(set) < len(sets[smallest]) {
+			smallest = n
+		}
+	}
+
+	matches := make(map[configKey]struct{}, len(sets[smallest]))
+out:
+	for key := range sets[smallest] {
+		for n, set := range sets {
+			if n == smallest {
+				continue
+			}
+			if _, ok := set[key]; !ok {
+				continue out
+			}
+		}
+		matches[key] = struct{}{}
+	}
+
+	return matches, true
+}
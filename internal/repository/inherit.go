@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"cmp"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"slices"
+)
+
+// Resolve fetches a config from the in-memory datastore with the metadata
+// it inherits from its parents resolved.
+// If the resource is not found, it returns ErrConfigNotFound.
+func (i *InMemoryConfig) Resolve(namespace, name string) (domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	config, ok := i.db.configs[configKey{namespace, name}]
+	if !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
+
+	return domain.Resolve(config, i.db.lookup(namespace))
+}
+
+// Descendants fetches every config inheriting from the config identified by
+// name from the in-memory datastore, directly or not, sorted by name.
+// If the resource is not found, it returns ErrConfigNotFound.
+func (i *InMemoryConfig) Descendants(namespace, name string) ([]domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	if _, ok := i.db.configs[configKey{namespace, name}]; !ok {
+		return nil, ErrConfigNotFound
+	}
+
+	// walk down the configs inheriting from one another, breadth first.
+	seen := map[string]bool{name: true}
+	var descendants []domain.Config
+	for queue := []string{name}; len(queue) > 0; queue = queue[1:] {
+		for _, child := range i.db.children(namespace, queue[0]) {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			descendants = append(descendants, i.db.configs[configKey{namespace, child}])
+			queue = append(queue, child)
+		}
+	}
+
+	slices.SortFunc(descendants, func(a, b domain.Config) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return descendants, nil
+}
+
+// SetParents sets the configs the config identified by name inherits from
+// in the in-memory datastore, storing it as a new revision.
+// If the resource is not found, it returns ErrConfigNotFound, and if revision
+// is set, but the config has changed since then, it returns ErrRevisionMismatch.
+func (i *InMemoryConfig) SetParents(namespace, name string, revision int64, parents []string) (domain.Config, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	existingConfig, ok := i.db.configs[configKey{namespace, name}]
+	if !ok {
+		return domain.Config{}, ErrConfigNotFound
+	}
+
+	if revision != 0 && existingConfig.Revision != revision {
+		return domain.Config{}, ErrRevisionMismatch
+	}
+
+	existingConfig.Parents = parents
+
+	return i.db.put(existingConfig)
+}
+
+// lookup gets a domain.ConfigLookup finding the configs in namespace.
+// Callers must hold the lock while using it.
+func (i *inMemoryDBState) lookup(namespace string) domain.ConfigLookup {
+	return func(name string) (domain.Config, bool) {
+		config, ok := i.configs[configKey{namespace, name}]
+		return config, ok
+	}
+}
+
+// children gets the names of the configs in namespace directly inheriting
+// from the config identified by name, sorted. Callers must hold the lock.
+func (i *inMemoryDBState) children(namespace, name string) []string {
+	var children []string
+	for key, config := range i.configs {
+		if key.namespace == namespace && slices.Contains(config.Parents, name) {
+			children = append(children, key.name)
+		}
+	}
+	slices.Sort(children)
+
+	return children
+}
+
+// resolveAll resolves the metadata every config in configs inherits from its
+// parents. Callers must hold the lock.
+func (i *inMemoryDBState) resolveAll(configs []domain.Config) ([]domain.Config, error) {
+	resolved := make([]domain.Config, len(configs))
+	for n, config := range configs {
+		var err error
+		if resolved[n], err = domain.Resolve(config, i.lookup(config.Namespace)); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,205 @@
+package repository_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInMemoryConfig_Inheritance(t *testing.T) {
+	// newRepo creates a repository holding burger-nutrition, inherited from by
+	// burger-nutrition-eu, which is inherited from by burger-nutrition-de.
+	newRepo := func(t *testing.T) repository.Config {
+		repo := repository.NewInMemoryConfig()
+		configs := []domain.Config{
+			{Name: "burger-nutrition", Metadata: []byte(`{"calories": "230", "allergens": {"gluten": "yes", "nuts": "no"}}`)},
+			{Name: "burger-nutrition-eu", Metadata: []byte(`{"allergens": {"nuts": "traces"}}`), Parents: []string{"burger-nutrition"}},
+			{Name: "burger-nutrition-de", Metadata: []byte(`{"calories": "250"}`), Parents: []string{"burger-nutrition-eu"}},
+			{Name: "unrelated", Metadata: []byte(`{"calories": "100"}`)},
+		}
+		for _, c := range configs {
+			c.Namespace = domain.DefaultNamespace
+			_, err := repo.Save(c)
+			require.NoError(t, err)
+		}
+		return repo
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		repo := newRepo(t)
+
+		config, err := repo.Resolve(domain.DefaultNamespace, "burger-nutrition-de")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"calories": "250", "allergens": {"gluten": "yes", "nuts": "traces"}}`, string(config.Metadata))
+
+		_, err = repo.Resolve(domain.DefaultNamespace, "nope")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+
+	t.Run("descendants", func(t *testing.T) {
+		repo := newRepo(t)
+
+		descendants, err := repo.Descendants(domain.DefaultNamespace, "burger-nutrition")
+		require.NoError(t, err)
+		require.Len(t, descendants, 2)
+		assert.Equal(t, "burger-nutrition-de", descendants[0].Name)
+		assert.Equal(t, "burger-nutrition-eu", descendants[1].Name)
+
+		descendants, err = repo.Descendants(domain.DefaultNamespace, "burger-nutrition-de")
+		require.NoError(t, err)
+		assert.Empty(t, descendants)
+
+		_, err = repo.Descendants(domain.DefaultNamespace, "nope")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			write   func(repo repository.Config) error
+			wantErr error
+		}{
+			{
+				name: "save with a missing parent",
+				write: func(repo repository.Config) error {
+					_, err := repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: "new", Metadata: []byte(`{}`), Parents: []string{"nope"}})
+					return err
+				},
+				wantErr: domain.ErrParentNotFound,
+			},
+			{
+				name: "save with a parent in another namespace",
+				write: func(repo repository.Config) error {
+					require.NoError(t, repo.CreateNamespace("team-a"))
+					_, err := repo.Save(domain.Config{Namespace: "team-a", Name: "new", Metadata: []byte(`{}`), Parents: []string{"burger-nutrition"}})
+					return err
+				},
+				wantErr: domain.ErrParentNotFound,
+			},
+			{
+				name: "set parents making a cycle",
+				write: func(repo repository.Config) error {
+					_, err := repo.SetParents(domain.DefaultNamespace, "burger-nutrition", 0, []string{"burger-nutrition-de"})
+					return err
+				},
+				wantErr: domain.ErrParentCycle,
+			},
+			{
+				name: "set itself as a parent",
+				write: func(repo repository.Config) error {
+					_, err := repo.SetParents(domain.DefaultNamespace, "unrelated", 0, []string{"unrelated"})
+					return err
+				},
+				wantErr: domain.ErrParentCycle,
+			},
+			{
+				name: "set parents of a missing config",
+				write: func(repo repository.Config) error {
+					_, err := repo.SetParents(domain.DefaultNamespace, "nope", 0, []string{"unrelated"})
+					return err
+				},
+				wantErr: repository.ErrConfigNotFound,
+			},
+			{
+				name: "set parents at a stale revision",
+				write: func(repo repository.Config) error {
+					_, err := repo.SetParents(domain.DefaultNamespace, "unrelated", 1, []string{"burger-nutrition"})
+					return err
+				},
+				wantErr: repository.ErrRevisionMismatch,
+			},
+			{
+				name: "delete a config with children",
+				write: func(repo repository.Config) error {
+					_, err := repo.Delete(domain.DefaultNamespace, "burger-nutrition-eu")
+					return err
+				},
+				wantErr: repository.ErrConfigHasChildren,
+			},
+			{
+				name: "upsert making a cycle",
+				write: func(repo repository.Config) error {
+					return repo.Bulk([]repository.Op{
+						{Type: repository.OpUpsert, Namespace: domain.DefaultNamespace, Name: "burger-nutrition", Metadata: []byte(`{}`), Parents: []string{"burger-nutrition-eu"}},
+					})[0].Err
+				},
+				wantErr: domain.ErrParentCycle,
+			},
+			{
+				name: "transaction making a cycle",
+				write: func(repo repository.Config) error {
+					_, err := repo.Txn(repository.Txn{Success: []repository.Op{
+						{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: "new", Metadata: []byte(`{}`), Parents: []string{"unrelated"}},
+						{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: "unrelated", Metadata: []byte(`{}`), Parents: []string{"new"}},
+					}})
+					return err
+				},
+				wantErr: domain.ErrParentCycle,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newRepo(t)
+				before, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
+				require.NoError(t, err)
+
+				assert.ErrorIs(t, tt.write(repo), tt.wantErr)
+
+				after, err := repo.List(domain.DefaultNamespace, repository.ListOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, before, after)
+			})
+		}
+
+		t.Run("updates keep the parents", func(t *testing.T) {
+			repo := newRepo(t)
+			_, err := repo.Update(domain.DefaultNamespace, "burger-nutrition-de", []byte(`{"calories": "260"}`))
+			require.NoError(t, err)
+			results := repo.Bulk([]repository.Op{
+				{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: "burger-nutrition-de", Metadata: []byte(`{"calories": "270"}`)},
+			})
+			require.NoError(t, results[0].Err)
+
+			config, err := repo.Get(domain.DefaultNamespace, "burger-nutrition-de")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"burger-nutrition-eu"}, config.Parents)
+		})
+
+		t.Run("children can be deleted before their parents", func(t *testing.T) {
+			repo := newRepo(t)
+			_, err := repo.Delete(domain.DefaultNamespace, "burger-nutrition-de")
+			require.NoError(t, err)
+			_, err = repo.Delete(domain.DefaultNamespace, "burger-nutrition-eu")
+			require.NoError(t, err)
+			_, err = repo.Delete(domain.DefaultNamespace, "burger-nutrition")
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("resolved list and search", func(t *testing.T) {
+		repo := newRepo(t)
+
+		page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{Resolved: true})
+		require.NoError(t, err)
+		require.Len(t, page.Configs, 4)
+		assert.JSONEq(t, `{"calories": "250", "allergens": {"gluten": "yes", "nuts": "traces"}}`, string(page.Configs[1].Metadata))
+
+		expr, err := query.Parse(`allergens.gluten = yes`)
+		require.NoError(t, err)
+
+		page, err = repo.Search(domain.DefaultNamespace, expr, repository.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, page.Configs, 1, "only the config holding the key matches")
+
+		page, err = repo.Search(domain.DefaultNamespace, expr, repository.ListOptions{Resolved: true})
+		require.NoError(t, err)
+		require.Len(t, page.Configs, 3, "the configs inheriting the key match as well")
+		for _, c := range page.Configs {
+			assert.Equal(t, "yes", c.MetadataValue("allergens.gluten"))
+		}
+	})
+}
@@ -3,90 +3,651 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	query "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	repository "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
 	mock "github.com/stretchr/testify/mock"
 )
 
-// Config is an autogenerated mock type for the Config type
-type Config struct {
-	mock.Mock
+// Config is an autogenerated mock type for the Config type
+type Config struct {
+	mock.Mock
+}
+
+type Config_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Config) EXPECT() *Config_Expecter {
+	return &Config_Expecter{mock: &_m.Mock}
+}
+
+// Bulk provides a mock function with given fields: ops
+func (_m *Config) Bulk(ops []repository.Op) []repository.OpResult {
+	ret := _m.Called(ops)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Bulk")
+	}
+
+	var r0 []repository.OpResult
+	if rf, ok := ret.Get(0).(func([]repository.Op) []repository.OpResult); ok {
+		r0 = rf(ops)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.OpResult)
+		}
+	}
+
+	return r0
+}
+
+// Config_Bulk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Bulk'
+type Config_Bulk_Call struct {
+	*mock.Call
+}
+
+// Bulk is a helper method to define mock.On call
+//   - ops []repository.Op
+func (_e *Config_Expecter) Bulk(ops interface{}) *Config_Bulk_Call {
+	return &Config_Bulk_Call{Call: _e.mock.On("Bulk", ops)}
+}
+
+func (_c *Config_Bulk_Call) Run(run func(ops []repository.Op)) *Config_Bulk_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]repository.Op))
+	})
+	return _c
+}
+
+func (_c *Config_Bulk_Call) Return(_a0 []repository.OpResult) *Config_Bulk_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Config_Bulk_Call) RunAndReturn(run func([]repository.Op) []repository.OpResult) *Config_Bulk_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompareAndDelete provides a mock function with given fields: namespace, name, revision
+func (_m *Config) CompareAndDelete(namespace string, name string, revision int64) (domain.Config, error) {
+	ret := _m.Called(namespace, name, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompareAndDelete")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) (domain.Config, error)); ok {
+		return rf(namespace, name, revision)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64) domain.Config); ok {
+		r0 = rf(namespace, name, revision)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int64) error); ok {
+		r1 = rf(namespace, name, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_CompareAndDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompareAndDelete'
+type Config_CompareAndDelete_Call struct {
+	*mock.Call
+}
+
+// CompareAndDelete is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+//   - revision int64
+func (_e *Config_Expecter) CompareAndDelete(namespace interface{}, name interface{}, revision interface{}) *Config_CompareAndDelete_Call {
+	return &Config_CompareAndDelete_Call{Call: _e.mock.On("CompareAndDelete", namespace, name, revision)}
+}
+
+func (_c *Config_CompareAndDelete_Call) Run(run func(namespace string, name string, revision int64)) *Config_CompareAndDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *Config_CompareAndDelete_Call) Return(_a0 domain.Config, _a1 error) *Config_CompareAndDelete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_CompareAndDelete_Call) RunAndReturn(run func(string, string, int64) (domain.Config, error)) *Config_CompareAndDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompareAndSwap provides a mock function with given fields: namespace, name, revision, metadata
+func (_m *Config) CompareAndSwap(namespace string, name string, revision int64, metadata []byte) (domain.Config, error) {
+	ret := _m.Called(namespace, name, revision, metadata)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompareAndSwap")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64, []byte) (domain.Config, error)); ok {
+		return rf(namespace, name, revision, metadata)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64, []byte) domain.Config); ok {
+		r0 = rf(namespace, name, revision, metadata)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int64, []byte) error); ok {
+		r1 = rf(namespace, name, revision, metadata)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_CompareAndSwap_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompareAndSwap'
+type Config_CompareAndSwap_Call struct {
+	*mock.Call
+}
+
+// CompareAndSwap is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+//   - revision int64
+//   - metadata []byte
+func (_e *Config_Expecter) CompareAndSwap(namespace interface{}, name interface{}, revision interface{}, metadata interface{}) *Config_CompareAndSwap_Call {
+	return &Config_CompareAndSwap_Call{Call: _e.mock.On("CompareAndSwap", namespace, name, revision, metadata)}
+}
+
+func (_c *Config_CompareAndSwap_Call) Run(run func(namespace string, name string, revision int64, metadata []byte)) *Config_CompareAndSwap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *Config_CompareAndSwap_Call) Return(_a0 domain.Config, _a1 error) *Config_CompareAndSwap_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_CompareAndSwap_Call) RunAndReturn(run func(string, string, int64, []byte) (domain.Config, error)) *Config_CompareAndSwap_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateNamespace provides a mock function with given fields: name
+func (_m *Config) CreateNamespace(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Config_CreateNamespace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateNamespace'
+type Config_CreateNamespace_Call struct {
+	*mock.Call
+}
+
+// CreateNamespace is a helper method to define mock.On call
+//   - name string
+func (_e *Config_Expecter) CreateNamespace(name interface{}) *Config_CreateNamespace_Call {
+	return &Config_CreateNamespace_Call{Call: _e.mock.On("CreateNamespace", name)}
+}
+
+func (_c *Config_CreateNamespace_Call) Run(run func(name string)) *Config_CreateNamespace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Config_CreateNamespace_Call) Return(_a0 error) *Config_CreateNamespace_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Config_CreateNamespace_Call) RunAndReturn(run func(string) error) *Config_CreateNamespace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: namespace, name
+func (_m *Config) Delete(namespace string, name string) (domain.Config, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (domain.Config, error)); ok {
+		return rf(namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) domain.Config); ok {
+		r0 = rf(namespace, name)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type Config_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *Config_Expecter) Delete(namespace interface{}, name interface{}) *Config_Delete_Call {
+	return &Config_Delete_Call{Call: _e.mock.On("Delete", namespace, name)}
+}
+
+func (_c *Config_Delete_Call) Run(run func(namespace string, name string)) *Config_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Config_Delete_Call) Return(_a0 domain.Config, _a1 error) *Config_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Delete_Call) RunAndReturn(run func(string, string) (domain.Config, error)) *Config_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteNamespace provides a mock function with given fields: name
+func (_m *Config) DeleteNamespace(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Config_DeleteNamespace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteNamespace'
+type Config_DeleteNamespace_Call struct {
+	*mock.Call
+}
+
+// DeleteNamespace is a helper method to define mock.On call
+//   - name string
+func (_e *Config_Expecter) DeleteNamespace(name interface{}) *Config_DeleteNamespace_Call {
+	return &Config_DeleteNamespace_Call{Call: _e.mock.On("DeleteNamespace", name)}
+}
+
+func (_c *Config_DeleteNamespace_Call) Run(run func(name string)) *Config_DeleteNamespace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Config_DeleteNamespace_Call) Return(_a0 error) *Config_DeleteNamespace_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Config_DeleteNamespace_Call) RunAndReturn(run func(string) error) *Config_DeleteNamespace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Descendants provides a mock function with given fields: namespace, name
+func (_m *Config) Descendants(namespace string, name string) ([]domain.Config, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Descendants")
+	}
+
+	var r0 []domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]domain.Config, error)); ok {
+		return rf(namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []domain.Config); ok {
+		r0 = rf(namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Config)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_Descendants_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Descendants'
+type Config_Descendants_Call struct {
+	*mock.Call
+}
+
+// Descendants is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *Config_Expecter) Descendants(namespace interface{}, name interface{}) *Config_Descendants_Call {
+	return &Config_Descendants_Call{Call: _e.mock.On("Descendants", namespace, name)}
+}
+
+func (_c *Config_Descendants_Call) Run(run func(namespace string, name string)) *Config_Descendants_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Config_Descendants_Call) Return(_a0 []domain.Config, _a1 error) *Config_Descendants_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Descendants_Call) RunAndReturn(run func(string, string) ([]domain.Config, error)) *Config_Descendants_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: namespace, name
+func (_m *Config) Get(namespace string, name string) (domain.Config, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (domain.Config, error)); ok {
+		return rf(namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) domain.Config); ok {
+		r0 = rf(namespace, name)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type Config_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *Config_Expecter) Get(namespace interface{}, name interface{}) *Config_Get_Call {
+	return &Config_Get_Call{Call: _e.mock.On("Get", namespace, name)}
+}
+
+func (_c *Config_Get_Call) Run(run func(namespace string, name string)) *Config_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Config_Get_Call) Return(_a0 domain.Config, _a1 error) *Config_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Get_Call) RunAndReturn(run func(string, string) (domain.Config, error)) *Config_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: namespace, opts
+func (_m *Config) List(namespace string, opts repository.ListOptions) (repository.Page, error) {
+	ret := _m.Called(namespace, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 repository.Page
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, repository.ListOptions) (repository.Page, error)); ok {
+		return rf(namespace, opts)
+	}
+	if rf, ok := ret.Get(0).(func(string, repository.ListOptions) repository.Page); ok {
+		r0 = rf(namespace, opts)
+	} else {
+		r0 = ret.Get(0).(repository.Page)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, repository.ListOptions) error); ok {
+		r1 = rf(namespace, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type Config_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - namespace string
+//   - opts repository.ListOptions
+func (_e *Config_Expecter) List(namespace interface{}, opts interface{}) *Config_List_Call {
+	return &Config_List_Call{Call: _e.mock.On("List", namespace, opts)}
+}
+
+func (_c *Config_List_Call) Run(run func(namespace string, opts repository.ListOptions)) *Config_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(repository.ListOptions))
+	})
+	return _c
+}
+
+func (_c *Config_List_Call) Return(_a0 repository.Page, _a1 error) *Config_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_List_Call) RunAndReturn(run func(string, repository.ListOptions) (repository.Page, error)) *Config_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListNamespaces provides a mock function with no fields
+func (_m *Config) ListNamespaces() ([]domain.Namespace, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListNamespaces")
+	}
+
+	var r0 []domain.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]domain.Namespace, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []domain.Namespace); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-type Config_Expecter struct {
-	mock *mock.Mock
+// Config_ListNamespaces_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListNamespaces'
+type Config_ListNamespaces_Call struct {
+	*mock.Call
 }
 
-func (_m *Config) EXPECT() *Config_Expecter {
-	return &Config_Expecter{mock: &_m.Mock}
+// ListNamespaces is a helper method to define mock.On call
+func (_e *Config_Expecter) ListNamespaces() *Config_ListNamespaces_Call {
+	return &Config_ListNamespaces_Call{Call: _e.mock.On("ListNamespaces")}
 }
 
-// Delete provides a mock function with given fields: name
-func (_m *Config) Delete(name string) error {
-	ret := _m.Called(name)
+func (_c *Config_ListNamespaces_Call) Run(run func()) *Config_ListNamespaces_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Config_ListNamespaces_Call) Return(_a0 []domain.Namespace, _a1 error) *Config_ListNamespaces_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_ListNamespaces_Call) RunAndReturn(run func() ([]domain.Namespace, error)) *Config_ListNamespaces_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Patch provides a mock function with given fields: namespace, name, revision, patch
+func (_m *Config) Patch(namespace string, name string, revision int64, patch repository.PatchFunc) (domain.Config, error) {
+	ret := _m.Called(namespace, name, revision, patch)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Delete")
+		panic("no return value specified for Patch")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(name)
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64, repository.PatchFunc) (domain.Config, error)); ok {
+		return rf(namespace, name, revision, patch)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64, repository.PatchFunc) domain.Config); ok {
+		r0 = rf(namespace, name, revision, patch)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Config)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, string, int64, repository.PatchFunc) error); ok {
+		r1 = rf(namespace, name, revision, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Config_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
-type Config_Delete_Call struct {
+// Config_Patch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Patch'
+type Config_Patch_Call struct {
 	*mock.Call
 }
 
-// Delete is a helper method to define mock.On call
+// Patch is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *Config_Expecter) Delete(name interface{}) *Config_Delete_Call {
-	return &Config_Delete_Call{Call: _e.mock.On("Delete", name)}
+//   - revision int64
+//   - patch repository.PatchFunc
+func (_e *Config_Expecter) Patch(namespace interface{}, name interface{}, revision interface{}, patch interface{}) *Config_Patch_Call {
+	return &Config_Patch_Call{Call: _e.mock.On("Patch", namespace, name, revision, patch)}
 }
 
-func (_c *Config_Delete_Call) Run(run func(name string)) *Config_Delete_Call {
+func (_c *Config_Patch_Call) Run(run func(namespace string, name string, revision int64, patch repository.PatchFunc)) *Config_Patch_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string), args[2].(int64), args[3].(repository.PatchFunc))
 	})
 	return _c
 }
 
-func (_c *Config_Delete_Call) Return(_a0 error) *Config_Delete_Call {
-	_c.Call.Return(_a0)
+func (_c *Config_Patch_Call) Return(_a0 domain.Config, _a1 error) *Config_Patch_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_Delete_Call) RunAndReturn(run func(string) error) *Config_Delete_Call {
+func (_c *Config_Patch_Call) RunAndReturn(run func(string, string, int64, repository.PatchFunc) (domain.Config, error)) *Config_Patch_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function with given fields: name
-func (_m *Config) Get(name string) (domain.Config, error) {
-	ret := _m.Called(name)
+// Resolve provides a mock function with given fields: namespace, name
+func (_m *Config) Resolve(namespace string, name string) (domain.Config, error) {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for Resolve")
 	}
 
 	var r0 domain.Config
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (domain.Config, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) (domain.Config, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) domain.Config); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) domain.Config); ok {
+		r0 = rf(namespace, name)
 	} else {
 		r0 = ret.Get(0).(domain.Config)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -94,57 +655,116 @@ func (_m *Config) Get(name string) (domain.Config, error) {
 	return r0, r1
 }
 
-// Config_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type Config_Get_Call struct {
+// Config_Resolve_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resolve'
+type Config_Resolve_Call struct {
 	*mock.Call
 }
 
-// Get is a helper method to define mock.On call
+// Resolve is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *Config_Expecter) Get(name interface{}) *Config_Get_Call {
-	return &Config_Get_Call{Call: _e.mock.On("Get", name)}
+func (_e *Config_Expecter) Resolve(namespace interface{}, name interface{}) *Config_Resolve_Call {
+	return &Config_Resolve_Call{Call: _e.mock.On("Resolve", namespace, name)}
 }
 
-func (_c *Config_Get_Call) Run(run func(name string)) *Config_Get_Call {
+func (_c *Config_Resolve_Call) Run(run func(namespace string, name string)) *Config_Resolve_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Config_Get_Call) Return(_a0 domain.Config, _a1 error) *Config_Get_Call {
+func (_c *Config_Resolve_Call) Return(_a0 domain.Config, _a1 error) *Config_Resolve_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_Get_Call) RunAndReturn(run func(string) (domain.Config, error)) *Config_Get_Call {
+func (_c *Config_Resolve_Call) RunAndReturn(run func(string, string) (domain.Config, error)) *Config_Resolve_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// List provides a mock function with given fields:
-func (_m *Config) List() ([]domain.Config, error) {
-	ret := _m.Called()
+// Revision provides a mock function with given fields: namespace, name, revision
+func (_m *Config) Revision(namespace string, name string, revision int64) (domain.Config, error) {
+	ret := _m.Called(namespace, name, revision)
 
 	if len(ret) == 0 {
-		panic("no return value specified for List")
+		panic("no return value specified for Revision")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) (domain.Config, error)); ok {
+		return rf(namespace, name, revision)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64) domain.Config); ok {
+		r0 = rf(namespace, name, revision)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int64) error); ok {
+		r1 = rf(namespace, name, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_Revision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revision'
+type Config_Revision_Call struct {
+	*mock.Call
+}
+
+// Revision is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+//   - revision int64
+func (_e *Config_Expecter) Revision(namespace interface{}, name interface{}, revision interface{}) *Config_Revision_Call {
+	return &Config_Revision_Call{Call: _e.mock.On("Revision", namespace, name, revision)}
+}
+
+func (_c *Config_Revision_Call) Run(run func(namespace string, name string, revision int64)) *Config_Revision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *Config_Revision_Call) Return(_a0 domain.Config, _a1 error) *Config_Revision_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Revision_Call) RunAndReturn(run func(string, string, int64) (domain.Config, error)) *Config_Revision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revisions provides a mock function with given fields: namespace, name
+func (_m *Config) Revisions(namespace string, name string) ([]domain.Config, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revisions")
 	}
 
 	var r0 []domain.Config
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]domain.Config, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(string, string) ([]domain.Config, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func() []domain.Config); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, string) []domain.Config); ok {
+		r0 = rf(namespace, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Config)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -152,49 +772,61 @@ func (_m *Config) List() ([]domain.Config, error) {
 	return r0, r1
 }
 
-// Config_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
-type Config_List_Call struct {
+// Config_Revisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revisions'
+type Config_Revisions_Call struct {
 	*mock.Call
 }
 
-// List is a helper method to define mock.On call
-func (_e *Config_Expecter) List() *Config_List_Call {
-	return &Config_List_Call{Call: _e.mock.On("List")}
+// Revisions is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *Config_Expecter) Revisions(namespace interface{}, name interface{}) *Config_Revisions_Call {
+	return &Config_Revisions_Call{Call: _e.mock.On("Revisions", namespace, name)}
 }
 
-func (_c *Config_List_Call) Run(run func()) *Config_List_Call {
+func (_c *Config_Revisions_Call) Run(run func(namespace string, name string)) *Config_Revisions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Config_List_Call) Return(_a0 []domain.Config, _a1 error) *Config_List_Call {
+func (_c *Config_Revisions_Call) Return(_a0 []domain.Config, _a1 error) *Config_Revisions_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_List_Call) RunAndReturn(run func() ([]domain.Config, error)) *Config_List_Call {
+func (_c *Config_Revisions_Call) RunAndReturn(run func(string, string) ([]domain.Config, error)) *Config_Revisions_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Save provides a mock function with given fields: cfg
-func (_m *Config) Save(cfg domain.Config) error {
+func (_m *Config) Save(cfg domain.Config) (domain.Config, error) {
 	ret := _m.Called(cfg)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Save")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(domain.Config) error); ok {
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.Config) (domain.Config, error)); ok {
+		return rf(cfg)
+	}
+	if rf, ok := ret.Get(0).(func(domain.Config) domain.Config); ok {
 		r0 = rf(cfg)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Config)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(domain.Config) error); ok {
+		r1 = rf(cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Config_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
@@ -215,39 +847,37 @@ func (_c *Config_Save_Call) Run(run func(cfg domain.Config)) *Config_Save_Call {
 	return _c
 }
 
-func (_c *Config_Save_Call) Return(_a0 error) *Config_Save_Call {
-	_c.Call.Return(_a0)
+func (_c *Config_Save_Call) Return(_a0 domain.Config, _a1 error) *Config_Save_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_Save_Call) RunAndReturn(run func(domain.Config) error) *Config_Save_Call {
+func (_c *Config_Save_Call) RunAndReturn(run func(domain.Config) (domain.Config, error)) *Config_Save_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Search provides a mock function with given fields: query
-func (_m *Config) Search(query map[string]string) ([]domain.Config, error) {
-	ret := _m.Called(query)
+// Search provides a mock function with given fields: namespace, expr, opts
+func (_m *Config) Search(namespace string, expr query.Expr, opts repository.ListOptions) (repository.Page, error) {
+	ret := _m.Called(namespace, expr, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Search")
 	}
 
-	var r0 []domain.Config
+	var r0 repository.Page
 	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]string) ([]domain.Config, error)); ok {
-		return rf(query)
+	if rf, ok := ret.Get(0).(func(string, query.Expr, repository.ListOptions) (repository.Page, error)); ok {
+		return rf(namespace, expr, opts)
 	}
-	if rf, ok := ret.Get(0).(func(map[string]string) []domain.Config); ok {
-		r0 = rf(query)
+	if rf, ok := ret.Get(0).(func(string, query.Expr, repository.ListOptions) repository.Page); ok {
+		r0 = rf(namespace, expr, opts)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Config)
-		}
+		r0 = ret.Get(0).(repository.Page)
 	}
 
-	if rf, ok := ret.Get(1).(func(map[string]string) error); ok {
-		r1 = rf(query)
+	if rf, ok := ret.Get(1).(func(string, query.Expr, repository.ListOptions) error); ok {
+		r1 = rf(namespace, expr, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -261,44 +891,171 @@ type Config_Search_Call struct {
 }
 
 // Search is a helper method to define mock.On call
-//   - query map[string]string
-func (_e *Config_Expecter) Search(query interface{}) *Config_Search_Call {
-	return &Config_Search_Call{Call: _e.mock.On("Search", query)}
+//   - namespace string
+//   - expr query.Expr
+//   - opts repository.ListOptions
+func (_e *Config_Expecter) Search(namespace interface{}, expr interface{}, opts interface{}) *Config_Search_Call {
+	return &Config_Search_Call{Call: _e.mock.On("Search", namespace, expr, opts)}
+}
+
+func (_c *Config_Search_Call) Run(run func(namespace string, expr query.Expr, opts repository.ListOptions)) *Config_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(query.Expr), args[2].(repository.ListOptions))
+	})
+	return _c
+}
+
+func (_c *Config_Search_Call) Return(_a0 repository.Page, _a1 error) *Config_Search_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Search_Call) RunAndReturn(run func(string, query.Expr, repository.ListOptions) (repository.Page, error)) *Config_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetParents provides a mock function with given fields: namespace, name, revision, parents
+func (_m *Config) SetParents(namespace string, name string, revision int64, parents []string) (domain.Config, error) {
+	ret := _m.Called(namespace, name, revision, parents)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetParents")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64, []string) (domain.Config, error)); ok {
+		return rf(namespace, name, revision, parents)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64, []string) domain.Config); ok {
+		r0 = rf(namespace, name, revision, parents)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int64, []string) error); ok {
+		r1 = rf(namespace, name, revision, parents)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_SetParents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetParents'
+type Config_SetParents_Call struct {
+	*mock.Call
+}
+
+// SetParents is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+//   - revision int64
+//   - parents []string
+func (_e *Config_Expecter) SetParents(namespace interface{}, name interface{}, revision interface{}, parents interface{}) *Config_SetParents_Call {
+	return &Config_SetParents_Call{Call: _e.mock.On("SetParents", namespace, name, revision, parents)}
+}
+
+func (_c *Config_SetParents_Call) Run(run func(namespace string, name string, revision int64, parents []string)) *Config_SetParents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *Config_SetParents_Call) Return(_a0 domain.Config, _a1 error) *Config_SetParents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_SetParents_Call) RunAndReturn(run func(string, string, int64, []string) (domain.Config, error)) *Config_SetParents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Txn provides a mock function with given fields: txn
+func (_m *Config) Txn(txn repository.Txn) (repository.TxnResult, error) {
+	ret := _m.Called(txn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Txn")
+	}
+
+	var r0 repository.TxnResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(repository.Txn) (repository.TxnResult, error)); ok {
+		return rf(txn)
+	}
+	if rf, ok := ret.Get(0).(func(repository.Txn) repository.TxnResult); ok {
+		r0 = rf(txn)
+	} else {
+		r0 = ret.Get(0).(repository.TxnResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(repository.Txn) error); ok {
+		r1 = rf(txn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-func (_c *Config_Search_Call) Run(run func(query map[string]string)) *Config_Search_Call {
+// Config_Txn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Txn'
+type Config_Txn_Call struct {
+	*mock.Call
+}
+
+// Txn is a helper method to define mock.On call
+//   - txn repository.Txn
+func (_e *Config_Expecter) Txn(txn interface{}) *Config_Txn_Call {
+	return &Config_Txn_Call{Call: _e.mock.On("Txn", txn)}
+}
+
+func (_c *Config_Txn_Call) Run(run func(txn repository.Txn)) *Config_Txn_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(map[string]string))
+		run(args[0].(repository.Txn))
 	})
 	return _c
 }
 
-func (_c *Config_Search_Call) Return(_a0 []domain.Config, _a1 error) *Config_Search_Call {
+func (_c *Config_Txn_Call) Return(_a0 repository.TxnResult, _a1 error) *Config_Txn_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_Search_Call) RunAndReturn(run func(map[string]string) ([]domain.Config, error)) *Config_Search_Call {
+func (_c *Config_Txn_Call) RunAndReturn(run func(repository.Txn) (repository.TxnResult, error)) *Config_Txn_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: name, metadata
-func (_m *Config) Update(name string, metadata []byte) error {
-	ret := _m.Called(name, metadata)
+// Update provides a mock function with given fields: namespace, name, metadata
+func (_m *Config) Update(namespace string, name string, metadata []byte) (domain.Config, error) {
+	ret := _m.Called(namespace, name, metadata)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
-		r0 = rf(name, metadata)
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, []byte) (domain.Config, error)); ok {
+		return rf(namespace, name, metadata)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, []byte) domain.Config); ok {
+		r0 = rf(namespace, name, metadata)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Config)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, string, []byte) error); ok {
+		r1 = rf(namespace, name, metadata)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Config_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
@@ -307,25 +1064,85 @@ type Config_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - namespace string
 //   - name string
 //   - metadata []byte
-func (_e *Config_Expecter) Update(name interface{}, metadata interface{}) *Config_Update_Call {
-	return &Config_Update_Call{Call: _e.mock.On("Update", name, metadata)}
+func (_e *Config_Expecter) Update(namespace interface{}, name interface{}, metadata interface{}) *Config_Update_Call {
+	return &Config_Update_Call{Call: _e.mock.On("Update", namespace, name, metadata)}
 }
 
-func (_c *Config_Update_Call) Run(run func(name string, metadata []byte)) *Config_Update_Call {
+func (_c *Config_Update_Call) Run(run func(namespace string, name string, metadata []byte)) *Config_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].([]byte))
+		run(args[0].(string), args[1].(string), args[2].([]byte))
 	})
 	return _c
 }
 
-func (_c *Config_Update_Call) Return(_a0 error) *Config_Update_Call {
-	_c.Call.Return(_a0)
+func (_c *Config_Update_Call) Return(_a0 domain.Config, _a1 error) *Config_Update_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Config_Update_Call) RunAndReturn(run func(string, string, []byte) (domain.Config, error)) *Config_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Watch provides a mock function with given fields: ctx, namespace, name, sinceRevision
+func (_m *Config) Watch(ctx context.Context, namespace string, name string, sinceRevision int64) (domain.Config, error) {
+	ret := _m.Called(ctx, namespace, name, sinceRevision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Watch")
+	}
+
+	var r0 domain.Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) (domain.Config, error)); ok {
+		return rf(ctx, namespace, name, sinceRevision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) domain.Config); ok {
+		r0 = rf(ctx, namespace, name, sinceRevision)
+	} else {
+		r0 = ret.Get(0).(domain.Config)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = rf(ctx, namespace, name, sinceRevision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Config_Watch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Watch'
+type Config_Watch_Call struct {
+	*mock.Call
+}
+
+// Watch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - namespace string
+//   - name string
+//   - sinceRevision int64
+func (_e *Config_Expecter) Watch(ctx interface{}, namespace interface{}, name interface{}, sinceRevision interface{}) *Config_Watch_Call {
+	return &Config_Watch_Call{Call: _e.mock.On("Watch", ctx, namespace, name, sinceRevision)}
+}
+
+func (_c *Config_Watch_Call) Run(run func(ctx context.Context, namespace string, name string, sinceRevision int64)) *Config_Watch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *Config_Watch_Call) Return(_a0 domain.Config, _a1 error) *Config_Watch_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Config_Update_Call) RunAndReturn(run func(string, []byte) error) *Config_Update_Call {
+func (_c *Config_Watch_Call) RunAndReturn(run func(context.Context, string, string, int64) (domain.Config, error)) *Config_Watch_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,250 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Schema is an autogenerated mock type for the Schema type
+type Schema struct {
+	mock.Mock
+}
+
+type Schema_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Schema) EXPECT() *Schema_Expecter {
+	return &Schema_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: name
+func (_m *Schema) Delete(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Schema_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type Schema_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - name string
+func (_e *Schema_Expecter) Delete(name interface{}) *Schema_Delete_Call {
+	return &Schema_Delete_Call{Call: _e.mock.On("Delete", name)}
+}
+
+func (_c *Schema_Delete_Call) Run(run func(name string)) *Schema_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Schema_Delete_Call) Return(_a0 error) *Schema_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Schema_Delete_Call) RunAndReturn(run func(string) error) *Schema_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: name
+func (_m *Schema) Get(name string) (domain.Schema, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Schema
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.Schema, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.Schema); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(domain.Schema)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Schema_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type Schema_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - name string
+func (_e *Schema_Expecter) Get(name interface{}) *Schema_Get_Call {
+	return &Schema_Get_Call{Call: _e.mock.On("Get", name)}
+}
+
+func (_c *Schema_Get_Call) Run(run func(name string)) *Schema_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Schema_Get_Call) Return(_a0 domain.Schema, _a1 error) *Schema_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Schema_Get_Call) RunAndReturn(run func(string) (domain.Schema, error)) *Schema_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with no fields
+func (_m *Schema) List() ([]domain.Schema, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Schema
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]domain.Schema, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []domain.Schema); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Schema)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Schema_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type Schema_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+func (_e *Schema_Expecter) List() *Schema_List_Call {
+	return &Schema_List_Call{Call: _e.mock.On("List")}
+}
+
+func (_c *Schema_List_Call) Run(run func()) *Schema_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Schema_List_Call) Return(_a0 []domain.Schema, _a1 error) *Schema_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Schema_List_Call) RunAndReturn(run func() ([]domain.Schema, error)) *Schema_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Put provides a mock function with given fields: schema
+func (_m *Schema) Put(schema domain.Schema) (bool, error) {
+	ret := _m.Called(schema)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Put")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.Schema) (bool, error)); ok {
+		return rf(schema)
+	}
+	if rf, ok := ret.Get(0).(func(domain.Schema) bool); ok {
+		r0 = rf(schema)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.Schema) error); ok {
+		r1 = rf(schema)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Schema_Put_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Put'
+type Schema_Put_Call struct {
+	*mock.Call
+}
+
+// Put is a helper method to define mock.On call
+//   - schema domain.Schema
+func (_e *Schema_Expecter) Put(schema interface{}) *Schema_Put_Call {
+	return &Schema_Put_Call{Call: _e.mock.On("Put", schema)}
+}
+
+func (_c *Schema_Put_Call) Run(run func(schema domain.Schema)) *Schema_Put_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(domain.Schema))
+	})
+	return _c
+}
+
+func (_c *Schema_Put_Call) Return(_a0 bool, _a1 error) *Schema_Put_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Schema_Put_Call) RunAndReturn(run func(domain.Schema) (bool, error)) *Schema_Put_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSchema creates a new instance of Schema. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSchema(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Schema {
+	mock := &Schema{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,390 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Webhook is an autogenerated mock type for the Webhook type
+type Webhook struct {
+	mock.Mock
+}
+
+type Webhook_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Webhook) EXPECT() *Webhook_Expecter {
+	return &Webhook_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *Webhook) Delete(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Webhook_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type Webhook_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - id string
+func (_e *Webhook_Expecter) Delete(id interface{}) *Webhook_Delete_Call {
+	return &Webhook_Delete_Call{Call: _e.mock.On("Delete", id)}
+}
+
+func (_c *Webhook_Delete_Call) Run(run func(id string)) *Webhook_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Webhook_Delete_Call) Return(_a0 error) *Webhook_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Webhook_Delete_Call) RunAndReturn(run func(string) error) *Webhook_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Deliveries provides a mock function with given fields: id
+func (_m *Webhook) Deliveries(id string) ([]domain.Delivery, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliveries")
+	}
+
+	var r0 []domain.Delivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.Delivery, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) []domain.Delivery); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Delivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Webhook_Deliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Deliveries'
+type Webhook_Deliveries_Call struct {
+	*mock.Call
+}
+
+// Deliveries is a helper method to define mock.On call
+//   - id string
+func (_e *Webhook_Expecter) Deliveries(id interface{}) *Webhook_Deliveries_Call {
+	return &Webhook_Deliveries_Call{Call: _e.mock.On("Deliveries", id)}
+}
+
+func (_c *Webhook_Deliveries_Call) Run(run func(id string)) *Webhook_Deliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Webhook_Deliveries_Call) Return(_a0 []domain.Delivery, _a1 error) *Webhook_Deliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Webhook_Deliveries_Call) RunAndReturn(run func(string) ([]domain.Delivery, error)) *Webhook_Deliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: id
+func (_m *Webhook) Get(id string) (domain.Webhook, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.Webhook, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.Webhook); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Webhook_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type Webhook_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - id string
+func (_e *Webhook_Expecter) Get(id interface{}) *Webhook_Get_Call {
+	return &Webhook_Get_Call{Call: _e.mock.On("Get", id)}
+}
+
+func (_c *Webhook_Get_Call) Run(run func(id string)) *Webhook_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Webhook_Get_Call) Return(_a0 domain.Webhook, _a1 error) *Webhook_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Webhook_Get_Call) RunAndReturn(run func(string) (domain.Webhook, error)) *Webhook_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with no fields
+func (_m *Webhook) List() ([]domain.Webhook, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]domain.Webhook, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []domain.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Webhook_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type Webhook_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+func (_e *Webhook_Expecter) List() *Webhook_List_Call {
+	return &Webhook_List_Call{Call: _e.mock.On("List")}
+}
+
+func (_c *Webhook_List_Call) Run(run func()) *Webhook_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Webhook_List_Call) Return(_a0 []domain.Webhook, _a1 error) *Webhook_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Webhook_List_Call) RunAndReturn(run func() ([]domain.Webhook, error)) *Webhook_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: webhook
+func (_m *Webhook) Save(webhook domain.Webhook) error {
+	ret := _m.Called(webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Webhook) error); ok {
+		r0 = rf(webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Webhook_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type Webhook_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - webhook domain.Webhook
+func (_e *Webhook_Expecter) Save(webhook interface{}) *Webhook_Save_Call {
+	return &Webhook_Save_Call{Call: _e.mock.On("Save", webhook)}
+}
+
+func (_c *Webhook_Save_Call) Run(run func(webhook domain.Webhook)) *Webhook_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(domain.Webhook))
+	})
+	return _c
+}
+
+func (_c *Webhook_Save_Call) Return(_a0 error) *Webhook_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Webhook_Save_Call) RunAndReturn(run func(domain.Webhook) error) *Webhook_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveDelivery provides a mock function with given fields: delivery
+func (_m *Webhook) SaveDelivery(delivery domain.Delivery) error {
+	ret := _m.Called(delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Delivery) error); ok {
+		r0 = rf(delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Webhook_SaveDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveDelivery'
+type Webhook_SaveDelivery_Call struct {
+	*mock.Call
+}
+
+// SaveDelivery is a helper method to define mock.On call
+//   - delivery domain.Delivery
+func (_e *Webhook_Expecter) SaveDelivery(delivery interface{}) *Webhook_SaveDelivery_Call {
+	return &Webhook_SaveDelivery_Call{Call: _e.mock.On("SaveDelivery", delivery)}
+}
+
+func (_c *Webhook_SaveDelivery_Call) Run(run func(delivery domain.Delivery)) *Webhook_SaveDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(domain.Delivery))
+	})
+	return _c
+}
+
+func (_c *Webhook_SaveDelivery_Call) Return(_a0 error) *Webhook_SaveDelivery_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Webhook_SaveDelivery_Call) RunAndReturn(run func(domain.Delivery) error) *Webhook_SaveDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: webhook
+func (_m *Webhook) Update(webhook domain.Webhook) error {
+	ret := _m.Called(webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Webhook) error); ok {
+		r0 = rf(webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Webhook_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type Webhook_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - webhook domain.Webhook
+func (_e *Webhook_Expecter) Update(webhook interface{}) *Webhook_Update_Call {
+	return &Webhook_Update_Call{Call: _e.mock.On("Update", webhook)}
+}
+
+func (_c *Webhook_Update_Call) Run(run func(webhook domain.Webhook)) *Webhook_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(domain.Webhook))
+	})
+	return _c
+}
+
+func (_c *Webhook_Update_Call) Return(_a0 error) *Webhook_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Webhook_Update_Call) RunAndReturn(run func(domain.Webhook) error) *Webhook_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewWebhook creates a new instance of Webhook. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhook(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Webhook {
+	mock := &Webhook{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"slices"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInvalidListOptions is used when the options to list configs aren't valid.
+	ErrInvalidListOptions = errors.New("invalid list options")
+	// ErrInvalidContinue is used when a continue token can't be used to get the next page,
+	// either because it's malformed or because it was issued for a different sort order.
+	ErrInvalidContinue = errors.New("invalid continue token")
+)
+
+// SortField is the field configs are sorted by.
+type SortField string
+
+const (
+	// SortByName sorts configs by their name.
+	SortByName SortField = "name"
+	// SortByUpdatedAt sorts configs by the time they were last updated.
+	SortByUpdatedAt SortField = "updatedAt"
+	// SortByRevision sorts configs by their revision.
+	SortByRevision SortField = "revision"
+)
+
+// ListOptions defines how configs are paged through.
+//
+// Configs are always sorted, falling back on their name and namespace
+// to break ties, so that the order is deterministic.
+type ListOptions struct {
+	// Limit is the maximum number of configs in a page.
+	// A limit of 0 gets every config in a single page.
+	Limit int
+	// Continue is the token of the page to get, as returned
+	// along with the previous page. It's empty for the first page.
+	Continue string
+	// SortBy is the field configs are sorted by, which is SortByName by default.
+	SortBy SortField
+	// Descending sorts configs from the greatest to the lowest.
+	Descending bool
+	// Resolved gets the configs with the metadata they inherit from their
+	// parents resolved, which is what Search matches against as well.
+	Resolved bool
+	// Interpolate gets the configs with the references in their metadata values
+	// replaced, as domain.Interpolate does. It's left to the service, which
+	// interpolates the page once it's got, so Search matches the raw values.
+	Interpolate bool
+}
+
+// Page is a page of configs.
+type Page struct {
+	// Configs are the configs in the page.
+	Configs []domain.Config
+	// Continue is the token to get the next page with,
+	// which is empty when this is the last page.
+	Continue string
+	// Total is the number of configs across every page.
+	Total int
+}
+
+// cursor is the position a page continues from, which is encoded
+// into an opaque continue token.
+type cursor struct {
+	SortBy     SortField  `json:"s"`
+	Descending bool       `json:"d,omitempty"`
+	Namespace  string     `json:"ns"`
+	Name       string     `json:"n"`
+	Revision   int64      `json:"r,omitempty"`
+	UpdatedAt  *time.Time `json:"u,omitempty"`
+}
+
+// validate checks opts, setting the default sort field.
+func (opts *ListOptions) validate() error {
+	if opts.Limit < 0 {
+		return fmt.Errorf("%w: limit can't be negative", ErrInvalidListOptions)
+	}
+
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = SortByName
+	case SortByName, SortByUpdatedAt, SortByRevision:
+	default:
+		return fmt.Errorf("%w: can't sort by %q", ErrInvalidListOptions, opts.SortBy)
+	}
+
+	return nil
+}
+
+// compareConfigs compares a and b by field, then by name and namespace.
+func compareConfigs(a, b domain.Config, field SortField) int {
+	var c int
+	switch field {
+	case SortByUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortByRevision:
+		c = cmp.Compare(a.Revision, b.Revision)
+	}
+
+	if c == 0 {
+		c = cmp.Compare(a.Name, b.Name)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.Namespace, b.Namespace)
+	}
+
+	return c
+}
+
+// paginate sorts configs and cuts out the page opts asks for.
+// configs is sorted in place.
+func paginate(configs []domain.Config, opts ListOptions) (Page, error) {
+	if err := opts.validate(); err != nil {
+		return Page{}, err
+	}
+
+	compare := func(a, b domain.Config) int {
+		if opts.Descending {
+			return compareConfigs(b, a, opts.SortBy)
+		}
+		return compareConfigs(a, b, opts.SortBy)
+	}
+	slices.SortFunc(configs, compare)
+
+	page := Page{Total: len(configs)}
+
+	start := 0
+	if opts.Continue != "" {
+		after, err := decodeCursor(opts)
+		if err != nil {
+			return Page{}, err
+		}
+		start = sort.Search(len(configs), func(i int) bool {
+			return compare(configs[i], after) > 0
+		})
+	}
+
+	end := len(configs)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+		page.Continue = encodeCursor(configs[end-1], opts)
+	}
+	page.Configs = configs[start:end]
+
+	return page, nil
+}
+
+// encodeCursor encodes the position right after last into a continue token.
+func encodeCursor(last domain.Config, opts ListOptions) string {
+	c := cursor{
+		SortBy:     opts.SortBy,
+		Descending: opts.Descending,
+		Namespace:  last.Namespace,
+		Name:       last.Name,
+	}
+	switch opts.SortBy {
+	case SortByUpdatedAt:
+		c.UpdatedAt = &last.UpdatedAt
+	case SortByRevision:
+		c.Revision = last.Revision
+	}
+
+	// a cursor is always marshalable.
+	bytes, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeCursor decodes the continue token in opts into the config it continues
+// after, making sure it was issued for the same sort order.
+func decodeCursor(opts ListOptions) (domain.Config, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(opts.Continue)
+	if err != nil {
+		return domain.Config{}, ErrInvalidContinue
+	}
+
+	var c cursor
+	if err := json.Unmarshal(bytes, &c); err != nil {
+		return domain.Config{}, ErrInvalidContinue
+	}
+
+	if c.SortBy != opts.SortBy || c.Descending != opts.Descending {
+		return domain.Config{}, fmt.Errorf("%w: it was issued for a different sort order", ErrInvalidContinue)
+	}
+
+	after := domain.Config{
+		Namespace: c.Namespace,
+		Name:      c.Name,
+		Revision:  c.Revision,
+	}
+	if c.UpdatedAt != nil {
+		after.UpdatedAt = *c.UpdatedAt
+	}
+
+	return after, nil
+}
@@ -0,0 +1,131 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInMemoryConfig_Pagination(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	// configs are created in the reverse order of their names,
+	// so that sorting by name and by revision tell apart.
+	for n := 9; n >= 0; n-- {
+		_, err := repo.Save(domain.Config{
+			Namespace: domain.DefaultNamespace,
+			Name:      fmt.Sprintf("config-%d", n),
+			Metadata:  []byte(fmt.Sprintf(`{"even": "%t"}`, n%2 == 0)),
+		})
+		require.NoError(t, err)
+	}
+
+	// pageThrough gets every page, returning the names of the configs in them.
+	pageThrough := func(t *testing.T, opts repository.ListOptions) []string {
+		t.Helper()
+
+		var names []string
+		for {
+			page, err := repo.List(domain.DefaultNamespace, opts)
+			require.NoError(t, err)
+			assert.Equal(t, 10, page.Total)
+			assert.LessOrEqual(t, len(page.Configs), opts.Limit)
+
+			for _, c := range page.Configs {
+				names = append(names, c.Name)
+			}
+			if page.Continue == "" {
+				return names
+			}
+			opts.Continue = page.Continue
+		}
+	}
+
+	t.Run("pages are sorted by name by default", func(t *testing.T) {
+		names := pageThrough(t, repository.ListOptions{Limit: 3})
+		assert.Equal(t, []string{
+			"config-0", "config-1", "config-2", "config-3", "config-4",
+			"config-5", "config-6", "config-7", "config-8", "config-9",
+		}, names)
+	})
+
+	t.Run("pages are sorted by revision in descending order", func(t *testing.T) {
+		names := pageThrough(t, repository.ListOptions{Limit: 4, SortBy: repository.SortByRevision, Descending: true})
+		assert.Equal(t, []string{
+			"config-0", "config-1", "config-2", "config-3", "config-4",
+			"config-5", "config-6", "config-7", "config-8", "config-9",
+		}, names)
+	})
+
+	t.Run("pages are sorted by update time", func(t *testing.T) {
+		names := pageThrough(t, repository.ListOptions{Limit: 5, SortBy: repository.SortByUpdatedAt})
+		assert.Len(t, names, 10)
+		assert.Equal(t, "config-9", names[0])
+	})
+
+	t.Run("changes between pages don't shift the next ones", func(t *testing.T) {
+		page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, []string{"config-0", "config-1"}, []string{page.Configs[0].Name, page.Configs[1].Name})
+
+		_, err = repo.Delete(domain.DefaultNamespace, "config-0")
+		require.NoError(t, err)
+		defer func() {
+			_, err := repo.Save(domain.Config{Namespace: domain.DefaultNamespace, Name: "config-0", Metadata: []byte(`{"even": "true"}`)})
+			require.NoError(t, err)
+		}()
+
+		next, err := repo.List(domain.DefaultNamespace, repository.ListOptions{Limit: 2, Continue: page.Continue})
+		require.NoError(t, err)
+		assert.Equal(t, "config-2", next.Configs[0].Name)
+	})
+
+	t.Run("search results are paged through", func(t *testing.T) {
+		page, err := repo.Search(domain.DefaultNamespace, mustParse(t, `even = true`), repository.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 5, page.Total)
+		assert.Len(t, page.Configs, 2)
+		assert.NotEmpty(t, page.Continue)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			opts    repository.ListOptions
+			wantErr error
+		}{
+			{
+				name:    "unknown sort field",
+				opts:    repository.ListOptions{SortBy: "metadata"},
+				wantErr: repository.ErrInvalidListOptions,
+			},
+			{
+				name:    "negative limit",
+				opts:    repository.ListOptions{Limit: -1},
+				wantErr: repository.ErrInvalidListOptions,
+			},
+			{
+				name:    "malformed continue token",
+				opts:    repository.ListOptions{Continue: "not a token"},
+				wantErr: repository.ErrInvalidContinue,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := repo.List(domain.DefaultNamespace, tt.opts)
+				assert.ErrorIs(t, err, tt.wantErr)
+			})
+		}
+
+		t.Run("continue token of a different sort order", func(t *testing.T) {
+			page, err := repo.List(domain.DefaultNamespace, repository.ListOptions{Limit: 2})
+			require.NoError(t, err)
+
+			_, err = repo.List(domain.DefaultNamespace, repository.ListOptions{Limit: 2, Continue: page.Continue, Descending: true})
+			assert.ErrorIs(t, err, repository.ErrInvalidContinue)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package repository
+
+import "github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+
+// indexTerms gets the equality conditions every config matching expr must
+// satisfy, which can be looked up in the metadata index. It also tells if
+// expr is made of nothing else, so that the lookups alone are enough.
+func indexTerms(expr query.Expr) ([]term, bool) {
+	var conditions []query.Expr
+	switch e := expr.(type) {
+	case nil:
+		return nil, true
+	case query.And:
+		conditions = e.Exprs
+	default:
+		conditions = []query.Expr{e}
+	}
+
+	var terms []term
+	exact := true
+	for _, c := range conditions {
+		if and, ok := c.(query.And); ok {
+			nested, nestedExact := indexTerms(and)
+			terms, exact = append(terms, nested...), exact && nestedExact
+			continue
+		}
+
+		condition, ok := c.(*query.Condition)
+		if !ok || condition.Op != query.Eq || condition.CaseInsensitive {
+			exact = false
+			continue
+		}
+		terms = append(terms, term{path: condition.Path, operand: condition.Values[0]})
+	}
+
+	return terms, exact
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"slices"
+	"sync"
+)
+
+// ErrSchemaNotFound is used when a given schema doesn't exist.
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// Schema is the port defining the I/O operations
+// for the domain.Schema resource.
+//
+//go:generate mockery --name Schema
+type Schema interface {
+	// List gets every schema, sorted by their name.
+	List() ([]domain.Schema, error)
+	// Get gets a schema identified by its name.
+	Get(name string) (domain.Schema, error)
+	// Put persists schema, replacing the one with the same name if any,
+	// but keeping the time it was created at. It tells if it's created.
+	Put(schema domain.Schema) (bool, error)
+	// Delete deletes a schema identified by its name.
+	Delete(name string) error
+}
+
+// NewInMemorySchema creates a new InMemorySchema instance.
+func NewInMemorySchema() *InMemorySchema {
+	return &InMemorySchema{schemas: make(map[string]domain.Schema)}
+}
+
+// InMemorySchema is an in-memory data store for schemas.
+type InMemorySchema struct {
+	mu      sync.RWMutex
+	schemas map[string]domain.Schema
+}
+
+// List fetches every schema from the in-memory datastore.
+func (i *InMemorySchema) List() ([]domain.Schema, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	schemas := make([]domain.Schema, 0, len(i.schemas))
+	for _, schema := range i.schemas {
+		schemas = append(schemas, schema)
+	}
+	slices.SortFunc(schemas, func(a, b domain.Schema) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return schemas, nil
+}
+
+// Get fetches a schema from the in-memory datastore.
+func (i *InMemorySchema) Get(name string) (domain.Schema, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	schema, ok := i.schemas[name]
+	if !ok {
+		return domain.Schema{}, ErrSchemaNotFound
+	}
+
+	return schema, nil
+}
+
+// Put creates or replaces a schema in the in-memory datastore.
+func (i *InMemorySchema) Put(schema domain.Schema) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	existing, ok := i.schemas[schema.Name]
+	if ok {
+		schema.CreatedAt = existing.CreatedAt
+	}
+	i.schemas[schema.Name] = schema
+
+	return !ok, nil
+}
+
+// Delete removes a schema from the in-memory datastore.
+func (i *InMemorySchema) Delete(name string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.schemas[name]; !ok {
+		return ErrSchemaNotFound
+	}
+	delete(i.schemas, name)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestInMemorySchema(t *testing.T) {
+	repo := repository.NewInMemorySchema()
+
+	now := time.Now()
+	flags := domain.Schema{Name: "flags", Pattern: "flags-*", Definition: []byte(`{}`), CreatedAt: now, UpdatedAt: now}
+	db := domain.Schema{Name: "db", Pattern: "db", Definition: []byte(`{}`), CreatedAt: now, UpdatedAt: now}
+
+	for _, schema := range []domain.Schema{flags, db} {
+		created, err := repo.Put(schema)
+		require.NoError(t, err)
+		assert.True(t, created)
+	}
+
+	t.Run("schemas are listed by name", func(t *testing.T) {
+		schemas, err := repo.List()
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Schema{db, flags}, schemas)
+	})
+
+	t.Run("schema is replaced", func(t *testing.T) {
+		replaced := flags
+		replaced.Pattern = "feature-flags"
+		replaced.CreatedAt, replaced.UpdatedAt = now.Add(time.Hour), now.Add(time.Hour)
+
+		created, err := repo.Put(replaced)
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		schema, err := repo.Get(flags.Name)
+		require.NoError(t, err)
+		assert.Equal(t, "feature-flags", schema.Pattern)
+		assert.Equal(t, now, schema.CreatedAt, "the creation time is kept")
+		assert.Equal(t, now.Add(time.Hour), schema.UpdatedAt)
+	})
+
+	t.Run("schema is deleted", func(t *testing.T) {
+		require.NoError(t, repo.Delete(db.Name))
+
+		_, err := repo.Get(db.Name)
+		assert.ErrorIs(t, err, repository.ErrSchemaNotFound)
+		assert.ErrorIs(t, repo.Delete(db.Name), repository.ErrSchemaNotFound)
+	})
+}
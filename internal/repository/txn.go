@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+)
+
+// CompareTarget is what a Compare checks about a config.
+type CompareTarget string
+
+const (
+	// CompareExists checks whether the config exists.
+	CompareExists CompareTarget = "exists"
+	// CompareRevision checks that the config is at a given revision.
+	CompareRevision CompareTarget = "revision"
+	// CompareMetadata checks that a key of the metadata of the config has a given value.
+	CompareMetadata CompareTarget = "metadata"
+)
+
+// Compare is a predicate on a config, checked by a transaction.
+type Compare struct {
+	// Target is what's checked about the config.
+	Target CompareTarget
+	// Namespace is the name of the namespace holding the config.
+	Namespace string
+	// Name is the name of the config.
+	Name string
+	// Exists is whether the config must exist, for CompareExists.
+	Exists bool
+	// Revision is the revision the config must be at, for CompareRevision.
+	Revision int64
+	// Key is the path of the value in the metadata, where each dot represents
+	// each key node in a different nest level, for CompareMetadata.
+	Key string
+	// Value is the value the key must have, for CompareMetadata.
+	Value string
+}
+
+// Txn is a transaction, running either its Success or its Failure operations
+// depending on whether every predicate in Compare holds.
+type Txn struct {
+	Compare []Compare
+	Success []Op
+	Failure []Op
+}
+
+// TxnResult is the outcome of a transaction.
+type TxnResult struct {
+	// Succeeded tells if every predicate held, so that
+	// the Success operations ran instead of the Failure ones.
+	Succeeded bool
+	// Results holds the outcome of every operation that ran, in the same order.
+	Results []OpResult
+}
+
+// Txn checks every predicate of txn and runs either its success or its
+// failure operations in the in-memory datastore, all under a single lock
+// acquisition. The operations are all-or-nothing: if any of them fails,
+// the ones before it are undone, and its error is returned.
+func (i *InMemoryConfig) Txn(txn Txn) (TxnResult, error) {
+	i.db.lock()
+	defer i.db.unlock()
+
+	result := TxnResult{Succeeded: true}
+	for _, c := range txn.Compare {
+		ok, err := i.db.compare(c)
+		if err != nil {
+			return TxnResult{}, err
+		}
+		if !ok {
+			result.Succeeded = false
+			break
+		}
+	}
+
+	ops := txn.Success
+	if !result.Succeeded {
+		ops = txn.Failure
+	}
+
+	results, err := i.db.execAll(ops)
+	if err != nil {
+		return TxnResult{}, err
+	}
+	result.Results = results
+
+	return result, nil
+}
+
+// compare tells if c holds against the state. Callers must hold the lock.
+func (i *inMemoryDBState) compare(c Compare) (bool, error) {
+	config, exists := i.configs[configKey{c.Namespace, c.Name}]
+
+	switch c.Target {
+	case CompareExists:
+		return exists == c.Exists, nil
+	case CompareRevision:
+		return exists && config.Revision == c.Revision, nil
+	case CompareMetadata:
+		condition := &query.Condition{Path: c.Key, Op: query.Eq, Values: []string{c.Value}}
+		return exists && query.Match(condition, domain.WithoutSecrets(config.Metadata)), nil
+	default:
+		return false, fmt.Errorf("%w: unknown compare target %q", ErrInvalidOp, c.Target)
+	}
+}
+
+// execAll runs ops in order as a single change: if any of them fails, the
+// ones before it are undone, and its error is returned. Callers must hold the lock.
+func (i *inMemoryDBState) execAll(ops []Op) ([]OpResult, error) {
+	// keep whatever is needed to bring the configs touched by ops back.
+	type saved struct {
+		config  domain.Config
+		history []domain.Config
+		exists  bool
+	}
+	before := make(map[configKey]saved)
+	for _, op := range ops {
+		key := configKey{op.Namespace, op.Name}
+		if _, ok := before[key]; !ok {
+			config, exists := i.configs[key]
+			before[key] = saved{config: config, history: i.history[key], exists: exists}
+		}
+	}
+	revision := i.revision
+
+	results := make([]OpResult, len(ops))
+	err := i.batch(func() error {
+		for n, op := range ops {
+			results[n] = i.exec(op)
+			if results[n].Err != nil {
+				return fmt.Errorf("operation %d: %w", n, results[n].Err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for key, s := range before {
+			if !s.exists {
+				delete(i.configs, key)
+				delete(i.history, key)
+				i.index.remove(key)
+				continue
+			}
+			i.configs[key] = s.config
+			i.history[key] = s.history
+			i.index.add(s.config)
+		}
+		i.revision = revision
+
+		return nil, err
+	}
+
+	return results, nil
+}
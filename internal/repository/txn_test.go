@@ -0,0 +1,158 @@
+package repository_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInMemoryConfig_Txn(t *testing.T) {
+	newRepo := func(t *testing.T) (repository.Config, domain.Config) {
+		repo := repository.NewInMemoryConfig(repository.WithCustomData(test.GenerateInMemoryTestData(t)))
+		current, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		return repo, current
+	}
+
+	compare := func(c repository.Compare) repository.Compare {
+		c.Namespace, c.Name = domain.DefaultNamespace, test.ConfigName1
+		return c
+	}
+
+	metadata := []byte(`{"foo": "txn"}`)
+	success := []repository.Op{
+		{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: test.ConfigName1, Metadata: metadata},
+	}
+	failure := []repository.Op{
+		{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: "failed", Metadata: metadata},
+	}
+
+	t.Run("predicates", func(t *testing.T) {
+		_, current := newRepo(t)
+
+		tests := []struct {
+			name    string
+			compare []repository.Compare
+			want    bool
+		}{
+			{
+				name: "no predicates",
+				want: true,
+			},
+			{
+				name:    "config exists",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareExists, Exists: true})},
+				want:    true,
+			},
+			{
+				name:    "config doesn't exist",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareExists})},
+				want:    false,
+			},
+			{
+				name:    "revision equals",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareRevision, Revision: current.Revision})},
+				want:    true,
+			},
+			{
+				name:    "revision differs",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareRevision, Revision: current.Revision + 1})},
+				want:    false,
+			},
+			{
+				name:    "metadata key equals",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareMetadata, Key: "obj.aaa", Value: "bbb"})},
+				want:    true,
+			},
+			{
+				name:    "metadata key differs",
+				compare: []repository.Compare{compare(repository.Compare{Target: repository.CompareMetadata, Key: "foo", Value: "baz"})},
+				want:    false,
+			},
+			{
+				name: "every predicate must hold",
+				compare: []repository.Compare{
+					compare(repository.Compare{Target: repository.CompareExists, Exists: true}),
+					compare(repository.Compare{Target: repository.CompareMetadata, Key: "foo", Value: "baz"}),
+				},
+				want: false,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo, _ := newRepo(t)
+
+				result, err := repo.Txn(repository.Txn{Compare: tt.compare, Success: success, Failure: failure})
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, result.Succeeded)
+				require.Len(t, result.Results, 1)
+
+				name := test.ConfigName1
+				if !tt.want {
+					name = "failed"
+				}
+				config, err := repo.Get(domain.DefaultNamespace, name)
+				require.NoError(t, err)
+				assert.Equal(t, metadata, config.Metadata)
+			})
+		}
+	})
+
+	t.Run("unknown compare target", func(t *testing.T) {
+		repo, _ := newRepo(t)
+
+		_, err := repo.Txn(repository.Txn{Compare: []repository.Compare{compare(repository.Compare{Target: "size"})}})
+		assert.ErrorIs(t, err, repository.ErrInvalidOp)
+	})
+
+	t.Run("a failing operation undoes the rest", func(t *testing.T) {
+		repo, current := newRepo(t)
+		before, err := repo.Get(domain.DefaultNamespace, test.ConfigName2)
+		require.NoError(t, err)
+
+		_, err = repo.Txn(repository.Txn{Success: []repository.Op{
+			{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: test.ConfigName1, Metadata: metadata},
+			{Type: repository.OpUpdate, Namespace: domain.DefaultNamespace, Name: test.ConfigName1, Metadata: metadata},
+			{Type: repository.OpDelete, Namespace: domain.DefaultNamespace, Name: test.ConfigName2},
+			{Type: repository.OpCreate, Namespace: domain.DefaultNamespace, Name: "new", Metadata: metadata},
+			{Type: repository.OpDelete, Namespace: domain.DefaultNamespace, Name: "nope"},
+		}})
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+
+		config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Equal(t, current, config)
+
+		revisions, err := repo.Revisions(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+
+		config, err = repo.Get(domain.DefaultNamespace, test.ConfigName2)
+		require.NoError(t, err)
+		assert.Equal(t, before, config)
+
+		_, err = repo.Get(domain.DefaultNamespace, "new")
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+
+		t.Run("undone changes aren't found by searches", func(t *testing.T) {
+			expr := mustParse(t, "foo = txn")
+			page, err := repo.Search(domain.DefaultNamespace, expr, repository.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Configs)
+		})
+
+		t.Run("the next change takes the next revision", func(t *testing.T) {
+			_, err := repo.Update(domain.DefaultNamespace, test.ConfigName1, metadata)
+			require.NoError(t, err)
+
+			config, err := repo.Get(domain.DefaultNamespace, test.ConfigName1)
+			require.NoError(t, err)
+			assert.Greater(t, config.Revision, before.Revision)
+			assert.Greater(t, config.Revision, current.Revision)
+		})
+	})
+}
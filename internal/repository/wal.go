@@ -0,0 +1,476 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+	// recordHeaderSize is the size of the header preceding every record
+	// in the log: the payload length followed by its CRC-32 checksum.
+	recordHeaderSize = 8
+	// maxRecordSize protects the replay from allocating absurd amounts of
+	// memory when the length in a record header is garbage.
+	maxRecordSize = 64 << 20
+)
+
+var (
+	// ErrCorruptedLog is used when the write-ahead log is damaged
+	// somewhere other than its final record, which can't be explained
+	// by an interrupted write and therefore isn't fixed automatically.
+	ErrCorruptedLog = errors.New("write-ahead log is corrupted")
+
+	// errTornRecord is used when a record can't be fully read back from the log.
+	errTornRecord = errors.New("torn record")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// recordOp is the kind of change described by a record.
+type recordOp string
+
+const (
+	opPut             recordOp = "put"
+	opDelete          recordOp = "delete"
+	opPutNamespace    recordOp = "putNamespace"
+	opDeleteNamespace recordOp = "deleteNamespace"
+)
+
+// record is a single change applied to the inMemoryDBState.
+type record struct {
+	// Seq is the position of the record in the history of changes.
+	Seq    uint64        `json:"seq"`
+	Op     recordOp      `json:"op"`
+	Config domain.Config `json:"config"`
+	// Namespace is only set by the namespace operations.
+	Namespace *domain.Namespace `json:"namespace,omitempty"`
+}
+
+// journal durably records the changes applied to an inMemoryDBState,
+// so that the state can be rebuilt after a restart.
+type journal interface {
+	// write persists rec before it gets applied to the state.
+	write(rec *record) error
+	// applied is called right after rec has been applied to the state,
+	// while the state lock is still held.
+	applied(state *inMemoryDBState)
+	// batch runs fn, which writes any number of records, flushing them
+	// all at once afterward. If they can't be flushed, they're discarded,
+	// and state is brought back to the last change that's durable.
+	// If fn fails, its records are discarded instead of flushed, leaving
+	// it up to the caller to undo the changes made to state.
+	batch(state *inMemoryDBState, fn func() error) error
+}
+
+// snapshot is the compacted form of the state, holding the history of
+// every config as of the record identified by Seq.
+type snapshot struct {
+	Seq        uint64             `json:"seq"`
+	Revision   int64              `json:"revision"`
+	Namespaces []domain.Namespace `json:"namespaces"`
+	// History holds every revision of each config, keyed by namespace and name,
+	// where the last revision is the current config.
+	History map[string][]domain.Config `json:"history"`
+}
+
+// wal is the write-ahead log journal. Every record is appended to a log file
+// and fsync'd before being applied, and once the log grows past
+// compactionThreshold records, the whole state is written as a snapshot
+// and the log starts over.
+type wal struct {
+	dir  string
+	file *os.File
+	// size is the size in bytes of the valid portion of the log file.
+	size int64
+	// seq is the sequence number of the last record written.
+	seq uint64
+	// records is the number of records in the log since the last snapshot.
+	records             int
+	compactionThreshold int
+	// batching defers flushing the records to the end of a batch.
+	batching bool
+}
+
+// openWAL restores state from the snapshot and the log in dir, and returns
+// the wal ready to journal further changes.
+func openWAL(dir string, compactionThreshold int, state *inMemoryDBState) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	w := &wal{
+		dir:                 dir,
+		compactionThreshold: compactionThreshold,
+	}
+
+	if err := w.loadSnapshot(state); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	w.file = file
+
+	if err := w.replay(state); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// loadSnapshot populates state with the latest snapshot, if there's any.
+func (w *wal) loadSnapshot(state *inMemoryDBState) error {
+	bytes, err := os.ReadFile(filepath.Join(w.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(bytes, &snap); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	for _, ns := range snap.Namespaces {
+		state.namespaces[ns.Name] = ns
+	}
+	for _, history := range snap.History {
+		if len(history) == 0 {
+			continue
+		}
+		current := history[len(history)-1]
+		// configs stored before namespaces existed belong to the default one.
+		if current.Namespace == "" {
+			current.Namespace = domain.DefaultNamespace
+		}
+		state.configs[keyOf(current)] = current
+		state.history[keyOf(current)] = history
+		state.index.add(current)
+	}
+	state.revision = snap.Revision
+	w.seq = snap.Seq
+
+	return nil
+}
+
+// replay applies every record in the log that isn't part of the snapshot yet.
+// A torn final record, left behind by a crash in the middle of a write,
+// is truncated away.
+func (w *wal) replay(state *inMemoryDBState) error {
+	info, err := w.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat write-ahead log: %w", err)
+	}
+
+	reader := bufio.NewReader(w.file)
+	for {
+		rec, n, err := readRecord(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errTornRecord) {
+			// a damaged record is only expected to be the final one,
+			// otherwise, it's something that must be looked into.
+			if w.size+n < info.Size() {
+				return fmt.Errorf("%w: bad record at offset %d", ErrCorruptedLog, w.size)
+			}
+			log.Printf("Truncating torn record at offset %d of the write-ahead log", w.size)
+			if err := w.file.Truncate(w.size); err != nil {
+				return fmt.Errorf("failed to truncate torn record: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		w.size += n
+		w.records++
+
+		// records already compacted into the snapshot must not be applied twice.
+		if rec.Seq <= w.seq {
+			continue
+		}
+		state.apply(rec)
+		w.seq = rec.Seq
+	}
+
+	if _, err := w.file.Seek(w.size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek write-ahead log: %w", err)
+	}
+
+	return nil
+}
+
+// readRecord reads the next record from r, returning it along with the number
+// of bytes it takes in the log.
+// It returns io.EOF when there's no record left, and errTornRecord when the
+// record is incomplete or doesn't match its checksum.
+func readRecord(r io.Reader) (record, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if errors.Is(err, io.EOF) {
+		return record{}, 0, io.EOF
+	}
+	if err != nil {
+		return record{}, int64(n), errTornRecord
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	checksum := binary.BigEndian.Uint32(header[4:])
+	if length > maxRecordSize {
+		return record{}, int64(n), errTornRecord
+	}
+
+	payload := make([]byte, length)
+	m, err := io.ReadFull(r, payload)
+	size := int64(n + m)
+	if err != nil {
+		return record{}, size, errTornRecord
+	}
+	if crc32.Checksum(payload, crcTable) != checksum {
+		return record{}, size, errTornRecord
+	}
+
+	var rec record
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return record{}, size, errTornRecord
+	}
+
+	return rec, size, nil
+}
+
+// write appends rec to the log and waits for it to be flushed to disk.
+func (w *wal) write(rec *record) error {
+	rec.Seq = w.seq + 1
+
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[recordHeaderSize:], payload)
+
+	if _, err := w.file.Write(buf); err != nil {
+		// don't leave a partial record behind for the next writes
+		// to be appended after.
+		w.rollback()
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if !w.batching {
+		if err := w.file.Sync(); err != nil {
+			w.rollback()
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+
+	w.size += int64(len(buf))
+	w.seq = rec.Seq
+
+	return nil
+}
+
+// batch runs fn, flushing the records it writes to disk only once it's done,
+// so that a whole batch of changes takes a single sync.
+func (w *wal) batch(state *inMemoryDBState, fn func() error) error {
+	size, seq, records := w.size, w.seq, w.records
+
+	w.batching = true
+	err := fn()
+	w.batching = false
+
+	if err != nil {
+		// nothing written by fn has been flushed yet, so it's simply dropped.
+		w.size, w.seq, w.records = size, seq, records
+		w.rollback()
+		return err
+	}
+
+	if w.size == size {
+		return nil
+	}
+
+	if err := w.file.Sync(); err != nil {
+		// the batch has been applied to the state already, so it's rebuilt
+		// out of the records that made it to disk before the batch.
+		w.size = size
+		w.rollback()
+		if reloadErr := w.reload(state); reloadErr != nil {
+			log.Printf("Failed to reload the state after a failed batch: %s", reloadErr.Error())
+		}
+		return fmt.Errorf("failed to sync write-ahead log: %w", err)
+	}
+
+	// compaction was held off until the batch was durable.
+	w.maybeCompact(state)
+
+	return nil
+}
+
+// reload rebuilds state from the snapshot and the log from scratch.
+func (w *wal) reload(state *inMemoryDBState) error {
+	fresh := newInMemoryDBState()
+	state.namespaces = fresh.namespaces
+	state.configs = fresh.configs
+	state.history = fresh.history
+	state.index = fresh.index
+	state.revision = 0
+
+	w.size, w.seq, w.records = 0, 0, 0
+	if err := w.loadSnapshot(state); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek write-ahead log: %w", err)
+	}
+
+	return w.replay(state)
+}
+
+// rollback discards anything written to the log after its last valid record.
+func (w *wal) rollback() {
+	if err := w.file.Truncate(w.size); err != nil {
+		log.Printf("Failed to roll back write-ahead log: %s", err.Error())
+		return
+	}
+	if _, err := w.file.Seek(w.size, io.SeekStart); err != nil {
+		log.Printf("Failed to roll back write-ahead log: %s", err.Error())
+	}
+}
+
+// applied compacts the log into a snapshot once it gets past the threshold.
+// A failed compaction doesn't affect durability, so it's only logged
+// and tried again on the next change.
+func (w *wal) applied(state *inMemoryDBState) {
+	w.records++
+	w.maybeCompact(state)
+}
+
+// maybeCompact compacts the log into a snapshot if it's past the threshold,
+// unless in the middle of a batch that isn't durable yet.
+func (w *wal) maybeCompact(state *inMemoryDBState) {
+	if w.batching || w.compactionThreshold <= 0 || w.records < w.compactionThreshold {
+		return
+	}
+
+	if err := w.compact(state); err != nil {
+		log.Printf("Failed to compact write-ahead log: %s", err.Error())
+	}
+}
+
+// compact writes the whole state as a snapshot and empties the log.
+func (w *wal) compact(state *inMemoryDBState) error {
+	snap := snapshot{
+		Seq:      w.seq,
+		Revision: state.revision,
+		History:  make(map[string][]domain.Config, len(state.history)),
+	}
+	for _, ns := range state.namespaces {
+		snap.Namespaces = append(snap.Namespaces, ns)
+	}
+	sort.Slice(snap.Namespaces, func(a, b int) bool {
+		return snap.Namespaces[a].Name < snap.Namespaces[b].Name
+	})
+	for key, history := range state.history {
+		snap.History[key.namespace+"/"+key.name] = history
+	}
+
+	bytes, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := replaceFile(w.dir, snapshotFileName, bytes); err != nil {
+		return err
+	}
+
+	// from now on, the records in the log are all part of the snapshot,
+	// and if the truncation below fails, they're skipped on replay.
+	if err := w.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate write-ahead log: %w", err)
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek write-ahead log: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync write-ahead log: %w", err)
+	}
+	w.size = 0
+	w.records = 0
+
+	return nil
+}
+
+// close releases the log file.
+func (w *wal) close() error {
+	return w.file.Close()
+}
+
+// replaceFile replaces the file named name in dir with data. It's written to
+// a temporary file first and then renamed, so that there's always a complete
+// file on disk.
+func replaceFile(dir, name string, data []byte) error {
+	path := filepath.Join(dir, name)
+	if err := writeFileSync(path+".tmp", data); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", name, err)
+	}
+
+	return syncDir(dir)
+}
+
+// writeFileSync writes data to the file in path and flushes it to disk.
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory entries in dir to disk, making renames durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open data directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync data directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrWebhookNotFound is used when a given webhook doesn't exist.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookExists is used when there's already a webhook with the same ID.
+	ErrWebhookExists = errors.New("webhook already exists")
+)
+
+// DeliveryHistorySize is the number of the latest deliveries kept for each webhook.
+const DeliveryHistorySize = 100
+
+// Webhook is the port defining the I/O operations
+// for the domain.Webhook resource.
+//
+//go:generate mockery --name Webhook
+type Webhook interface {
+	// List gets every webhook, sorted by their creation time.
+	List() ([]domain.Webhook, error)
+	// Save persists a new webhook.
+	Save(webhook domain.Webhook) error
+	// Get gets a webhook identified by its ID.
+	Get(id string) (domain.Webhook, error)
+	// Update replaces the webhook identified by the ID of webhook.
+	Update(webhook domain.Webhook) error
+	// Delete deletes a webhook identified by its ID, along with its deliveries.
+	Delete(id string) error
+	// SaveDelivery persists delivery, replacing the one with the same ID if any.
+	// Only the latest DeliveryHistorySize deliveries of a webhook are kept.
+	SaveDelivery(delivery domain.Delivery) error
+	// Deliveries gets the deliveries of the webhook identified by its ID,
+	// from the oldest to the latest.
+	Deliveries(id string) ([]domain.Delivery, error)
+}
+
+// NewInMemoryWebhook creates a new InMemoryWebhook instance.
+func NewInMemoryWebhook() *InMemoryWebhook {
+	return &InMemoryWebhook{
+		webhooks:   make(map[string]domain.Webhook),
+		deliveries: make(map[string][]domain.Delivery),
+	}
+}
+
+// InMemoryWebhook is an in-memory data store for webhooks.
+type InMemoryWebhook struct {
+	mu         sync.RWMutex
+	webhooks   map[string]domain.Webhook
+	deliveries map[string][]domain.Delivery
+}
+
+// List fetches every webhook from the in-memory datastore.
+func (i *InMemoryWebhook) List() ([]domain.Webhook, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	webhooks := make([]domain.Webhook, 0, len(i.webhooks))
+	for _, webhook := range i.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return webhooks, nil
+}
+
+// Save creates a new webhook in the in-memory datastore.
+func (i *InMemoryWebhook) Save(webhook domain.Webhook) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.webhooks[webhook.ID]; ok {
+		return ErrWebhookExists
+	}
+	i.webhooks[webhook.ID] = webhook
+
+	return nil
+}
+
+// Get fetches a webhook from the in-memory datastore.
+func (i *InMemoryWebhook) Get(id string) (domain.Webhook, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	webhook, ok := i.webhooks[id]
+	if !ok {
+		return domain.Webhook{}, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// Update replaces a webhook in the in-memory datastore.
+func (i *InMemoryWebhook) Update(webhook domain.Webhook) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.webhooks[webhook.ID]; !ok {
+		return ErrWebhookNotFound
+	}
+	i.webhooks[webhook.ID] = webhook
+
+	return nil
+}
+
+// Delete removes a webhook and its deliveries from the in-memory datastore.
+func (i *InMemoryWebhook) Delete(id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(i.webhooks, id)
+	delete(i.deliveries, id)
+
+	return nil
+}
+
+// SaveDelivery stores a delivery in the in-memory datastore. Deliveries
+// of webhooks that don't exist anymore are dropped.
+func (i *InMemoryWebhook) SaveDelivery(delivery domain.Delivery) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.webhooks[delivery.WebhookID]; !ok {
+		return ErrWebhookNotFound
+	}
+
+	deliveries := i.deliveries[delivery.WebhookID]
+	if n := slices.IndexFunc(deliveries, func(d domain.Delivery) bool { return d.ID == delivery.ID }); n >= 0 {
+		deliveries[n] = delivery
+		return nil
+	}
+
+	deliveries = append(deliveries, delivery)
+	if len(deliveries) > DeliveryHistorySize {
+		deliveries = slices.Delete(deliveries, 0, len(deliveries)-DeliveryHistorySize)
+	}
+	i.deliveries[delivery.WebhookID] = deliveries
+
+	return nil
+}
+
+// Deliveries fetches the deliveries of a webhook from the in-memory datastore.
+func (i *InMemoryWebhook) Deliveries(id string) ([]domain.Delivery, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if _, ok := i.webhooks[id]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+
+	return slices.Clone(i.deliveries[id]), nil
+}
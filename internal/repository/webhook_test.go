@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestInMemoryWebhook(t *testing.T) {
+	repo := repository.NewInMemoryWebhook()
+
+	now := time.Now()
+	first := domain.Webhook{ID: "b", URL: "http://example.com/first", Secret: "s3cr3t", CreatedAt: now}
+	second := domain.Webhook{ID: "a", URL: "http://example.com/second", Secret: "s3cr3t", CreatedAt: now.Add(time.Second)}
+	require.NoError(t, repo.Save(first))
+	require.NoError(t, repo.Save(second))
+
+	t.Run("webhooks are listed by creation time", func(t *testing.T) {
+		webhooks, err := repo.List()
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Webhook{first, second}, webhooks)
+	})
+
+	t.Run("webhook can't be saved twice", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(first), repository.ErrWebhookExists)
+	})
+
+	t.Run("webhook is updated", func(t *testing.T) {
+		updated := first
+		updated.Prefix = "web-"
+		require.NoError(t, repo.Update(updated))
+
+		webhook, err := repo.Get(first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "web-", webhook.Prefix)
+	})
+
+	t.Run("deliveries are kept", func(t *testing.T) {
+		delivery := domain.Delivery{ID: "d1", WebhookID: first.ID, Status: domain.DeliveryPending}
+		require.NoError(t, repo.SaveDelivery(delivery))
+
+		delivery.Status = domain.DeliverySucceeded
+		require.NoError(t, repo.SaveDelivery(delivery))
+
+		deliveries, err := repo.Deliveries(first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Delivery{delivery}, deliveries)
+	})
+
+	t.Run("only the latest deliveries are kept", func(t *testing.T) {
+		for n := range repository.DeliveryHistorySize + 10 {
+			require.NoError(t, repo.SaveDelivery(domain.Delivery{ID: fmt.Sprint(n), WebhookID: second.ID}))
+		}
+
+		deliveries, err := repo.Deliveries(second.ID)
+		require.NoError(t, err)
+		require.Len(t, deliveries, repository.DeliveryHistorySize)
+		assert.Equal(t, "10", deliveries[0].ID)
+	})
+
+	t.Run("webhook is deleted along with its deliveries", func(t *testing.T) {
+		require.NoError(t, repo.Delete(first.ID))
+
+		_, err := repo.Get(first.ID)
+		assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+
+		_, err = repo.Deliveries(first.ID)
+		assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+
+		assert.ErrorIs(t, repo.SaveDelivery(domain.Delivery{ID: "d2", WebhookID: first.ID}), repository.ErrWebhookNotFound)
+	})
+
+	t.Run("webhook not found", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Update(domain.Webhook{ID: "nope"}), repository.ErrWebhookNotFound)
+		assert.ErrorIs(t, repo.Delete("nope"), repository.ErrWebhookNotFound)
+	})
+}
@@ -1,49 +1,465 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"time"
 )
 
+// Option customizes a Config service instance.
+type Option func(*Config)
+
+// WithEvents publishes every change made to configs through events.
+func WithEvents(events *Events) Option {
+	return func(c *Config) {
+		c.events = events
+	}
+}
+
+// WithWebhooks posts every change made to configs to the matching webhooks.
+func WithWebhooks(webhooks *Webhooks) Option {
+	return func(c *Config) {
+		c.webhooks = webhooks
+	}
+}
+
+// WithSchemas rejects every change leaving the metadata of a config violating
+// any of the schemas attached to it.
+func WithSchemas(schemas *Schemas) Option {
+	return func(c *Config) {
+		c.schemas = schemas
+	}
+}
+
+// WithSecrets encrypts the metadata values marked as secret with secrets
+// before they're stored. Without it, such values are rejected.
+func WithSecrets(secrets *Secrets) Option {
+	return func(c *Config) {
+		c.secrets = secrets
+	}
+}
+
 // NewConfig creates a new Config service instance.
-func NewConfig(repo repository.Config) *Config {
-	return &Config{repo}
+func NewConfig(repo repository.Config, opts ...Option) *Config {
+	c := &Config{repo: repo}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // Config abstracts away the complexity of interacting
 // with repositories to serve the config resources.
+//
+// Configs are identified by their name within a namespace.
 type Config struct {
-	repo repository.Config
+	repo     repository.Config
+	events   *Events
+	webhooks *Webhooks
+	schemas  *Schemas
+	secrets  *Secrets
 }
 
-// List gets a list of configs.
-func (c Config) List() ([]domain.Config, error) {
-	return c.repo.List()
+// List gets the page of the configs in namespace described by opts.
+func (c Config) List(namespace string, opts repository.ListOptions) (repository.Page, error) {
+	page, err := c.repo.List(namespace, opts)
+	if err != nil || !opts.Interpolate {
+		return page, err
+	}
+
+	return c.interpolatePage(page)
 }
 
 // Create creates a new config according to cfg.
+// Configs without a namespace are created in the default namespace.
 func (c Config) Create(cfg domain.Config) error {
-	return c.repo.Save(cfg)
+	if cfg.Namespace == "" {
+		cfg.Namespace = domain.DefaultNamespace
+	}
+
+	var err error
+	if cfg.Metadata, err = c.prepare(cfg.Namespace, cfg.Name, cfg.Metadata, nil); err != nil {
+		return err
+	}
+	created, err := c.repo.Save(cfg)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventCreated, created)
+
+	return nil
 }
 
 // Get gets a config identified by its name.
-func (c Config) Get(name string) (domain.Config, error) {
-	return c.repo.Get(name)
+func (c Config) Get(namespace, name string) (domain.Config, error) {
+	return c.repo.Get(namespace, name)
+}
+
+// Resolve gets the config identified by name with the metadata it inherits
+// from its parents deep merged under its own.
+func (c Config) Resolve(namespace, name string) (domain.Config, error) {
+	return c.repo.Resolve(namespace, name)
+}
+
+// Interpolate gets cfg with every reference in its metadata values replaced by
+// the value it points at, in the configs of its namespace, as domain.Interpolate
+// does. cfg itself is left as it is, so its raw values are still at hand.
+func (c Config) Interpolate(cfg domain.Config) (domain.Config, error) {
+	return domain.Interpolate(cfg, c.lookup(cfg.Namespace))
+}
+
+// Descendants gets every config inheriting from the config identified by name,
+// directly or not, sorted by name.
+func (c Config) Descendants(namespace, name string) ([]domain.Config, error) {
+	return c.repo.Descendants(namespace, name)
+}
+
+// SetParents sets the configs the config identified by name inherits from.
+// A non-zero revision makes it conditional, just like CompareAndSwap.
+func (c Config) SetParents(namespace, name string, revision int64, parents []string) error {
+	updated, err := c.repo.SetParents(namespace, name, revision, parents)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventUpdated, updated)
+
+	return nil
 }
 
 // Update updates the config identified by name applying whatever is in metadata.
-func (c Config) Update(name string, metadata []byte) error {
-	return c.repo.Update(name, metadata)
+func (c Config) Update(namespace, name string, metadata []byte) error {
+	metadata, err := c.prepare(namespace, name, metadata, nil)
+	if err != nil {
+		return err
+	}
+	updated, err := c.repo.Update(namespace, name, metadata)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventUpdated, updated)
+
+	return nil
+}
+
+// CompareAndSwap updates the config identified by name applying whatever is
+// in metadata, as long as the config is still at revision.
+func (c Config) CompareAndSwap(namespace, name string, revision int64, metadata []byte) error {
+	metadata, err := c.prepare(namespace, name, metadata, nil)
+	if err != nil {
+		return err
+	}
+	updated, err := c.repo.CompareAndSwap(namespace, name, revision, metadata)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventUpdated, updated)
+
+	return nil
+}
+
+// Patch atomically updates the config identified by name with the metadata
+// computed by patch out of its current metadata. A non-zero revision makes it
+// conditional, just like CompareAndSwap.
+func (c Config) Patch(namespace, name string, revision int64, patch repository.PatchFunc) error {
+	updated, err := c.repo.Patch(namespace, name, revision, func(metadata []byte) ([]byte, error) {
+		patched, err := patch(metadata)
+		if err != nil {
+			return nil, err
+		}
+		return c.prepare(namespace, name, patched, metadata)
+	})
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventUpdated, updated)
+
+	return nil
 }
 
 // Delete removes the config identified by name.
-func (c Config) Delete(name string) error {
-	return c.repo.Delete(name)
+func (c Config) Delete(namespace, name string) error {
+	deleted, err := c.repo.Delete(namespace, name)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventDeleted, deleted)
+
+	return nil
+}
+
+// CompareAndDelete removes the config identified by name,
+// as long as the config is still at revision.
+func (c Config) CompareAndDelete(namespace, name string, revision int64) error {
+	deleted, err := c.repo.CompareAndDelete(namespace, name, revision)
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventDeleted, deleted)
+
+	return nil
+}
+
+// Bulk runs every operation in ops in order, reporting the outcome of each of them.
+// Operations without a namespace are run in the default namespace.
+func (c Config) Bulk(ops []repository.Op) []repository.OpResult {
+	for n := range ops {
+		if ops[n].Namespace == "" {
+			ops[n].Namespace = domain.DefaultNamespace
+		}
+	}
+
+	// operations violating schemas fail on their own, without being run.
+	results := make([]repository.OpResult, len(ops))
+	var valid []repository.Op
+	var indexes []int
+	for n, op := range ops {
+		op, err := c.prepareOp(op)
+		if err != nil {
+			results[n] = repository.OpResult{Err: err}
+			continue
+		}
+		valid = append(valid, op)
+		indexes = append(indexes, n)
+	}
+
+	for n, result := range c.repo.Bulk(valid) {
+		results[indexes[n]] = result
+	}
+	c.publishResults(ops, results)
+
+	return results
+}
+
+// Txn runs either the success or the failure operations of txn atomically,
+// depending on whether its predicates hold. Predicates and operations
+// without a namespace apply to the default namespace.
+func (c Config) Txn(txn repository.Txn) (repository.TxnResult, error) {
+	for n := range txn.Compare {
+		if txn.Compare[n].Namespace == "" {
+			txn.Compare[n].Namespace = domain.DefaultNamespace
+		}
+	}
+	for _, ops := range [][]repository.Op{txn.Success, txn.Failure} {
+		for n := range ops {
+			if ops[n].Namespace == "" {
+				ops[n].Namespace = domain.DefaultNamespace
+			}
+			var err error
+			if ops[n], err = c.prepareOp(ops[n]); err != nil {
+				return repository.TxnResult{}, err
+			}
+		}
+	}
+
+	result, err := c.repo.Txn(txn)
+	if err != nil {
+		return repository.TxnResult{}, err
+	}
+
+	ops := txn.Success
+	if !result.Succeeded {
+		ops = txn.Failure
+	}
+	c.publishResults(ops, result.Results)
+
+	return result, nil
+}
+
+// Search gets the page described by opts of the configs in namespace whose
+// metadata matches expr. Use repository.AllNamespaces to search across
+// every namespace.
+func (c Config) Search(namespace string, expr query.Expr, opts repository.ListOptions) (repository.Page, error) {
+	page, err := c.repo.Search(namespace, expr, opts)
+	if err != nil || !opts.Interpolate {
+		return page, err
+	}
+
+	return c.interpolatePage(page)
+}
+
+// Reveal gets cfg with its secret metadata values decrypted.
+func (c Config) Reveal(cfg domain.Config) (domain.Config, error) {
+	metadata, err := c.secrets.Reveal(cfg.Metadata)
+	if err != nil {
+		return domain.Config{}, err
+	}
+	cfg.Metadata = metadata
+
+	return cfg, nil
+}
+
+// Revisions gets every revision of the config identified by name,
+// from the oldest to the current one.
+func (c Config) Revisions(namespace, name string) ([]domain.Config, error) {
+	return c.repo.Revisions(namespace, name)
+}
+
+// Revision gets the config identified by name as it was at revision.
+func (c Config) Revision(namespace, name string, revision int64) (domain.Config, error) {
+	return c.repo.Revision(namespace, name, revision)
+}
+
+// Watch waits for the config identified by name to change past sinceRevision,
+// until ctx is done.
+func (c Config) Watch(ctx context.Context, namespace, name string, sinceRevision int64) (domain.Config, error) {
+	return c.repo.Watch(ctx, namespace, name, sinceRevision)
+}
+
+// Rollback restores the metadata the config identified by name had at revision.
+// Instead of rewriting history, the restored metadata is stored as a new revision.
+// A non-zero current makes it conditional on the config still being at current,
+// just like CompareAndSwap.
+func (c Config) Rollback(namespace, name string, revision, current int64) error {
+	config, err := c.repo.Revision(namespace, name, revision)
+	if err != nil {
+		return err
+	}
+
+	updated, err := c.repo.Patch(namespace, name, current, func([]byte) ([]byte, error) {
+		return config.Metadata, c.validate(namespace, name, config.Metadata)
+	})
+	if err != nil {
+		return err
+	}
+	c.publishConfig(domain.EventUpdated, updated)
+
+	return nil
+}
+
+// ListNamespaces gets a list of namespaces.
+func (c Config) ListNamespaces() ([]domain.Namespace, error) {
+	return c.repo.ListNamespaces()
+}
+
+// CreateNamespace creates a new empty namespace identified by name.
+func (c Config) CreateNamespace(name string) error {
+	return c.repo.CreateNamespace(name)
+}
+
+// DeleteNamespace removes the namespace identified by name, which must be empty.
+func (c Config) DeleteNamespace(name string) error {
+	return c.repo.DeleteNamespace(name)
+}
+
+// validate validates metadata against the schemas attached to the config
+// identified by name, as long as schemas are being enforced.
+func (c Config) validate(namespace, name string, metadata []byte) error {
+	if c.schemas == nil {
+		return nil
+	}
+
+	return c.schemas.Validate(namespace, name, metadata)
+}
+
+// prepare gets metadata as it's stored, with its secret values sealed, as
+// long as it's valid against the schemas attached to the config identified by name.
+// current is the metadata being replaced, whose sealed secrets may be kept.
+func (c Config) prepare(namespace, name string, metadata, current []byte) ([]byte, error) {
+	sealed, err := c.secrets.Seal(metadata, current)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealed, c.validate(namespace, name, sealed)
+}
+
+// prepareOp gets op with the metadata it leaves a config with prepared
+// to be stored. Deletions are left as they are.
+func (c Config) prepareOp(op repository.Op) (repository.Op, error) {
+	if op.Type == repository.OpDelete {
+		return op, nil
+	}
+
+	metadata, err := c.prepare(op.Namespace, op.Name, op.Metadata, nil)
+	if err != nil {
+		return repository.Op{}, fmt.Errorf("config %q: %w", op.Name, err)
+	}
+	op.Metadata = metadata
+
+	return op, nil
+}
+
+// interpolatePage interpolates every config in page, looking up
+// each referenced config only once.
+func (c Config) interpolatePage(page repository.Page) (repository.Page, error) {
+	lookups := make(map[string]domain.ConfigLookup)
+	configs := make([]domain.Config, len(page.Configs))
+	for n, config := range page.Configs {
+		lookup, ok := lookups[config.Namespace]
+		if !ok {
+			lookup = c.lookup(config.Namespace)
+			lookups[config.Namespace] = lookup
+		}
+
+		var err error
+		if configs[n], err = domain.Interpolate(config, lookup); err != nil {
+			return repository.Page{}, err
+		}
+	}
+	page.Configs = configs
+
+	return page, nil
+}
+
+// lookup gets a domain.ConfigLookup finding the configs in namespace,
+// which only gets each of them once from the repository.
+func (c Config) lookup(namespace string) domain.ConfigLookup {
+	configs := make(map[string]domain.Config)
+	return func(name string) (domain.Config, bool) {
+		if config, ok := configs[name]; ok {
+			return config, true
+		}
+
+		config, err := c.repo.Get(namespace, name)
+		if err != nil {
+			return domain.Config{}, false
+		}
+		configs[name] = config
+
+		return config, true
+	}
+}
+
+// publishResults publishes the change made by every operation in ops
+// that went through, according to its result in results.
+func (c Config) publishResults(ops []repository.Op, results []repository.OpResult) {
+	for n, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		switch {
+		case ops[n].Type == repository.OpDelete:
+			c.publishConfig(domain.EventDeleted, result.Config)
+		case result.Created:
+			c.publishConfig(domain.EventCreated, result.Config)
+		default:
+			c.publishConfig(domain.EventUpdated, result.Config)
+		}
+	}
+}
+
+// publishConfig publishes a change of the given type made to cfg to the events
+// and webhooks, as long as changes are being published.
+func (c Config) publishConfig(eventType domain.EventType, cfg domain.Config) {
+	if !c.publishing() {
+		return
+	}
+
+	event := domain.Event{Type: eventType, Config: cfg, Time: time.Now().UTC()}
+	if c.events != nil {
+		event = c.events.Publish(eventType, cfg)
+	}
+	if c.webhooks != nil {
+		c.webhooks.Dispatch(event)
+	}
 }
 
-// Search gets a list of configs matching the query key/value pairs,
-// where key represents the nested property in metadata, and value is the
-// value that should match.
-func (c Config) Search(query map[string]string) ([]domain.Config, error) {
-	return c.repo.Search(query)
+// publishing tells if changes made to configs are being published.
+func (c Config) publishing() bool {
+	return c.events != nil || c.webhooks != nil
 }
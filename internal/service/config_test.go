@@ -1,13 +1,17 @@
 package service_test
 
 import (
+	"context"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository/mocks"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
 	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"net/http"
 	"testing"
 )
 
@@ -15,16 +19,17 @@ func TestConfig_List(t *testing.T) {
 	t.Run("listing is successful", func(t *testing.T) {
 		mockRepo := mocks.NewConfig(t)
 		stubs := test.GenerateConfigListStubs(t)
-		mockRepo.On("List").Return(stubs, nil)
+		opts := repository.ListOptions{Limit: 10, SortBy: repository.SortByRevision}
+		mockRepo.On("List", domain.DefaultNamespace, opts).Return(repository.Page{Configs: stubs, Total: len(stubs)}, nil)
 
 		svc := service.NewConfig(mockRepo)
 
-		configs, err := svc.List()
+		page, err := svc.List(domain.DefaultNamespace, opts)
 		require.NoError(t, err)
 
 		t.Run("it returns the expected number of configs", func(t *testing.T) {
 			wantLen := len(stubs)
-			gotLen := len(configs)
+			gotLen := len(page.Configs)
 
 			assert.Equal(t, wantLen, gotLen)
 		})
@@ -40,28 +45,49 @@ func TestConfig_Create(t *testing.T) {
 
 		mockRepo := mocks.NewConfig(t)
 		mockRepo.On("Save", mock.Anything).
-			Return(func(config domain.Config) error {
-				assert.Equal(t, toCreateConfig, config)
-				return nil
+			Return(func(config domain.Config) (domain.Config, error) {
+				assert.Equal(t, toCreateConfig.Name, config.Name)
+				assert.Equal(t, toCreateConfig.Metadata, config.Metadata)
+				return config, nil
 			})
 
 		svc := service.NewConfig(mockRepo)
 		require.NoError(t, svc.Create(toCreateConfig))
 	})
+
+	t.Run("config without a namespace is created in the default namespace", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Save", mock.MatchedBy(func(config domain.Config) bool {
+			return config.Namespace == domain.DefaultNamespace
+		})).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		require.NoError(t, svc.Create(domain.Config{Name: "config 1", Metadata: []byte(`{}`)}))
+	})
+
+	t.Run("config is created in its namespace", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Save", mock.MatchedBy(func(config domain.Config) bool {
+			return config.Namespace == "team-a"
+		})).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		require.NoError(t, svc.Create(domain.Config{Namespace: "team-a", Name: "config 1", Metadata: []byte(`{}`)}))
+	})
 }
 
 func TestConfig_Get(t *testing.T) {
 	t.Run("get is successful", func(t *testing.T) {
 		mockRepo := mocks.NewConfig(t)
 		wantName := test.ConfigName1
-		mockRepo.On("Get", mock.Anything).
+		mockRepo.On("Get", domain.DefaultNamespace, mock.Anything).
 			Return(domain.Config{
 				Name:     wantName,
 				Metadata: []byte(`{"foo": "bar"}`),
 			}, nil)
 
 		svc := service.NewConfig(mockRepo)
-		config, err := svc.Get(wantName)
+		config, err := svc.Get(domain.DefaultNamespace, wantName)
 		require.NoError(t, err)
 
 		t.Run("returned config match expected name", func(t *testing.T) {
@@ -73,12 +99,58 @@ func TestConfig_Get(t *testing.T) {
 func TestConfig_Update(t *testing.T) {
 	t.Run("update is successful", func(t *testing.T) {
 		mockRepo := mocks.NewConfig(t)
-		mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("Update", domain.DefaultNamespace, mock.Anything, mock.Anything).Return(domain.Config{}, nil)
 
 		wantName := test.ConfigName1
 
 		svc := service.NewConfig(mockRepo)
-		err := svc.Update(wantName, []byte(`{"foo": "bar"}`))
+		err := svc.Update(domain.DefaultNamespace, wantName, []byte(`{"foo": "bar"}`))
+		require.NoError(t, err)
+	})
+}
+
+func TestConfig_CompareAndSwap(t *testing.T) {
+	t.Run("compare and swap is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("CompareAndSwap", domain.DefaultNamespace, test.ConfigName1, int64(2), mock.Anything).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		err := svc.CompareAndSwap(domain.DefaultNamespace, test.ConfigName1, 2, []byte(`{"foo": "bar"}`))
+		require.NoError(t, err)
+	})
+}
+
+func TestConfig_Patch(t *testing.T) {
+	t.Run("patch is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Patch", domain.DefaultNamespace, test.ConfigName1, int64(0), mock.Anything).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		err := svc.Patch(domain.DefaultNamespace, test.ConfigName1, 0, func(metadata []byte) ([]byte, error) {
+			return metadata, nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestConfig_SetParents(t *testing.T) {
+	t.Run("setting parents is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("SetParents", domain.DefaultNamespace, test.ConfigName1, int64(0), []string{test.ConfigName2}).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		err := svc.SetParents(domain.DefaultNamespace, test.ConfigName1, 0, []string{test.ConfigName2})
+		require.NoError(t, err)
+	})
+}
+
+func TestConfig_CompareAndDelete(t *testing.T) {
+	t.Run("compare and delete is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("CompareAndDelete", domain.DefaultNamespace, test.ConfigName1, int64(2)).Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		err := svc.CompareAndDelete(domain.DefaultNamespace, test.ConfigName1, 2)
 		require.NoError(t, err)
 	})
 }
@@ -86,30 +158,379 @@ func TestConfig_Update(t *testing.T) {
 func TestConfig_Delete(t *testing.T) {
 	t.Run("delete is successful", func(t *testing.T) {
 		mockRepo := mocks.NewConfig(t)
-		mockRepo.On("Delete", mock.Anything).Return(nil)
+		mockRepo.On("Delete", domain.DefaultNamespace, mock.Anything).Return(domain.Config{}, nil)
 		svc := service.NewConfig(mockRepo)
 
-		err := svc.Delete(test.ConfigName1)
+		err := svc.Delete(domain.DefaultNamespace, test.ConfigName1)
 		require.NoError(t, err)
 	})
 }
 
+func TestConfig_Bulk(t *testing.T) {
+	t.Run("operations default to the default namespace", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Bulk", mock.MatchedBy(func(ops []repository.Op) bool {
+			return ops[0].Namespace == domain.DefaultNamespace && ops[1].Namespace == "team-a"
+		})).Return([]repository.OpResult{{}, {}})
+
+		svc := service.NewConfig(mockRepo)
+		svc.Bulk([]repository.Op{
+			{Type: repository.OpDelete, Name: test.ConfigName1},
+			{Type: repository.OpDelete, Namespace: "team-a", Name: test.ConfigName1},
+		})
+	})
+
+	t.Run("every change that went through is published", func(t *testing.T) {
+		created := domain.Config{Namespace: domain.DefaultNamespace, Name: "new", Revision: 1}
+		updated := domain.Config{Namespace: domain.DefaultNamespace, Name: test.ConfigName1, Revision: 2}
+		deleted := domain.Config{Namespace: domain.DefaultNamespace, Name: test.ConfigName2, Revision: 3}
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Bulk", mock.Anything).Return([]repository.OpResult{
+			{Config: created, Created: true},
+			{Config: updated},
+			{Err: repository.ErrConfigNotFound},
+			{Config: deleted},
+		})
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		svc.Bulk([]repository.Op{
+			{Type: repository.OpUpsert, Name: created.Name},
+			{Type: repository.OpUpsert, Name: updated.Name},
+			{Type: repository.OpUpdate, Name: "nope"},
+			{Type: repository.OpDelete, Name: deleted.Name},
+		})
+
+		for _, want := range []domain.Event{
+			{Type: domain.EventCreated, Config: created},
+			{Type: domain.EventUpdated, Config: updated},
+			{Type: domain.EventDeleted, Config: deleted},
+		} {
+			event := <-sub.C
+			assert.Equal(t, want.Type, event.Type)
+			assert.Equal(t, want.Config, event.Config)
+		}
+	})
+}
+
+func TestConfig_Txn(t *testing.T) {
+	t.Run("predicates and operations default to the default namespace", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Txn", mock.MatchedBy(func(txn repository.Txn) bool {
+			return txn.Compare[0].Namespace == domain.DefaultNamespace &&
+				txn.Success[0].Namespace == domain.DefaultNamespace &&
+				txn.Failure[0].Namespace == "team-a"
+		})).Return(repository.TxnResult{Succeeded: true, Results: []repository.OpResult{{}}}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		_, err := svc.Txn(repository.Txn{
+			Compare: []repository.Compare{{Target: repository.CompareExists, Name: test.ConfigName1}},
+			Success: []repository.Op{{Type: repository.OpDelete, Name: test.ConfigName1}},
+			Failure: []repository.Op{{Type: repository.OpDelete, Namespace: "team-a", Name: test.ConfigName1}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("the changes of the branch that ran are published", func(t *testing.T) {
+		created := domain.Config{Namespace: domain.DefaultNamespace, Name: "new", Revision: 1}
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Txn", mock.Anything).
+			Return(repository.TxnResult{Results: []repository.OpResult{{Config: created, Created: true}}}, nil)
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		result, err := svc.Txn(repository.Txn{
+			Success: []repository.Op{{Type: repository.OpDelete, Name: test.ConfigName1}},
+			Failure: []repository.Op{{Type: repository.OpCreate, Name: created.Name}},
+		})
+		require.NoError(t, err)
+		assert.False(t, result.Succeeded)
+
+		event := <-sub.C
+		assert.Equal(t, domain.EventCreated, event.Type)
+		assert.Equal(t, created, event.Config)
+	})
+
+	t.Run("nothing is published when the transaction fails", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Txn", mock.Anything).Return(repository.TxnResult{}, repository.ErrConfigNotFound)
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		_, err := svc.Txn(repository.Txn{Success: []repository.Op{{Type: repository.OpDelete, Name: "nope"}}})
+		assert.ErrorIs(t, err, repository.ErrConfigNotFound)
+		assert.Empty(t, sub.C)
+	})
+}
+
 func TestConfig_Search(t *testing.T) {
 	t.Run("search is successful", func(t *testing.T) {
 		mockRepo := mocks.NewConfig(t)
 		stubs := test.GenerateConfigListStubs(t)
-		mockRepo.On("Search", mock.Anything).Return(stubs, nil)
+		mockRepo.On("Search", domain.DefaultNamespace, mock.Anything, repository.ListOptions{}).
+			Return(repository.Page{Configs: stubs, Total: len(stubs)}, nil)
 
 		svc := service.NewConfig(mockRepo)
 
-		configs, err := svc.Search(map[string]string{"foo": "bar"})
+		expr := &query.Condition{Path: "foo", Op: query.Eq, Values: []string{"bar"}}
+		page, err := svc.Search(domain.DefaultNamespace, expr, repository.ListOptions{})
 		require.NoError(t, err)
 
 		t.Run("it returns the expected number of configs", func(t *testing.T) {
 			wantLen := 2
-			gotLen := len(configs)
+			gotLen := len(page.Configs)
 
 			assert.Equal(t, wantLen, gotLen)
 		})
 	})
 }
+
+func TestConfig_Interpolate(t *testing.T) {
+	infra := domain.Config{Namespace: domain.DefaultNamespace, Name: "shared-infra", Metadata: []byte(`{"db": {"host": "db.internal"}}`), Revision: 9}
+	app := domain.Config{Namespace: domain.DefaultNamespace, Name: "app", Metadata: []byte(`{"host": "${shared-infra:db.host}", "url": "pg://${shared-infra:db.host}"}`), Revision: 4}
+
+	t.Run("interpolation is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Get", domain.DefaultNamespace, "shared-infra").Return(infra, nil).Once()
+
+		svc := service.NewConfig(mockRepo)
+		config, err := svc.Interpolate(app)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"host": "db.internal", "url": "pg://db.internal"}`, string(config.Metadata))
+		assert.Equal(t, int64(9), config.Revision)
+	})
+
+	t.Run("pages are interpolated when asked to", func(t *testing.T) {
+		opts := repository.ListOptions{Interpolate: true}
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("List", domain.DefaultNamespace, opts).Return(repository.Page{Configs: []domain.Config{app, app}, Total: 2}, nil)
+		mockRepo.On("Get", domain.DefaultNamespace, "shared-infra").Return(infra, nil).Once()
+
+		svc := service.NewConfig(mockRepo)
+		page, err := svc.List(domain.DefaultNamespace, opts)
+		require.NoError(t, err)
+
+		require.Len(t, page.Configs, 2)
+		for _, config := range page.Configs {
+			assert.JSONEq(t, `{"host": "db.internal", "url": "pg://db.internal"}`, string(config.Metadata))
+		}
+	})
+
+	t.Run("missing references fail", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Get", domain.DefaultNamespace, "shared-infra").Return(domain.Config{}, repository.ErrConfigNotFound)
+
+		svc := service.NewConfig(mockRepo)
+		_, err := svc.Interpolate(app)
+		assert.ErrorIs(t, err, domain.ErrReferenceNotFound)
+	})
+}
+
+func TestConfig_Revisions(t *testing.T) {
+	t.Run("listing revisions is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		stubs := test.GenerateConfigListStubs(t)
+		mockRepo.On("Revisions", domain.DefaultNamespace, test.ConfigName1).Return(stubs, nil)
+
+		svc := service.NewConfig(mockRepo)
+
+		revisions, err := svc.Revisions(domain.DefaultNamespace, test.ConfigName1)
+		require.NoError(t, err)
+		assert.Len(t, revisions, len(stubs))
+	})
+}
+
+func TestConfig_Revision(t *testing.T) {
+	t.Run("get revision is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Revision", domain.DefaultNamespace, test.ConfigName1, int64(3)).
+			Return(domain.Config{Name: test.ConfigName1, Revision: 3}, nil)
+
+		svc := service.NewConfig(mockRepo)
+
+		config, err := svc.Revision(domain.DefaultNamespace, test.ConfigName1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), config.Revision)
+	})
+}
+
+func TestConfig_Rollback(t *testing.T) {
+	t.Run("rollback stores the old metadata as a new revision", func(t *testing.T) {
+		oldMetadata := []byte(`{"foo": "old"}`)
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Revision", domain.DefaultNamespace, test.ConfigName1, int64(3)).
+			Return(domain.Config{Name: test.ConfigName1, Metadata: oldMetadata, Revision: 3}, nil)
+		mockRepo.On("Patch", domain.DefaultNamespace, test.ConfigName1, int64(5), mock.Anything).
+			Run(func(args mock.Arguments) {
+				patched, err := args.Get(3).(repository.PatchFunc)([]byte(`{"foo": "new"}`))
+				require.NoError(t, err)
+				assert.Equal(t, oldMetadata, patched)
+			}).
+			Return(domain.Config{}, nil)
+
+		svc := service.NewConfig(mockRepo)
+		require.NoError(t, svc.Rollback(domain.DefaultNamespace, test.ConfigName1, 3, 5))
+	})
+
+	t.Run("config has changed since it was read", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Revision", domain.DefaultNamespace, test.ConfigName1, int64(3)).
+			Return(domain.Config{Name: test.ConfigName1, Metadata: []byte(`{"foo": "old"}`), Revision: 3}, nil)
+		mockRepo.On("Patch", domain.DefaultNamespace, test.ConfigName1, int64(5), mock.Anything).
+			Return(domain.Config{}, repository.ErrRevisionMismatch)
+
+		svc := service.NewConfig(mockRepo)
+		assert.ErrorIs(t, svc.Rollback(domain.DefaultNamespace, test.ConfigName1, 3, 5), repository.ErrRevisionMismatch)
+	})
+
+	t.Run("revision is not found", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Revision", domain.DefaultNamespace, test.ConfigName1, int64(3)).
+			Return(domain.Config{}, repository.ErrRevisionNotFound)
+
+		svc := service.NewConfig(mockRepo)
+		assert.ErrorIs(t, svc.Rollback(domain.DefaultNamespace, test.ConfigName1, 3, 0), repository.ErrRevisionNotFound)
+	})
+}
+
+func TestConfig_Watch(t *testing.T) {
+	t.Run("watch is successful", func(t *testing.T) {
+		ctx := context.Background()
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Watch", ctx, domain.DefaultNamespace, test.ConfigName1, int64(3)).
+			Return(domain.Config{Name: test.ConfigName1, Revision: 4}, nil)
+
+		svc := service.NewConfig(mockRepo)
+
+		config, err := svc.Watch(ctx, domain.DefaultNamespace, test.ConfigName1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), config.Revision)
+	})
+}
+
+func TestConfig_Events(t *testing.T) {
+	stored := domain.Config{Namespace: domain.DefaultNamespace, Name: test.ConfigName1, Metadata: []byte(`{"foo": "bar"}`), Revision: 7}
+
+	t.Run("creation is published", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Save", mock.Anything).Return(stored, nil)
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		require.NoError(t, svc.Create(domain.Config{Name: test.ConfigName1, Metadata: stored.Metadata}))
+
+		event := <-sub.C
+		assert.Equal(t, domain.EventCreated, event.Type)
+		assert.Equal(t, stored, event.Config)
+	})
+
+	t.Run("update is published", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Update", domain.DefaultNamespace, test.ConfigName1, mock.Anything).Return(stored, nil)
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		require.NoError(t, svc.Update(domain.DefaultNamespace, test.ConfigName1, stored.Metadata))
+
+		event := <-sub.C
+		assert.Equal(t, domain.EventUpdated, event.Type)
+		assert.Equal(t, stored, event.Config)
+	})
+
+	t.Run("deletion is published with the last known config", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Delete", domain.DefaultNamespace, test.ConfigName1).Return(stored, nil)
+
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		require.NoError(t, svc.Delete(domain.DefaultNamespace, test.ConfigName1))
+
+		event := <-sub.C
+		assert.Equal(t, domain.EventDeleted, event.Type)
+		assert.Equal(t, stored, event.Config)
+	})
+
+	t.Run("change is posted to webhooks", func(t *testing.T) {
+		rcv, server := newReceiver(t, http.StatusOK)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		_, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Update", domain.DefaultNamespace, test.ConfigName1, mock.Anything).Return(stored, nil)
+
+		svc := service.NewConfig(mockRepo, service.WithWebhooks(webhooks))
+		require.NoError(t, svc.Update(domain.DefaultNamespace, test.ConfigName1, stored.Metadata))
+
+		req := <-rcv.requests
+		assert.Equal(t, "updated", req.Header.Get(service.EventTypeHeader))
+	})
+
+	t.Run("failed change isn't published", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Update", domain.DefaultNamespace, test.ConfigName1, mock.Anything).
+			Return(domain.Config{}, repository.ErrConfigNotFound)
+
+		events := service.NewEvents(10)
+		svc := service.NewConfig(mockRepo, service.WithEvents(events))
+		require.ErrorIs(t, svc.Update(domain.DefaultNamespace, test.ConfigName1, stored.Metadata), repository.ErrConfigNotFound)
+
+		// nothing was published before, so the next event is the first one.
+		assert.Equal(t, int64(1), events.Publish(domain.EventUpdated, stored).ID)
+	})
+}
+
+func TestConfig_Namespaces(t *testing.T) {
+	t.Run("list is successful", func(t *testing.T) {
+		stubs := []domain.Namespace{{Name: domain.DefaultNamespace}, {Name: "team-a"}}
+
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("ListNamespaces").Return(stubs, nil)
+
+		svc := service.NewConfig(mockRepo)
+		namespaces, err := svc.ListNamespaces()
+		require.NoError(t, err)
+		assert.Equal(t, stubs, namespaces)
+	})
+
+	t.Run("creation is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("CreateNamespace", "team-a").Return(nil)
+
+		svc := service.NewConfig(mockRepo)
+		assert.NoError(t, svc.CreateNamespace("team-a"))
+	})
+
+	t.Run("deletion is successful", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("DeleteNamespace", "team-a").Return(nil)
+
+		svc := service.NewConfig(mockRepo)
+		assert.NoError(t, svc.DeleteNamespace("team-a"))
+	})
+}
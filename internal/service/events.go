@@ -0,0 +1,144 @@
+package service
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEventBufferSize is the number of past events kept by default
+	// for subscribers to resume from.
+	DefaultEventBufferSize = 1024
+	// subscriptionBufferSize is the number of events a subscriber can fall behind
+	// before it's dropped, so that slow subscribers never hold publishers up.
+	subscriptionBufferSize = 64
+)
+
+// NewEvents creates a new Events instance keeping the last size events,
+// or DefaultEventBufferSize events if size isn't positive.
+func NewEvents(size int) *Events {
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+
+	return &Events{
+		buffer:        make([]domain.Event, 0, size),
+		size:          size,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Events fans the changes made to configs out to its subscribers.
+//
+// It keeps a bounded buffer of the latest events, so that subscribers
+// can resume from the last event they've seen after reconnecting.
+type Events struct {
+	mu            sync.Mutex
+	buffer        []domain.Event
+	size          int
+	lastID        int64
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// Subscription receives the events published after it's been made.
+type Subscription struct {
+	// Backlog are the buffered events that were published after the
+	// event the subscription resumes from, from the oldest.
+	Backlog []domain.Event
+	// C receives every new event. It's closed when the subscription is
+	// dropped, either for falling behind or because the events are closed.
+	C <-chan domain.Event
+
+	c chan domain.Event
+}
+
+// Subscribe subscribes to the events published from now on. The buffered
+// events following the one identified by lastEventID are in the Backlog,
+// so a lastEventID of 0 means not resuming from anything.
+//
+// Subscriptions must be released with Unsubscribe once they're done.
+func (e *Events) Subscribe(lastEventID int64) *Subscription {
+	c := make(chan domain.Event, subscriptionBufferSize)
+	sub := &Subscription{C: c, c: c}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if lastEventID > 0 {
+		for _, event := range e.buffer {
+			if event.ID > lastEventID {
+				sub.Backlog = append(sub.Backlog, event)
+			}
+		}
+	}
+
+	if e.closed {
+		close(c)
+		return sub
+	}
+	e.subscriptions[sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe drops sub, so that it doesn't receive any other event.
+func (e *Events) Unsubscribe(sub *Subscription) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.drop(sub)
+}
+
+// Publish records a change of the given type made to cfg,
+// letting every subscriber know about it.
+func (e *Events) Publish(eventType domain.EventType, cfg domain.Config) domain.Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastID++
+	event := domain.Event{
+		ID:     e.lastID,
+		Type:   eventType,
+		Config: cfg,
+		Time:   time.Now().UTC(),
+	}
+
+	if len(e.buffer) == e.size {
+		e.buffer = append(e.buffer[:0], e.buffer[1:]...)
+	}
+	e.buffer = append(e.buffer, event)
+
+	for sub := range e.subscriptions {
+		select {
+		case sub.c <- event:
+		default:
+			// the subscriber is falling behind, so drop it and let it
+			// resume from the buffer instead of blocking the publisher.
+			e.drop(sub)
+		}
+	}
+
+	return event
+}
+
+// Close drops every subscription, and any made afterward.
+func (e *Events) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for sub := range e.subscriptions {
+		e.drop(sub)
+	}
+	e.closed = true
+}
+
+// drop closes sub if it's still subscribed. Callers must hold the lock.
+func (e *Events) drop(sub *Subscription) {
+	if _, ok := e.subscriptions[sub]; !ok {
+		return
+	}
+	delete(e.subscriptions, sub)
+	close(sub.c)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	config := domain.Config{Namespace: domain.DefaultNamespace, Name: test.ConfigName1}
+
+	t.Run("subscribers receive published events", func(t *testing.T) {
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		published := events.Publish(domain.EventCreated, config)
+
+		event := <-sub.C
+		assert.Equal(t, published, event)
+		assert.Equal(t, int64(1), event.ID)
+		assert.Equal(t, domain.EventCreated, event.Type)
+		assert.Empty(t, sub.Backlog)
+	})
+
+	t.Run("subscription resumes from the last event", func(t *testing.T) {
+		events := service.NewEvents(10)
+		for range 3 {
+			events.Publish(domain.EventUpdated, config)
+		}
+
+		sub := events.Subscribe(1)
+		defer events.Unsubscribe(sub)
+
+		require.Len(t, sub.Backlog, 2)
+		assert.Equal(t, int64(2), sub.Backlog[0].ID)
+		assert.Equal(t, int64(3), sub.Backlog[1].ID)
+	})
+
+	t.Run("only the latest events are buffered", func(t *testing.T) {
+		events := service.NewEvents(2)
+		for range 5 {
+			events.Publish(domain.EventUpdated, config)
+		}
+
+		sub := events.Subscribe(1)
+		defer events.Unsubscribe(sub)
+
+		require.Len(t, sub.Backlog, 2)
+		assert.Equal(t, int64(4), sub.Backlog[0].ID)
+	})
+
+	t.Run("subscribers falling behind are dropped", func(t *testing.T) {
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+		defer events.Unsubscribe(sub)
+
+		// publishing never blocks, no matter how far behind a subscriber is.
+		for range 100 {
+			events.Publish(domain.EventUpdated, config)
+		}
+
+		received := 0
+		for range sub.C {
+			received++
+		}
+		assert.Less(t, received, 100)
+	})
+
+	t.Run("closing drops every subscription", func(t *testing.T) {
+		events := service.NewEvents(10)
+		sub := events.Subscribe(0)
+
+		events.Close()
+
+		_, ok := <-sub.C
+		assert.False(t, ok)
+
+		t.Run("later subscriptions are dropped right away", func(t *testing.T) {
+			_, ok := <-events.Subscribe(0).C
+			assert.False(t, ok)
+		})
+	})
+}
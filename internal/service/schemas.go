@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Violator is a config whose metadata doesn't satisfy a schema attached to it.
+type Violator struct {
+	// Namespace is the name of the namespace holding the config.
+	Namespace string
+	// Name is the name of the config.
+	Name string
+	// Revision is the revision of the config violating the schema.
+	Revision int64
+	// Violations are the ways the metadata of the config violates the schema.
+	Violations []domain.Violation
+}
+
+// NewSchemas creates a new Schemas service instance, validating
+// the configs in configs against the schemas in repo, with their
+// secret values as the plaintext secrets gets out of them.
+func NewSchemas(repo repository.Schema, configs repository.Config, secrets *Secrets) *Schemas {
+	return &Schemas{
+		repo:     repo,
+		configs:  configs,
+		secrets:  secrets,
+		compiled: make(map[string]compiledSchema),
+	}
+}
+
+// Schemas manages the JSON Schemas attached to configs,
+// validating their metadata against them.
+type Schemas struct {
+	repo    repository.Schema
+	configs repository.Config
+	secrets *Secrets
+
+	mu       sync.Mutex
+	compiled map[string]compiledSchema
+}
+
+// compiledSchema is a schema compiled at the time it was last changed.
+type compiledSchema struct {
+	updatedAt time.Time
+	schema    *domain.JSONSchema
+}
+
+// List gets every schema, sorted by their name.
+func (s *Schemas) List() ([]domain.Schema, error) {
+	return s.repo.List()
+}
+
+// Get gets a schema identified by its name.
+func (s *Schemas) Get(name string) (domain.Schema, error) {
+	return s.repo.Get(name)
+}
+
+// Put creates schema, or replaces the one with the same name, telling if it's
+// created. The existing configs it's attached to are neither changed nor
+// rejected, so Violators reports the ones violating it.
+// If its definition or its pattern aren't valid, it returns domain.ErrInvalidSchema.
+func (s *Schemas) Put(schema domain.Schema) (domain.Schema, bool, error) {
+	compiled, err := domain.CompileSchema(schema.Definition)
+	if err != nil {
+		return domain.Schema{}, false, err
+	}
+	if _, err := path.Match(schema.Pattern, ""); err != nil {
+		return domain.Schema{}, false, fmt.Errorf("%w: pattern %q: %w", domain.ErrInvalidSchema, schema.Pattern, err)
+	}
+
+	now := time.Now().UTC()
+	schema.CreatedAt, schema.UpdatedAt = now, now
+	created, err := s.repo.Put(schema)
+	if err != nil {
+		return domain.Schema{}, false, err
+	}
+	if schema, err = s.repo.Get(schema.Name); err != nil {
+		return domain.Schema{}, false, err
+	}
+
+	s.mu.Lock()
+	s.compiled[schema.Name] = compiledSchema{updatedAt: schema.UpdatedAt, schema: compiled}
+	s.mu.Unlock()
+
+	return schema, created, nil
+}
+
+// Delete removes the schema identified by name.
+func (s *Schemas) Delete(name string) error {
+	if err := s.repo.Delete(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.compiled, name)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Violators re-validates the existing configs the schema identified by name
+// is attached to, reporting the ones violating it.
+func (s *Schemas) Violators(name string) ([]Violator, error) {
+	schema, err := s.repo.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.violators(schema, compiled)
+}
+
+// Validate validates metadata against every schema attached to the config
+// identified by name, returning domain.ErrSchemaViolation when it doesn't
+// satisfy any of them. Secret values are validated as their plaintext.
+func (s *Schemas) Validate(namespace, name string, metadata []byte) error {
+	schemas, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	metadata, err = s.secrets.Plain(metadata)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, schema := range schemas {
+		if !schema.Matches(namespace, name) {
+			continue
+		}
+
+		compiled, err := s.compile(schema)
+		if err != nil {
+			return err
+		}
+		if violations := compiled.Validate(metadata); len(violations) > 0 {
+			failures = append(failures, fmt.Sprintf("%q: %s", schema.Name, joinViolations(violations)))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%w %s", domain.ErrSchemaViolation, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// compile gets schema compiled, compiling it only once after every change.
+func (s *Schemas) compile(schema domain.Schema) (*domain.JSONSchema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.compiled[schema.Name]; ok && c.updatedAt.Equal(schema.UpdatedAt) {
+		return c.schema, nil
+	}
+
+	compiled, err := domain.CompileSchema(schema.Definition)
+	if err != nil {
+		return nil, err
+	}
+	s.compiled[schema.Name] = compiledSchema{updatedAt: schema.UpdatedAt, schema: compiled}
+
+	return compiled, nil
+}
+
+// violators validates every config schema is attached to against compiled,
+// reporting the ones violating it, sorted by name.
+func (s *Schemas) violators(schema domain.Schema, compiled *domain.JSONSchema) ([]Violator, error) {
+	page, err := s.configs.Search(repository.AllNamespaces, nil, repository.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	violators := make([]Violator, 0)
+	for _, config := range page.Configs {
+		if !schema.Matches(config.Namespace, config.Name) {
+			continue
+		}
+		metadata, err := s.secrets.Plain(config.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if violations := compiled.Validate(metadata); len(violations) > 0 {
+			violators = append(violators, Violator{
+				Namespace:  config.Namespace,
+				Name:       config.Name,
+				Revision:   config.Revision,
+				Violations: violations,
+			})
+		}
+	}
+
+	return violators, nil
+}
+
+// joinViolations joins the descriptions of violations into a single one.
+func joinViolations(violations []domain.Violation) string {
+	descriptions := make([]string, len(violations))
+	for n, violation := range violations {
+		descriptions[n] = violation.String()
+	}
+
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSchemas(t *testing.T) {
+	repo := repository.NewInMemoryConfig()
+	schemas := service.NewSchemas(repository.NewInMemorySchema(), repo, nil)
+	svc := service.NewConfig(repo, service.WithSchemas(schemas))
+
+	require.NoError(t, svc.Create(domain.Config{Name: "flags-web", Metadata: []byte(`{"enabeld": "true"}`)}))
+	require.NoError(t, svc.Create(domain.Config{Name: "flags-app", Metadata: []byte(`{"enabled": "true"}`)}))
+
+	schema, created, err := schemas.Put(domain.Schema{
+		Name:       "flags",
+		Pattern:    "flags-*",
+		Definition: []byte(`{"required": ["enabled"], "properties": {"enabled": {"enum": ["true", "false"]}}, "additionalProperties": false}`),
+	})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.False(t, schema.CreatedAt.IsZero())
+
+	t.Run("existing configs are re-validated", func(t *testing.T) {
+		violators, err := schemas.Violators("flags")
+		require.NoError(t, err)
+		require.Len(t, violators, 1)
+		assert.Equal(t, "flags-web", violators[0].Name)
+		assert.Equal(t, []domain.Violation{
+			{Path: "enabled", Message: "is required"},
+			{Path: "enabeld", Message: "isn't allowed"},
+		}, violators[0].Violations)
+	})
+
+	t.Run("changes are validated", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			change func() error
+		}{
+			{name: "create", change: func() error {
+				return svc.Create(domain.Config{Name: "flags-new", Metadata: []byte(`{"enabled": "yes"}`)})
+			}},
+			{name: "update", change: func() error {
+				return svc.Update(domain.DefaultNamespace, "flags-app", []byte(`{}`))
+			}},
+			{name: "patch", change: func() error {
+				return svc.Patch(domain.DefaultNamespace, "flags-app", 0, func([]byte) ([]byte, error) { return []byte(`{"enabled": "no"}`), nil })
+			}},
+			{name: "rollback", change: func() error {
+				return svc.Rollback(domain.DefaultNamespace, "flags-web", 1, 0)
+			}},
+			{name: "txn", change: func() error {
+				_, err := svc.Txn(repository.Txn{Success: []repository.Op{{Type: repository.OpUpsert, Name: "flags-app", Metadata: []byte(`{}`)}}})
+				return err
+			}},
+			{name: "import", change: func() error {
+				_, err := svc.Import([]domain.Config{{Name: "flags-imported", Metadata: []byte(`{}`)}}, service.ImportFail, true)
+				return err
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.ErrorIs(t, tt.change(), domain.ErrSchemaViolation)
+			})
+		}
+
+		t.Run("bulk", func(t *testing.T) {
+			results := svc.Bulk([]repository.Op{
+				{Type: repository.OpUpsert, Name: "flags-app", Metadata: []byte(`{"enabled": "nope"}`)},
+				{Type: repository.OpUpsert, Name: "flags-app", Metadata: []byte(`{"enabled": "false"}`)},
+				{Type: repository.OpDelete, Name: "flags-web"},
+			})
+			require.Len(t, results, 3)
+			assert.ErrorIs(t, results[0].Err, domain.ErrSchemaViolation)
+			assert.NoError(t, results[1].Err)
+			assert.NoError(t, results[2].Err)
+		})
+
+		t.Run("but not the ones to other configs", func(t *testing.T) {
+			assert.NoError(t, svc.Create(domain.Config{Name: "other", Metadata: []byte(`{"anything": "goes"}`)}))
+		})
+	})
+
+	t.Run("reports every schema violated", func(t *testing.T) {
+		_, _, err := schemas.Put(domain.Schema{Name: "app", Pattern: "flags-app", Definition: []byte(`{"required": ["owner"]}`)})
+		require.NoError(t, err)
+
+		err = svc.Update(domain.DefaultNamespace, "flags-app", []byte(`{"enabled": "maybe"}`))
+		assert.EqualError(t, err, `metadata violates schema "app": owner is required; "flags": enabled must be one of "true", "false"`)
+	})
+
+	t.Run("invalid schemas are rejected", func(t *testing.T) {
+		_, _, err := schemas.Put(domain.Schema{Name: "bad", Pattern: "*", Definition: []byte(`{"format": "email"}`)})
+		assert.ErrorIs(t, err, domain.ErrInvalidSchema)
+
+		_, _, err = schemas.Put(domain.Schema{Name: "bad", Pattern: "[", Definition: []byte(`{}`)})
+		assert.ErrorIs(t, err, domain.ErrInvalidSchema)
+
+		_, err = schemas.Get("bad")
+		assert.ErrorIs(t, err, repository.ErrSchemaNotFound)
+	})
+
+	t.Run("deleted schemas aren't enforced anymore", func(t *testing.T) {
+		require.NoError(t, schemas.Delete("app"))
+		require.NoError(t, schemas.Delete("flags"))
+		assert.NoError(t, svc.Update(domain.DefaultNamespace, "flags-app", []byte(`{}`)))
+	})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"slices"
+)
+
+// NewSecrets creates a new Secrets service instance, encrypting secrets
+// with key, which must be 16, 24 or 32 bytes long, for AES-128, AES-192
+// or AES-256 respectively.
+func NewSecrets(key []byte) (*Secrets, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+
+	return &Secrets{aead: aead}, nil
+}
+
+// Secrets encrypts the secret metadata values of configs at rest with AES-GCM,
+// as domain.SealSecrets marks them. A nil Secrets has no key, so it fails
+// with domain.ErrSecretsDisabled to seal or reveal any secret.
+type Secrets struct {
+	aead cipher.AEAD
+}
+
+// Seal gets metadata with its values marked as secret encrypted. Values
+// encrypted already are only kept when current, the metadata being replaced,
+// holds them, as patches leave them.
+func (s *Secrets) Seal(metadata, current []byte) ([]byte, error) {
+	kept := domain.Ciphertexts(current)
+	return domain.SealSecrets(metadata, s.encrypt, func(ciphertext string) bool {
+		return slices.Contains(kept, ciphertext)
+	})
+}
+
+// Reveal gets metadata with its secret values decrypted.
+func (s *Secrets) Reveal(metadata []byte) ([]byte, error) {
+	return domain.RevealSecrets(metadata, s.decrypt)
+}
+
+// Plain gets metadata with its secret values as bare plaintext,
+// whether they're sealed or not.
+func (s *Secrets) Plain(metadata []byte) ([]byte, error) {
+	return domain.PlainSecrets(metadata, s.decrypt)
+}
+
+// encrypt encrypts plaintext under a random nonce, which is prepended
+// to the ciphertext, encoded in base64.
+func (s *Secrets) encrypt(plaintext string) (string, error) {
+	if s == nil {
+		return "", domain.ErrSecretsDisabled
+	}
+
+	// a nonce can't ever be used twice with the same key,
+	// so a secret isn't encrypted without a random one.
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt decrypts ciphertext, as encrypt gets it.
+func (s *Secrets) decrypt(ciphertext string) (string, error) {
+	if s == nil {
+		return "", domain.ErrSecretsDisabled
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return "", errors.New("failed to decrypt secret: ciphertext is too short")
+	}
+
+	nonce, sealed := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var secretsKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestNewSecrets(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		_, err := service.NewSecrets(secretsKey[:size])
+		assert.NoError(t, err)
+	}
+
+	_, err := service.NewSecrets([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestSecrets(t *testing.T) {
+	secrets, err := service.NewSecrets(secretsKey)
+	require.NoError(t, err)
+
+	t.Run("secrets are encrypted", func(t *testing.T) {
+		sealed, err := secrets.Seal([]byte(`{"apiKey": "secret:abc123", "region": "eu"}`), nil)
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(sealed), "abc123")
+		apiKey := domain.Config{Metadata: sealed}.MetadataValue("apiKey")
+		assert.True(t, domain.IsSecret(apiKey))
+
+		revealed, err := secrets.Reveal(sealed)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"apiKey": "secret:abc123", "region": "eu"}`, string(revealed))
+	})
+
+	t.Run("every encryption is different", func(t *testing.T) {
+		a, err := secrets.Seal([]byte(`{"apiKey": "secret:abc123"}`), nil)
+		require.NoError(t, err)
+		b, err := secrets.Seal([]byte(`{"apiKey": "secret:abc123"}`), nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, string(a), string(b))
+	})
+
+	t.Run("secrets encrypted with another key can't be revealed", func(t *testing.T) {
+		sealed, err := secrets.Seal([]byte(`{"apiKey": "secret:abc123"}`), nil)
+		require.NoError(t, err)
+
+		other, err := service.NewSecrets([]byte("fedcba9876543210fedcba9876543210"))
+		require.NoError(t, err)
+
+		_, err = other.Reveal(sealed)
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered secrets can't be revealed", func(t *testing.T) {
+		_, err := secrets.Reveal([]byte(`{"apiKey": "encrypted:bm90IGEgY2lwaGVydGV4dA=="}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("without a key", func(t *testing.T) {
+		var disabled *service.Secrets
+
+		metadata, err := disabled.Seal([]byte(`{"region": "eu"}`), nil)
+		require.NoError(t, err)
+		assert.Equal(t, `{"region": "eu"}`, string(metadata))
+
+		_, err = disabled.Seal([]byte(`{"apiKey": "secret:abc123"}`), nil)
+		assert.ErrorIs(t, err, domain.ErrSecretsDisabled)
+	})
+}
+
+func TestConfig_Secrets(t *testing.T) {
+	secrets, err := service.NewSecrets(secretsKey)
+	require.NoError(t, err)
+
+	repo := repository.NewInMemoryConfig()
+	svc := service.NewConfig(repo, service.WithSecrets(secrets))
+
+	require.NoError(t, svc.Create(domain.Config{Name: "payments", Metadata: []byte(`{"apiKey": "secret:abc123"}`)}))
+
+	stored, err := repo.Get(domain.DefaultNamespace, "payments")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Metadata), "abc123")
+
+	t.Run("secrets are revealed", func(t *testing.T) {
+		revealed, err := svc.Reveal(stored)
+		require.NoError(t, err)
+		assert.Equal(t, "secret:abc123", revealed.MetadataValue("apiKey"))
+	})
+
+	t.Run("secrets are encrypted by every change", func(t *testing.T) {
+		require.NoError(t, svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "secret:def456"}`)))
+		results := svc.Bulk([]repository.Op{{Type: repository.OpCreate, Name: "checkout", Metadata: []byte(`{"apiKey": "secret:ghi789"}`)}})
+		require.NoError(t, results[0].Err)
+
+		for name, plaintext := range map[string]string{"payments": "def456", "checkout": "ghi789"} {
+			stored, err := repo.Get(domain.DefaultNamespace, name)
+			require.NoError(t, err)
+			assert.NotContains(t, string(stored.Metadata), plaintext)
+		}
+	})
+
+	t.Run("redacted secrets can't be written back", func(t *testing.T) {
+		err := svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "secret:******"}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+	})
+
+	t.Run("ciphertexts can't be written by clients", func(t *testing.T) {
+		err := svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "encrypted:bm90IGEgY2lwaGVydGV4dA=="}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+
+		results := svc.Bulk([]repository.Op{{Type: repository.OpUpsert, Name: "checkout", Metadata: []byte(`{"apiKey": "encrypted:bm90IGEgY2lwaGVydGV4dA=="}`)}})
+		assert.ErrorIs(t, results[0].Err, domain.ErrInvalidSecret)
+	})
+
+	t.Run("patches keep sealed secrets", func(t *testing.T) {
+		require.NoError(t, svc.Patch(domain.DefaultNamespace, "payments", 0, func(metadata []byte) ([]byte, error) {
+			return domain.MergePatch(metadata, []byte(`{"region": "eu"}`))
+		}))
+
+		patched, err := repo.Get(domain.DefaultNamespace, "payments")
+		require.NoError(t, err)
+		revealed, err := svc.Reveal(patched)
+		require.NoError(t, err)
+		assert.Equal(t, "secret:def456", revealed.MetadataValue("apiKey"))
+	})
+
+	t.Run("secrets are rejected without a key", func(t *testing.T) {
+		err := service.NewConfig(repo).Create(domain.Config{Name: "other", Metadata: []byte(`{"apiKey": "secret:abc123"}`)})
+		assert.ErrorIs(t, err, domain.ErrSecretsDisabled)
+	})
+}
+
+func TestConfig_SecretsSchemas(t *testing.T) {
+	secrets, err := service.NewSecrets(secretsKey)
+	require.NoError(t, err)
+
+	repo := repository.NewInMemoryConfig()
+	schemas := service.NewSchemas(repository.NewInMemorySchema(), repo, secrets)
+	svc := service.NewConfig(repo, service.WithSchemas(schemas), service.WithSecrets(secrets))
+
+	require.NoError(t, svc.Create(domain.Config{Name: "payments", Metadata: []byte(`{"apiKey": "secret:sk_abc123"}`)}))
+	require.NoError(t, svc.Create(domain.Config{Name: "checkout", Metadata: []byte(`{"apiKey": "secret:abc123"}`)}))
+
+	_, _, err = schemas.Put(domain.Schema{
+		Name:       "api-keys",
+		Pattern:    "*",
+		Definition: []byte(`{"properties": {"apiKey": {"type": "string", "pattern": "^sk_", "maxLength": 12}}}`),
+	})
+	require.NoError(t, err)
+
+	t.Run("secrets are validated as their plaintext", func(t *testing.T) {
+		assert.NoError(t, svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "secret:sk_def456"}`)))
+
+		err := svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "secret:def456"}`))
+		assert.ErrorIs(t, err, domain.ErrSchemaViolation)
+		assert.NotContains(t, err.Error(), "def456")
+
+		err = svc.Update(domain.DefaultNamespace, "payments", []byte(`{"apiKey": "secret:sk_0123456789"}`))
+		assert.ErrorIs(t, err, domain.ErrSchemaViolation)
+	})
+
+	t.Run("sealed secrets are validated as their plaintext", func(t *testing.T) {
+		assert.NoError(t, svc.Patch(domain.DefaultNamespace, "payments", 0, func(metadata []byte) ([]byte, error) {
+			return domain.MergePatch(metadata, []byte(`{"region": "eu"}`))
+		}))
+
+		violators, err := schemas.Violators("api-keys")
+		require.NoError(t, err)
+		require.Len(t, violators, 1)
+		assert.Equal(t, "checkout", violators[0].Name)
+	})
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"slices"
+)
+
+// ImportStrategy is what an import does with the configs that exist already.
+type ImportStrategy string
+
+const (
+	// ImportSkip leaves the existing configs as they are.
+	ImportSkip ImportStrategy = "skip"
+	// ImportOverwrite replaces the metadata of the existing configs.
+	ImportOverwrite ImportStrategy = "overwrite"
+	// ImportFail fails the whole import if any config exists already.
+	ImportFail ImportStrategy = "fail"
+)
+
+// ImportAction is what an import does with a config.
+type ImportAction string
+
+const (
+	// ImportCreated creates the config.
+	ImportCreated ImportAction = "create"
+	// ImportOverwritten replaces the metadata of the existing config.
+	ImportOverwritten ImportAction = "overwrite"
+	// ImportSkipped leaves the existing config as it is.
+	ImportSkipped ImportAction = "skip"
+	// ImportConflicted fails the import, since the config exists already.
+	ImportConflicted ImportAction = "conflict"
+)
+
+// Export gets every config across every namespace, sorted by namespace and name,
+// as they are at a single point in time.
+func (c Config) Export() ([]domain.Config, error) {
+	page, err := c.repo.Search(repository.AllNamespaces, nil, repository.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	configs := page.Configs
+	slices.SortFunc(configs, func(a, b domain.Config) int {
+		if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return configs, nil
+}
+
+// importAttempts is how many times an import is planned, when the configs
+// it's planned on keep changing before it's carried out.
+const importAttempts = 3
+
+// Import creates configs, settling on what to do with the ones that exist
+// already according to strategy, and reports the action taken on each of them.
+// Configs without a namespace are imported in the default namespace, and the
+// namespaces that don't exist are created along. Encrypted secrets are taken
+// as long as they were encrypted with the same key, as they're exported.
+// Either every config is imported or none is. With ImportFail, if any config
+// exists already, none is imported, and the error is ErrConfigExists.
+// A dryRun only reports the actions, without changing anything.
+func (c Config) Import(configs []domain.Config, strategy ImportStrategy, dryRun bool) ([]ImportAction, error) {
+	for n := range configs {
+		if configs[n].Namespace == "" {
+			configs[n].Namespace = domain.DefaultNamespace
+		}
+
+		// secrets are exported encrypted, so they're revealed to be sealed
+		// again, as long as they were encrypted with the same key.
+		metadata, err := c.secrets.Reveal(configs[n].Metadata)
+		if err != nil && !errors.Is(err, domain.ErrSecretsDisabled) {
+			err = fmt.Errorf("%w: %w", domain.ErrInvalidSecret, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", configs[n].Name, err)
+		}
+		configs[n].Metadata = metadata
+	}
+
+	actions, txn, err := c.planImport(configs, strategy)
+	if err != nil || dryRun || len(txn.Success) == 0 {
+		return actions, err
+	}
+
+	created, err := c.createNamespaces(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the plan holds as long as the configs it's based on are still there,
+	// or still missing, which the transaction checks along. Otherwise, it's
+	// planned again against the configs as they are by then.
+	for attempt := 1; ; attempt++ {
+		result, err := c.repo.Txn(txn)
+		if err != nil {
+			c.deleteNamespaces(created)
+			return nil, err
+		}
+		if result.Succeeded {
+			c.publishResults(txn.Success, result.Results)
+			return importedActions(configs, actions, txn.Success, result.Results), nil
+		}
+		if attempt == importAttempts {
+			c.deleteNamespaces(created)
+			return nil, fmt.Errorf("configs kept changing while importing: %w", repository.ErrRevisionMismatch)
+		}
+
+		if actions, txn, err = c.planImport(configs, strategy); err != nil || len(txn.Success) == 0 {
+			c.deleteNamespaces(created)
+			return actions, err
+		}
+	}
+}
+
+// planImport settles on the action to take on each of configs according to
+// strategy, getting the transaction carrying them out, which only goes
+// through as long as none of the configs it doesn't overwrite has been
+// created or deleted in the meantime.
+func (c Config) planImport(configs []domain.Config, strategy ImportStrategy) ([]ImportAction, repository.Txn, error) {
+	actions := make([]ImportAction, len(configs))
+	var txn repository.Txn
+	conflicts := 0
+
+	for n, cfg := range configs {
+		_, err := c.repo.Get(cfg.Namespace, cfg.Name)
+		exists := err == nil
+		if err != nil && !errors.Is(err, repository.ErrConfigNotFound) {
+			return nil, repository.Txn{}, err
+		}
+
+		op := repository.Op{Type: repository.OpCreate, Namespace: cfg.Namespace, Name: cfg.Name, Metadata: cfg.Metadata, Parents: cfg.Parents}
+		switch {
+		case strategy == ImportOverwrite:
+			// upserts hold whether the config exists or not,
+			// so the action is only known for sure once it's run.
+			actions[n] = ImportCreated
+			if exists {
+				actions[n] = ImportOverwritten
+			}
+			op.Type = repository.OpUpsert
+		case !exists:
+			actions[n] = ImportCreated
+		case strategy == ImportSkip:
+			actions[n] = ImportSkipped
+		default:
+			actions[n] = ImportConflicted
+			conflicts++
+		}
+
+		if strategy != ImportOverwrite {
+			txn.Compare = append(txn.Compare, repository.Compare{Target: repository.CompareExists, Namespace: cfg.Namespace, Name: cfg.Name, Exists: exists})
+		}
+		if actions[n] == ImportSkipped {
+			continue
+		}
+		if op, err = c.prepareOp(op); err != nil {
+			return nil, repository.Txn{}, err
+		}
+		txn.Success = append(txn.Success, op)
+	}
+
+	if conflicts > 0 {
+		return actions, repository.Txn{}, fmt.Errorf("%d of the configs: %w", conflicts, repository.ErrConfigExists)
+	}
+	txn.Success = parentsFirst(txn.Success)
+
+	return actions, txn, nil
+}
+
+// importedActions gets actions with the ones of the configs that were
+// upserted by ops settled according to results.
+func importedActions(configs []domain.Config, actions []ImportAction, ops []repository.Op, results []repository.OpResult) []ImportAction {
+	type key struct{ namespace, name string }
+	indexes := make(map[key]int, len(configs))
+	for n, cfg := range configs {
+		indexes[key{cfg.Namespace, cfg.Name}] = n
+	}
+
+	for n, op := range ops {
+		if op.Type != repository.OpUpsert {
+			continue
+		}
+		actions[indexes[key{op.Namespace, op.Name}]] = ImportOverwritten
+		if results[n].Created {
+			actions[indexes[key{op.Namespace, op.Name}]] = ImportCreated
+		}
+	}
+
+	return actions
+}
+
+// createNamespaces creates the namespaces of configs that don't exist yet,
+// getting the ones it created.
+func (c Config) createNamespaces(configs []domain.Config) ([]string, error) {
+	namespaces, err := c.repo.ListNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		existing[ns.Name] = true
+	}
+
+	var created []string
+	for _, cfg := range configs {
+		if existing[cfg.Namespace] {
+			continue
+		}
+		err := c.repo.CreateNamespace(cfg.Namespace)
+		if err != nil && !errors.Is(err, repository.ErrNamespaceExists) {
+			c.deleteNamespaces(created)
+			return nil, err
+		}
+		if err == nil {
+			created = append(created, cfg.Namespace)
+		}
+		existing[cfg.Namespace] = true
+	}
+
+	return created, nil
+}
+
+// deleteNamespaces deletes the namespaces an import that didn't go through
+// created. The ones that aren't empty anymore are left in place, since
+// something else has been created in them meanwhile.
+func (c Config) deleteNamespaces(names []string) {
+	for _, name := range names {
+		c.repo.DeleteNamespace(name)
+	}
+}
+
+// parentsFirst orders ops so that the configs inherited from by other configs
+// in ops come before them, keeping the order of ops otherwise.
+func parentsFirst(ops []repository.Op) []repository.Op {
+	type key struct{ namespace, name string }
+	byKey := make(map[key]int, len(ops))
+	for n, op := range ops {
+		byKey[key{op.Namespace, op.Name}] = n
+	}
+
+	ordered := make([]repository.Op, 0, len(ops))
+	visited := make([]bool, len(ops))
+	var visit func(n int)
+	visit = func(n int) {
+		// cycles are left for the repository to reject,
+		// in whatever order they're met.
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, parent := range ops[n].Parents {
+			if p, ok := byKey[key{ops[n].Namespace, parent}]; ok {
+				visit(p)
+			}
+		}
+		ordered = append(ordered, ops[n])
+	}
+	for n := range ops {
+		visit(n)
+	}
+
+	return ordered
+}
@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository/mocks"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfig_Export(t *testing.T) {
+	mockRepo := mocks.NewConfig(t)
+	mockRepo.On("Search", repository.AllNamespaces, nil, repository.ListOptions{}).
+		Return(repository.Page{Configs: []domain.Config{
+			{Namespace: "team-b", Name: "a"},
+			{Namespace: "team-a", Name: "b"},
+			{Namespace: "team-a", Name: "a"},
+		}}, nil)
+
+	configs, err := service.NewConfig(mockRepo).Export()
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Config{
+		{Namespace: "team-a", Name: "a"},
+		{Namespace: "team-a", Name: "b"},
+		{Namespace: "team-b", Name: "a"},
+	}, configs)
+}
+
+func TestConfig_Import(t *testing.T) {
+	existing := domain.Config{Namespace: domain.DefaultNamespace, Name: "existing", Metadata: []byte(`{"foo":"bar"}`)}
+
+	imported := func() []domain.Config {
+		return []domain.Config{
+			{Name: "new", Metadata: []byte(`{"foo":"new"}`)},
+			{Namespace: "team-a", Name: "new", Metadata: []byte(`{"foo":"new"}`)},
+			{Name: existing.Name, Metadata: []byte(`{"foo":"imported"}`)},
+		}
+	}
+
+	// succeeded gets the result of txn as if it went through, where
+	// only the configs named "new" are created by upserts.
+	succeeded := func(txn repository.Txn) (repository.TxnResult, error) {
+		result := repository.TxnResult{Succeeded: true}
+		for _, op := range txn.Success {
+			result.Results = append(result.Results, repository.OpResult{Created: op.Name == "new"})
+		}
+		return result, nil
+	}
+
+	newRepo := func(t *testing.T) *mocks.Config {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Get", domain.DefaultNamespace, existing.Name).Return(existing, nil)
+		mockRepo.On("Get", mock.Anything, "new").Return(domain.Config{}, repository.ErrConfigNotFound)
+		return mockRepo
+	}
+
+	tests := []struct {
+		name        string
+		strategy    service.ImportStrategy
+		wantActions []service.ImportAction
+		wantOps     []repository.OpType
+	}{
+		{
+			name:        "skip",
+			strategy:    service.ImportSkip,
+			wantActions: []service.ImportAction{service.ImportCreated, service.ImportCreated, service.ImportSkipped},
+			wantOps:     []repository.OpType{repository.OpCreate, repository.OpCreate},
+		},
+		{
+			name:        "overwrite",
+			strategy:    service.ImportOverwrite,
+			wantActions: []service.ImportAction{service.ImportCreated, service.ImportCreated, service.ImportOverwritten},
+			wantOps:     []repository.OpType{repository.OpUpsert, repository.OpUpsert, repository.OpUpsert},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := newRepo(t)
+			mockRepo.On("ListNamespaces").Return([]domain.Namespace{{Name: domain.DefaultNamespace}}, nil)
+			mockRepo.On("CreateNamespace", "team-a").Return(nil).Once()
+			mockRepo.On("Txn", mock.MatchedBy(func(txn repository.Txn) bool {
+				if len(txn.Success) != len(tt.wantOps) {
+					return false
+				}
+				for n, op := range txn.Success {
+					if op.Type != tt.wantOps[n] {
+						return false
+					}
+				}
+				return true
+			})).Return(succeeded, nil)
+
+			actions, err := service.NewConfig(mockRepo).Import(imported(), tt.strategy, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActions, actions)
+		})
+	}
+
+	t.Run("import is planned again when configs change meanwhile", func(t *testing.T) {
+		mockRepo := mocks.NewConfig(t)
+		mockRepo.On("Get", domain.DefaultNamespace, "new").Return(domain.Config{}, repository.ErrConfigNotFound).Once()
+		mockRepo.On("Get", domain.DefaultNamespace, "new").Return(existing, nil).Once()
+		mockRepo.On("ListNamespaces").Return([]domain.Namespace{{Name: domain.DefaultNamespace}}, nil)
+		mockRepo.On("Txn", mock.MatchedBy(func(txn repository.Txn) bool {
+			return len(txn.Success) == 1 && !txn.Compare[0].Exists
+		})).Return(repository.TxnResult{Succeeded: false}, nil).Once()
+
+		actions, err := service.NewConfig(mockRepo).Import([]domain.Config{{Name: "new", Metadata: []byte(`{}`)}}, service.ImportSkip, false)
+		require.NoError(t, err)
+		assert.Equal(t, []service.ImportAction{service.ImportSkipped}, actions)
+	})
+
+	t.Run("created namespaces are deleted when the import fails", func(t *testing.T) {
+		mockRepo := newRepo(t)
+		mockRepo.On("ListNamespaces").Return([]domain.Namespace{{Name: domain.DefaultNamespace}}, nil)
+		mockRepo.On("CreateNamespace", "team-a").Return(nil).Once()
+		mockRepo.On("Txn", mock.Anything).Return(repository.TxnResult{}, repository.ErrConfigHasChildren)
+		mockRepo.On("DeleteNamespace", "team-a").Return(nil).Once()
+
+		_, err := service.NewConfig(mockRepo).Import(imported(), service.ImportOverwrite, false)
+		assert.ErrorIs(t, err, repository.ErrConfigHasChildren)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		actions, err := service.NewConfig(newRepo(t)).Import(imported(), service.ImportFail, false)
+		assert.ErrorIs(t, err, repository.ErrConfigExists)
+		assert.Equal(t, []service.ImportAction{service.ImportCreated, service.ImportCreated, service.ImportConflicted}, actions)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		actions, err := service.NewConfig(newRepo(t)).Import(imported(), service.ImportOverwrite, true)
+		require.NoError(t, err)
+		assert.Equal(t, []service.ImportAction{service.ImportCreated, service.ImportCreated, service.ImportOverwritten}, actions)
+	})
+
+	t.Run("parents come first", func(t *testing.T) {
+		// the actions are still reported in the order the configs are imported in.
+		children := []domain.Config{
+			{Name: "de", Metadata: []byte(`{}`), Parents: []string{"eu"}},
+			{Name: "eu", Metadata: []byte(`{}`), Parents: []string{"base"}},
+			{Name: "base", Metadata: []byte(`{}`)},
+		}
+
+		repo := repository.NewInMemoryConfig()
+		actions, err := service.NewConfig(repo).Import(children, service.ImportFail, false)
+		require.NoError(t, err)
+		assert.Equal(t, []service.ImportAction{service.ImportCreated, service.ImportCreated, service.ImportCreated}, actions)
+
+		config, err := repo.Resolve(domain.DefaultNamespace, "de")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eu"}, config.Parents)
+	})
+}
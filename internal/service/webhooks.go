@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/query"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// headers the events are posted to webhooks with.
+const (
+	// SignatureHeader holds the HMAC-SHA256 of the body keyed with the secret
+	// of the webhook, hex encoded and prefixed with "sha256=".
+	SignatureHeader = "X-Signature-256"
+	// EventTypeHeader holds the type of the event.
+	EventTypeHeader = "X-Event-Type"
+	// DeliveryIDHeader identifies the delivery, which stays
+	// the same across the attempts to post the event.
+	DeliveryIDHeader = "X-Delivery-ID"
+)
+
+const (
+	// DefaultDeliveryAttempts is the number of times an event is posted by default
+	// before the delivery is given up on.
+	DefaultDeliveryAttempts = 5
+	// DefaultDeliveryBackoff is how long to wait by default before retrying
+	// a delivery for the first time. It's doubled on every retry.
+	DefaultDeliveryBackoff = time.Second
+	// deliveryTimeout is how long a receiver has to respond to an attempt.
+	deliveryTimeout = 10 * time.Second
+)
+
+// WebhooksOption customizes a Webhooks service instance.
+type WebhooksOption func(*Webhooks)
+
+// WithHTTPClient posts the events with client.
+func WithHTTPClient(client *http.Client) WebhooksOption {
+	return func(w *Webhooks) {
+		w.client = client
+	}
+}
+
+// WithRetries gives up on a delivery after attempts, waiting backoff before
+// retrying for the first time, and twice as long on every retry after that.
+func WithRetries(attempts int, backoff time.Duration) WebhooksOption {
+	return func(w *Webhooks) {
+		w.attempts = attempts
+		w.backoff = backoff
+	}
+}
+
+// NewWebhooks creates a new Webhooks service instance.
+func NewWebhooks(repo repository.Webhook, opts ...WebhooksOption) *Webhooks {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Webhooks{
+		repo:     repo,
+		client:   &http.Client{Timeout: deliveryTimeout},
+		attempts: DefaultDeliveryAttempts,
+		backoff:  DefaultDeliveryBackoff,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Webhooks serves the webhook resources, and posts
+// the changes made to configs to the matching webhooks.
+type Webhooks struct {
+	repo     repository.Webhook
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+
+	// ctx is cancelled when closing, so that pending deliveries give up.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu guards closed, so that no delivery starts once closing.
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// payload is the body events are posted to webhooks with.
+type payload struct {
+	ID     int64            `json:"id"`
+	Type   domain.EventType `json:"type"`
+	Config struct {
+		Namespace string          `json:"namespace"`
+		Name      string          `json:"name"`
+		Metadata  json.RawMessage `json:"metadata"`
+		Revision  int64           `json:"revision"`
+		UpdatedAt time.Time       `json:"updatedAt"`
+	} `json:"config"`
+	Time time.Time `json:"time"`
+}
+
+// newPayload gets the payload event is posted with.
+func newPayload(event domain.Event) payload {
+	p := payload{ID: event.ID, Type: event.Type, Time: event.Time}
+	p.Config.Namespace = event.Config.Namespace
+	p.Config.Name = event.Config.Name
+	// secret values are redacted, and the metadata is left out when they can't be told apart.
+	if metadata, err := domain.RedactSecrets(event.Config.Metadata); err == nil {
+		p.Config.Metadata = metadata
+	}
+	p.Config.Revision = event.Config.Revision
+	p.Config.UpdatedAt = event.Config.UpdatedAt
+
+	return p
+}
+
+// List gets every webhook.
+func (w *Webhooks) List() ([]domain.Webhook, error) {
+	return w.repo.List()
+}
+
+// Create creates a new webhook according to webhook, returning it
+// along with the ID and creation time it's been given.
+func (w *Webhooks) Create(webhook domain.Webhook) (domain.Webhook, error) {
+	id, err := newID()
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.ID = id
+	webhook.CreatedAt = time.Now().UTC()
+
+	if err := w.repo.Save(webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// Get gets a webhook identified by its ID.
+func (w *Webhooks) Get(id string) (domain.Webhook, error) {
+	return w.repo.Get(id)
+}
+
+// Update replaces the URL, filters and secret of the webhook identified by the ID of webhook.
+func (w *Webhooks) Update(webhook domain.Webhook) (domain.Webhook, error) {
+	current, err := w.repo.Get(webhook.ID)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.CreatedAt = current.CreatedAt
+
+	if err := w.repo.Update(webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// Delete removes the webhook identified by its ID.
+// Its pending deliveries are given up on.
+func (w *Webhooks) Delete(id string) error {
+	return w.repo.Delete(id)
+}
+
+// Deliveries gets the latest deliveries of the webhook identified by its ID.
+func (w *Webhooks) Deliveries(id string) ([]domain.Delivery, error) {
+	return w.repo.Deliveries(id)
+}
+
+// Dispatch posts event to every webhook it matches. It never blocks,
+// since the deliveries are made in the background.
+func (w *Webhooks) Dispatch(event domain.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	webhooks, err := w.repo.List()
+	if err != nil {
+		log.Printf("Failed to list the webhooks to dispatch event %d to: %v", event.ID, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !matchWebhook(webhook, event) {
+			continue
+		}
+
+		id, err := newID()
+		if err != nil {
+			log.Printf("Failed to deliver event %d to webhook %s: %v", event.ID, webhook.ID, err)
+			continue
+		}
+
+		now := time.Now().UTC()
+		delivery := domain.Delivery{
+			ID:        id,
+			WebhookID: webhook.ID,
+			Event:     event,
+			Status:    domain.DeliveryPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := w.repo.SaveDelivery(delivery); err != nil {
+			continue
+		}
+
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.deliver(webhook, delivery)
+		}()
+	}
+}
+
+// Close gives up on every pending delivery, waiting for them to stop.
+// Events dispatched afterward aren't delivered.
+func (w *Webhooks) Close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+}
+
+// deliver posts the event in delivery to webhook, retrying with exponential
+// backoff until it's accepted or it runs out of attempts.
+func (w *Webhooks) deliver(webhook domain.Webhook, delivery domain.Delivery) {
+	body, err := json.Marshal(newPayload(delivery.Event))
+	if err != nil {
+		delivery.Status, delivery.Error = domain.DeliveryFailed, err.Error()
+		w.repo.SaveDelivery(delivery)
+		return
+	}
+
+	backoff := w.backoff
+	for {
+		delivery.Attempts++
+		delivery.ResponseStatus, err = w.post(webhook, delivery, body)
+		delivery.UpdatedAt = time.Now().UTC()
+
+		switch {
+		case err == nil:
+			delivery.Status, delivery.Error = domain.DeliverySucceeded, ""
+		case delivery.Attempts >= w.attempts:
+			delivery.Status, delivery.Error = domain.DeliveryFailed, err.Error()
+		default:
+			delivery.Error = err.Error()
+		}
+
+		// stop once the delivery is settled, or once the webhook
+		// is gone and there's no one to deliver the event to anymore.
+		if err := w.repo.SaveDelivery(delivery); err != nil || delivery.Status != domain.DeliveryPending {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-w.ctx.Done():
+			delivery.Status, delivery.Error = domain.DeliveryFailed, "shut down before the event could be delivered"
+			w.repo.SaveDelivery(delivery)
+			return
+		}
+	}
+}
+
+// post makes an attempt to post body to webhook, returning the status of the response.
+// Only 2xx responses are taken as the event being accepted.
+func (w *Webhooks) post(webhook domain.Webhook, delivery domain.Delivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(EventTypeHeader, string(delivery.Event.Type))
+	req.Header.Set(DeliveryIDHeader, delivery.ID)
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign computes the signature of body with secret, as found in the SignatureHeader,
+// so that receivers can check that events come from this service.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// matchWebhook tells if event passes the filters of webhook.
+func matchWebhook(webhook domain.Webhook, event domain.Event) bool {
+	if webhook.Namespace != "" && webhook.Namespace != event.Config.Namespace {
+		return false
+	}
+	if !strings.HasPrefix(event.Config.Name, webhook.Prefix) {
+		return false
+	}
+	if webhook.Query == "" {
+		return true
+	}
+
+	// queries are validated when webhooks are created,
+	// so one that doesn't parse doesn't match anything.
+	expr, err := query.Parse(webhook.Query)
+	if err != nil {
+		return false
+	}
+
+	return query.Match(expr, domain.WithoutSecrets(event.Config.Metadata))
+}
+
+// newID generates a random ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating ID: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,259 @@
+package service_test
+
+import (
+	"encoding/json"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/domain"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/repository"
+	"github.com/hellofreshdevtests/HFtest-platform-anlsergio/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook receiver responding with the given statuses in turn,
+// and with the last one from then on.
+type receiver struct {
+	statuses []int
+	calls    atomic.Int32
+	requests chan *http.Request
+	bodies   chan []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	rcv := &receiver{
+		statuses: statuses,
+		requests: make(chan *http.Request, 10),
+		bodies:   make(chan []byte, 10),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(rcv.calls.Add(1)) - 1
+		body, _ := io.ReadAll(r.Body)
+		rcv.requests <- r
+		rcv.bodies <- body
+
+		w.WriteHeader(rcv.statuses[min(call, len(rcv.statuses)-1)])
+	}))
+	t.Cleanup(server.Close)
+
+	return rcv, server
+}
+
+// waitForDelivery waits for the only delivery of the webhook identified by id to settle.
+func waitForDelivery(t *testing.T, webhooks *service.Webhooks, id string) domain.Delivery {
+	t.Helper()
+
+	var delivery domain.Delivery
+	require.Eventually(t, func() bool {
+		deliveries, err := webhooks.Deliveries(id)
+		if err != nil || len(deliveries) != 1 {
+			return false
+		}
+		delivery = deliveries[0]
+		return delivery.Status != domain.DeliveryPending
+	}, time.Second, time.Millisecond)
+
+	return delivery
+}
+
+func TestWebhooks(t *testing.T) {
+	event := domain.Event{
+		ID:   1,
+		Type: domain.EventUpdated,
+		Config: domain.Config{
+			Namespace: domain.DefaultNamespace,
+			Name:      "web-1",
+			Metadata:  []byte(`{"region":"eu"}`),
+			Revision:  3,
+		},
+	}
+
+	t.Run("event is posted signed", func(t *testing.T) {
+		rcv, server := newReceiver(t, http.StatusOK)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		webhook, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		webhooks.Dispatch(event)
+
+		delivery := waitForDelivery(t, webhooks, webhook.ID)
+		assert.Equal(t, domain.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+
+		req, body := <-rcv.requests, <-rcv.bodies
+		assert.Equal(t, service.Sign("s3cr3t", body), req.Header.Get(service.SignatureHeader))
+		assert.Equal(t, "updated", req.Header.Get(service.EventTypeHeader))
+		assert.Equal(t, delivery.ID, req.Header.Get(service.DeliveryIDHeader))
+
+		var payload struct {
+			Type   string `json:"type"`
+			Config struct {
+				Name     string         `json:"name"`
+				Metadata map[string]any `json:"metadata"`
+			} `json:"config"`
+		}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "updated", payload.Type)
+		assert.Equal(t, "web-1", payload.Config.Name)
+		assert.Equal(t, map[string]any{"region": "eu"}, payload.Config.Metadata)
+	})
+
+	t.Run("secrets aren't posted", func(t *testing.T) {
+		rcv, server := newReceiver(t, http.StatusOK)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		_, err := webhooks.Create(domain.Webhook{URL: server.URL, Query: `apiKey missing`})
+		require.NoError(t, err)
+
+		secretEvent := event
+		secretEvent.Config.Metadata = []byte(`{"apiKey":"encrypted:c2VjcmV0"}`)
+		webhooks.Dispatch(secretEvent)
+
+		body := <-rcv.bodies
+		assert.NotContains(t, string(body), "c2VjcmV0")
+		assert.Contains(t, string(body), domain.RedactedSecret)
+	})
+
+	t.Run("failed delivery is retried", func(t *testing.T) {
+		rcv, server := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook(), service.WithRetries(5, time.Millisecond))
+		defer webhooks.Close()
+
+		webhook, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		webhooks.Dispatch(event)
+
+		delivery := waitForDelivery(t, webhooks, webhook.ID)
+		assert.Equal(t, domain.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Equal(t, int32(3), rcv.calls.Load())
+	})
+
+	t.Run("delivery is given up on after the last attempt", func(t *testing.T) {
+		rcv, server := newReceiver(t, http.StatusInternalServerError)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook(), service.WithRetries(3, time.Millisecond))
+		defer webhooks.Close()
+
+		webhook, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		webhooks.Dispatch(event)
+
+		delivery := waitForDelivery(t, webhooks, webhook.ID)
+		assert.Equal(t, domain.DeliveryFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+		assert.NotEmpty(t, delivery.Error)
+		assert.Equal(t, int32(3), rcv.calls.Load())
+	})
+
+	t.Run("events are filtered", func(t *testing.T) {
+		_, server := newReceiver(t, http.StatusOK)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		tests := []struct {
+			name    string
+			webhook domain.Webhook
+			want    bool
+		}{
+			{name: "no filters", webhook: domain.Webhook{}, want: true},
+			{name: "matching prefix", webhook: domain.Webhook{Prefix: "web-"}, want: true},
+			{name: "other prefix", webhook: domain.Webhook{Prefix: "api-"}, want: false},
+			{name: "other namespace", webhook: domain.Webhook{Namespace: "team-a"}, want: false},
+			{name: "matching query", webhook: domain.Webhook{Query: `region in (eu, us)`}, want: true},
+			{name: "other query", webhook: domain.Webhook{Query: `region = us`}, want: false},
+		}
+
+		ids := make(map[string]string)
+		for _, tt := range tests {
+			tt.webhook.URL, tt.webhook.Secret = server.URL, "s3cr3t"
+			webhook, err := webhooks.Create(tt.webhook)
+			require.NoError(t, err)
+			ids[tt.name] = webhook.ID
+		}
+
+		webhooks.Dispatch(event)
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				deliveries, err := webhooks.Deliveries(ids[tt.name])
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, len(deliveries) == 1)
+			})
+		}
+	})
+
+	t.Run("dispatching doesn't wait for the receiver", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		_, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			webhooks.Dispatch(event)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("dispatching blocked on the receiver")
+		}
+	})
+
+	t.Run("pending deliveries are given up on when closing", func(t *testing.T) {
+		_, server := newReceiver(t, http.StatusServiceUnavailable)
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook(), service.WithRetries(5, time.Hour))
+
+		webhook, err := webhooks.Create(domain.Webhook{URL: server.URL, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		webhooks.Dispatch(event)
+		require.Eventually(t, func() bool {
+			deliveries, _ := webhooks.Deliveries(webhook.ID)
+			return len(deliveries) == 1 && deliveries[0].Attempts == 1
+		}, time.Second, time.Millisecond)
+
+		webhooks.Close()
+
+		deliveries, err := webhooks.Deliveries(webhook.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeliveryFailed, deliveries[0].Status)
+	})
+
+	t.Run("update keeps the creation time", func(t *testing.T) {
+		webhooks := service.NewWebhooks(repository.NewInMemoryWebhook())
+		defer webhooks.Close()
+
+		created, err := webhooks.Create(domain.Webhook{URL: "http://example.com", Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		updated, err := webhooks.Update(domain.Webhook{ID: created.ID, URL: "http://example.org", Secret: "s3cr3t"})
+		require.NoError(t, err)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+		assert.Equal(t, "http://example.org", updated.URL)
+
+		_, err = webhooks.Update(domain.Webhook{ID: "nope"})
+		assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+	})
+}